@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/kdf"
+	"crypto-lab/internal/modes"
+)
+
+type environment struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (env *environment) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+func (env *environment) parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usagef("%v", err)
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected arguments: %v", fs.Args())
+	}
+	return nil
+}
+
+func addKeyFlags(fs *flag.FlagSet, ks *keySource) {
+	fs.StringVar(&ks.hexKey, "key", "", "key in hex")
+	fs.StringVar(&ks.keyFile, "key-file", "", "file with the key (hex as written by keygen, or raw bytes)")
+	fs.StringVar(&ks.passphrase, "passphrase", "", "derive the key from a passphrase (PBKDF2-SHA256, salt stored in the header)")
+}
+
+// "-" или пусто - stdin/stdout
+func (env *environment) openInput(path string) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return io.NopCloser(env.stdin), nil
+	}
+	return os.Open(path)
+}
+
+// выход команды: Commit - успех, Close без Commit отменяет запись
+type output interface {
+	io.Writer
+	Commit() error
+	Close() error
+}
+
+type stdoutOutput struct{ io.Writer }
+
+func (stdoutOutput) Commit() error { return nil }
+func (stdoutOutput) Close() error  { return nil }
+
+// файл пишется во временный рядом и переименовывается в Commit: неверный ключ
+// или битый вход не портят существующий файл и не оставляют частичный текст
+type fileOutput struct {
+	*os.File
+	path      string
+	committed bool
+}
+
+func (f *fileOutput) Commit() error {
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		return err
+	}
+	f.committed = true
+	return nil
+}
+
+func (f *fileOutput) Close() error {
+	if f.committed {
+		return nil
+	}
+	f.File.Close()
+	return os.Remove(f.Name())
+}
+
+func (env *environment) createOutput(path string) (output, error) {
+	if path == "" || path == "-" {
+		return stdoutOutput{env.stdout}, nil
+	}
+	// CreateTemp создает файл с правами 0600
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, err
+	}
+	return &fileOutput{File: f, path: path}, nil
+}
+
+func (env *environment) setVerbose(verbose bool) {
+	if verbose {
+		modes.DebugOutput = env.stderr
+	} else {
+		modes.DebugOutput = nil
+	}
+}
+
+func (env *environment) encrypt(args []string) error {
+	fs := env.newFlagSet("encrypt")
+	cipherName := fs.String("cipher", "DEAL-128", "cipher: "+cipherNames())
+	modeName := fs.String("mode", "CBC", "cipher mode")
+	paddingName := fs.String("padding", "PKCS7", "padding mode")
+	ivHex := fs.String("iv", "", "IV in hex (random if omitted)")
+	nonceHex := fs.String("nonce", "", "nonce in hex for CTR/RandomDelta/GCM (random if omitted)")
+	seed := fs.Int64("seed", 0, "RandomDelta counter seed")
+	inPath := fs.String("in", "", "input file (default stdin)")
+	outPath := fs.String("out", "", "output file (default stdout)")
+	verbose := fs.Bool("v", false, "print padding diagnostics to stderr")
+	var ks keySource
+	addKeyFlags(fs, &ks)
+
+	if err := env.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := ks.validate(); err != nil {
+		return err
+	}
+	env.setVerbose(*verbose)
+
+	name, spec, err := lookupCipher(*cipherName)
+	if err != nil {
+		return err
+	}
+	mode, err := parseMode(*modeName)
+	if err != nil {
+		return err
+	}
+	padding, err := parsePadding(*paddingName)
+	if err != nil {
+		return err
+	}
+	iv, err := parseHex("iv", *ivHex)
+	if err != nil {
+		return err
+	}
+	nonce, err := parseHex("nonce", *nonceHex)
+	if err != nil {
+		return err
+	}
+
+	var salt []byte
+	var headerOpts []modes.HeaderOption
+	if ks.passphrase != "" {
+		salt, err = modes.GenerateRandomBytes(passphraseSaltSize)
+		if err != nil {
+			return err
+		}
+		headerOpts = append(headerOpts, modes.WithKDFSalt(salt))
+	}
+
+	key, err := ks.key(spec.keySize, salt)
+	if err != nil {
+		return err
+	}
+	cipher, err := ciphers.Lookup(name, key)
+	if err != nil {
+		return err
+	}
+
+	if iv != nil && len(iv) != cipher.GetBlockSize() {
+		return usagef("-iv must be %d bytes for %s, got %d", cipher.GetBlockSize(), name, len(iv))
+	}
+
+	// nonce фиксируется заранее, т.к. seed передается вторым параметром;
+	// для GCM - стандартные 12 байт
+	if nonce == nil {
+		size := cipher.GetBlockSize() / 2
+		if mode == ciphers.GCM {
+			size = 12
+		}
+		nonce, err = modes.GenerateRandomBytes(size)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, err := modes.NewSymmetricContext(cipher, mode, padding, iv, nonce, *seed)
+	if err != nil {
+		return err
+	}
+
+	in, err := env.openInput(*inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := env.createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := ctx.EncryptStream(out, in, headerOpts...); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
+func (env *environment) decrypt(args []string) error {
+	fs := env.newFlagSet("decrypt")
+	inPath := fs.String("in", "", "input file (default stdin)")
+	outPath := fs.String("out", "", "output file (default stdout)")
+	verbose := fs.Bool("v", false, "print padding diagnostics to stderr")
+	var ks keySource
+	addKeyFlags(fs, &ks)
+
+	if err := env.parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := ks.validate(); err != nil {
+		return err
+	}
+	env.setVerbose(*verbose)
+
+	in, err := env.openInput(*inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	br := bufio.NewReader(in)
+	h, err := modes.ReadFileHeader(br)
+	if err != nil {
+		return err
+	}
+
+	name, spec, err := cipherForHeader(h)
+	if err != nil {
+		return err
+	}
+	key, err := ks.key(spec.keySize, h.Salt)
+	if err != nil {
+		return err
+	}
+	cipher, err := ciphers.Lookup(name, key)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := modes.NewSymmetricContextFromHeader(cipher, h)
+	if err != nil {
+		return err
+	}
+	reader, err := ctx.NewDecryptReader(br)
+	if err != nil {
+		return err
+	}
+
+	out, err := env.createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, reader); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
+func (env *environment) keygen(args []string) error {
+	fs := env.newFlagSet("keygen")
+	cipherName := fs.String("cipher", "DEAL-128", "cipher: "+cipherNames())
+	outPath := fs.String("out", "", "output file (default stdout)")
+
+	if err := env.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	_, spec, err := lookupCipher(*cipherName)
+	if err != nil {
+		return err
+	}
+	key, err := modes.GenerateRandomBytes(spec.keySize)
+	if err != nil {
+		return err
+	}
+	if spec.alg.ID == ciphers.CipherDES || spec.alg.ID == ciphers.CipherTripleDES {
+		kdf.SetDESParity(key)
+	}
+
+	out, err := env.createOutput(*outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := fmt.Fprintln(out, hex.EncodeToString(key)); err != nil {
+		return err
+	}
+	return out.Commit()
+}
+
+func (env *environment) info(args []string) error {
+	fs := env.newFlagSet("info")
+	inPath := fs.String("in", "", "input file (default stdin)")
+
+	if err := env.parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	in, err := env.openInput(*inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	h, err := modes.ReadFileHeader(in)
+	if err != nil {
+		return err
+	}
+
+	cipherName := h.CipherID.String()
+	if name, _, err := cipherForHeader(h); err == nil {
+		cipherName = name
+	}
+
+	w := env.stdout
+	fmt.Fprintf(w, "format version: %d\n", h.Version)
+	fmt.Fprintf(w, "cipher:         %s\n", cipherName)
+	fmt.Fprintf(w, "key size:       %d bytes\n", h.KeySize)
+	fmt.Fprintf(w, "block size:     %d bytes\n", h.BlockSize)
+	fmt.Fprintf(w, "mode:           %v\n", h.Mode)
+	fmt.Fprintf(w, "padding:        %v\n", h.Padding)
+	printHexField(w, "iv:", h.IV)
+	printHexField(w, "nonce:", h.Nonce)
+	if h.HasSeed {
+		fmt.Fprintf(w, "seed:           %d\n", h.Seed)
+	}
+	if h.GCMTagSize != 0 {
+		fmt.Fprintf(w, "gcm tag size:   %d bytes\n", h.GCMTagSize)
+	}
+	printHexField(w, "kdf salt:", h.Salt)
+	printHexField(w, "mac tag:", h.MACTag)
+	return nil
+}
+
+func printHexField(w io.Writer, label string, value []byte) {
+	if len(value) > 0 {
+		fmt.Fprintf(w, "%-15s %s\n", label, hex.EncodeToString(value))
+	}
+}
@@ -0,0 +1,80 @@
+// cryptolab - шифрование и расшифровка файлов шифрами и режимами crypto-lab.
+//
+//	cryptolab encrypt -cipher DEAL-256 -mode CBC -padding PKCS7 -passphrase secret -in a.txt -out a.enc
+//	cryptolab decrypt -passphrase secret -in a.enc -out a.txt
+//	cryptolab keygen  -cipher DES
+//	cryptolab info    -in a.enc
+//
+// Без -in/-out данные читаются из stdin и пишутся в stdout.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	env := &environment{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	var err error
+	switch args[0] {
+	case "encrypt":
+		err = env.encrypt(args[1:])
+	case "decrypt":
+		err = env.decrypt(args[1:])
+	case "keygen":
+		err = env.keygen(args[1:])
+	case "info":
+		err = env.info(args[1:])
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "cryptolab: unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "cryptolab %s: %s\n", args[0], describeError(err))
+		if isUsageError(err) {
+			return exitUsage
+		}
+		return exitError
+	}
+	return exitOK
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, `usage: cryptolab <command> [flags]
+
+commands:
+  encrypt   encrypt data into a self-describing container
+  decrypt   decrypt a container produced by encrypt
+  keygen    generate a random key (hex)
+  info      print the container header
+
+ciphers:  %s
+modes:    %s
+          (GCM needs a 16-byte block: DEAL, AES; GCM and CBC-CS ignore -padding)
+paddings: Zeros, ANSIX923, PKCS7, ISO10126
+
+run "cryptolab <command> -h" for command flags
+`, cipherNames(), fileModeNames())
+}
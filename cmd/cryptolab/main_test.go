@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCLI(t *testing.T, stdin []byte, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestEncryptDecryptStdio(t *testing.T) {
+	message := []byte("cryptolab round-trip through stdin/stdout")
+
+	for _, mode := range []string{"ECB", "CBC", "PCBC", "CFB", "OFB", "CTR", "RandomDelta", "GCM", "CBC-CS1", "CBC-CS2", "CBC-CS3"} {
+		t.Run(mode, func(t *testing.T) {
+			key := "0123456789abcdeffedcba9876543210"
+
+			code, encrypted, stderr := runCLI(t, message, "encrypt", "-cipher", "DEAL-128", "-mode", mode, "-key", key, "-seed", "7")
+			if code != exitOK {
+				t.Fatalf("encrypt exit %d: %s", code, stderr)
+			}
+
+			code, decrypted, stderr := runCLI(t, []byte(encrypted), "decrypt", "-key", key)
+			if code != exitOK {
+				t.Fatalf("decrypt exit %d: %s", code, stderr)
+			}
+			if decrypted != string(message) {
+				t.Errorf("expected %q, got %q", message, decrypted)
+			}
+		})
+	}
+}
+
+func TestEncryptDecryptFilesWithPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain.txt")
+	encPath := filepath.Join(dir, "plain.enc")
+	decPath := filepath.Join(dir, "plain.dec")
+
+	message := bytes.Repeat([]byte("passphrase protected "), 20)
+	os.WriteFile(plainPath, message, 0644)
+
+	code, _, stderr := runCLI(t, nil, "encrypt", "-cipher", "des", "-mode", "cfb", "-padding", "ansi x.923",
+		"-passphrase", "correct horse", "-in", plainPath, "-out", encPath)
+	if code != exitOK {
+		t.Fatalf("encrypt exit %d: %s", code, stderr)
+	}
+
+	code, info, stderr := runCLI(t, nil, "info", "-in", encPath)
+	if code != exitOK {
+		t.Fatalf("info exit %d: %s", code, stderr)
+	}
+	for _, want := range []string{"cipher:         DES", "mode:           CFB", "padding:        ANSI X.923", "kdf salt:"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("info output missing %q:\n%s", want, info)
+		}
+	}
+
+	code, _, stderr = runCLI(t, nil, "decrypt", "-passphrase", "correct horse", "-in", encPath, "-out", decPath)
+	if code != exitOK {
+		t.Fatalf("decrypt exit %d: %s", code, stderr)
+	}
+	decrypted, _ := os.ReadFile(decPath)
+	if !bytes.Equal(decrypted, message) {
+		t.Errorf("file round-trip failed")
+	}
+
+	// неверный пароль: соль случайная, и в CFB + ANSI X.923 примерно в 1/256 запусков
+	// паддинг выглядит верным, поэтому проверяется только, что открытый текст не получен
+	// (ошибка паддинга детерминированно - в TestDecryptKeepsOutputOnError)
+	wrongPath := filepath.Join(dir, "wrong.dec")
+	code, _, stderr = runCLI(t, nil, "decrypt", "-passphrase", "wrong horse", "-in", encPath, "-out", wrongPath)
+	if got, _ := os.ReadFile(wrongPath); bytes.Equal(got, message) {
+		t.Errorf("wrong passphrase recovered the plaintext (exit %d: %s)", code, stderr)
+	}
+}
+
+// неверный ключ: существующий -out не тронут, временных файлов не остается.
+// ECB без случайных полей - паддинг гарантированно не сходится
+func TestDecryptKeepsOutputOnError(t *testing.T) {
+	dir := t.TempDir()
+	encPath := filepath.Join(dir, "data.enc")
+	outPath := filepath.Join(dir, "data.txt")
+
+	code, _, stderr := runCLI(t, []byte("secret data"), "encrypt", "-cipher", "DES", "-mode", "ECB",
+		"-key", "133457799bbcdff1", "-out", encPath)
+	if code != exitOK {
+		t.Fatalf("encrypt exit %d: %s", code, stderr)
+	}
+	os.WriteFile(outPath, []byte("existing"), 0644)
+
+	code, _, stderr = runCLI(t, nil, "decrypt", "-key", "0e329232ea6d0d73", "-in", encPath, "-out", outPath)
+	if code != exitError || !strings.Contains(stderr, "decryption failed") {
+		t.Errorf("wrong key: expected exit %d with decryption error, got %d: %s", exitError, code, stderr)
+	}
+	if got, _ := os.ReadFile(outPath); string(got) != "existing" {
+		t.Errorf("output overwritten: %q", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected 2 files in dir, got %d", len(entries))
+	}
+
+	code, _, stderr = runCLI(t, nil, "decrypt", "-key", "133457799bbcdff1", "-in", encPath, "-out", outPath)
+	if got, _ := os.ReadFile(outPath); code != exitOK || string(got) != "secret data" {
+		t.Errorf("decrypt exit %d, output %q: %s", code, got, stderr)
+	}
+}
+
+func TestKeygenKeyFile(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "deal.key")
+
+	code, _, stderr := runCLI(t, nil, "keygen", "-cipher", "DEAL-256", "-out", keyPath)
+	if code != exitOK {
+		t.Fatalf("keygen exit %d: %s", code, stderr)
+	}
+	keyHex, _ := os.ReadFile(keyPath)
+	if len(strings.TrimSpace(string(keyHex))) != 64 {
+		t.Errorf("expected 64 hex chars, got %q", keyHex)
+	}
+
+	code, encrypted, stderr := runCLI(t, []byte("data"), "encrypt", "-cipher", "DEAL-256", "-key-file", keyPath)
+	if code != exitOK {
+		t.Fatalf("encrypt exit %d: %s", code, stderr)
+	}
+	code, decrypted, stderr := runCLI(t, []byte(encrypted), "decrypt", "-key-file", keyPath)
+	if code != exitOK || decrypted != "data" {
+		t.Errorf("decrypt exit %d, output %q: %s", code, decrypted, stderr)
+	}
+}
+
+// имена из реестра ciphers: info восстанавливает 3DES-128 по ID и размеру ключа
+func TestRegistryCipherNames(t *testing.T) {
+	key := strings.Repeat("0123456789abcdef", 2)
+	code, encrypted, stderr := runCLI(t, []byte("data"), "encrypt", "-cipher", "3des-128", "-key", key)
+	if code != exitOK {
+		t.Fatalf("encrypt exit %d: %s", code, stderr)
+	}
+	code, info, stderr := runCLI(t, []byte(encrypted), "info")
+	if code != exitOK || !strings.Contains(info, "3DES-128") {
+		t.Errorf("info exit %d, expected 3DES-128:\n%s%s", code, info, stderr)
+	}
+	code, decrypted, stderr := runCLI(t, []byte(encrypted), "decrypt", "-key", key)
+	if code != exitOK || decrypted != "data" {
+		t.Errorf("decrypt exit %d, output %q: %s", code, decrypted, stderr)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		args     []string
+		wantCode int
+		wantMsg  string
+	}{
+		{"no command", "", nil, exitUsage, "usage"},
+		{"unknown command", "", []string{"frobnicate"}, exitUsage, "unknown command"},
+		{"unknown cipher", "", []string{"encrypt", "-cipher", "RC4", "-key", "00"}, exitUsage, "unknown cipher"},
+		{"unknown mode", "", []string{"encrypt", "-mode", "XYZ", "-key", "00"}, exitUsage, "unknown cipher mode"},
+		{"XTS", "", []string{"encrypt", "-mode", "XTS", "-key", "00"}, exitUsage, "not supported for files"},
+		{"no key", "", []string{"encrypt"}, exitUsage, "exactly one of"},
+		{"key size", "x", []string{"encrypt", "-cipher", "DES", "-key", "0011"}, exitError, "invalid key size for DES"},
+		{"not a container", "plain text", []string{"decrypt", "-key", "00"}, exitError, "not a cryptolab container"},
+		{"truncated", "CL", []string{"info"}, exitError, "truncated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, []byte(tt.stdin), tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("expected exit %d, got %d: %s", tt.wantCode, code, stderr)
+			}
+			if !strings.Contains(stderr, tt.wantMsg) {
+				t.Errorf("expected %q in stderr, got %q", tt.wantMsg, stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"crypto-lab/internal/ciphers"
+	_ "crypto-lab/internal/ciphers/deal" // регистрация в реестре ciphers
+	_ "crypto-lab/internal/ciphers/des"
+	_ "crypto-lab/internal/ciphers/rijndael"
+	"crypto-lab/internal/hash"
+	"crypto-lab/internal/kdf"
+	"crypto-lab/internal/modes"
+)
+
+// шифр из реестра ciphers с выбранным размером ключа
+type cipherSpec struct {
+	alg     ciphers.Algorithm
+	keySize int
+}
+
+// имя как в реестре: DEAL-128, 3DES-192; DES без суффикса
+func (s cipherSpec) name() string {
+	return s.alg.Names()[slices.Index(s.alg.KeySizes, s.keySize)]
+}
+
+// параметры PBKDF2 для -passphrase; соль хранится в заголовке
+const (
+	passphraseIterations = 100000
+	passphraseSaltSize   = 16
+)
+
+// ошибка в аргументах командной строки: код выхода 2
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func isUsageError(err error) bool {
+	var ue *usageError
+	return errors.As(err, &ue)
+}
+
+// понятные сообщения для типичных ошибок
+func describeError(err error) string {
+	switch {
+	case errors.Is(err, modes.ErrInvalidPadding), errors.Is(err, modes.ErrAuthenticationFailed):
+		return fmt.Sprintf("decryption failed: %v (wrong key or corrupted input?)", err)
+	case errors.Is(err, modes.ErrBadMagic):
+		return "input is not a cryptolab container (bad magic)"
+	case errors.Is(err, modes.ErrTruncatedHeader):
+		return "input is truncated: incomplete container header"
+	}
+	return err.Error()
+}
+
+func cipherNames() string {
+	var names []string
+	for _, a := range ciphers.Algorithms() {
+		names = append(names, a.Names()...)
+	}
+	return strings.Join(names, ", ")
+}
+
+// имя без суффикса - размер ключа по умолчанию: DEAL = DEAL-128, 3DES = 3DES-192
+func lookupCipher(name string) (string, cipherSpec, error) {
+	a, keySize, err := ciphers.LookupAlgorithm(name)
+	if err != nil {
+		return "", cipherSpec{}, usagef("unknown cipher %q (supported: %s)", name, cipherNames())
+	}
+	spec := cipherSpec{alg: a, keySize: keySize}
+	return spec.name(), spec, nil
+}
+
+// шифр по полям заголовка: ID и размер ключа (для 3DES он различает EDE2 и EDE3)
+func cipherForHeader(h *modes.FileHeader) (string, cipherSpec, error) {
+	for _, a := range ciphers.Algorithms() {
+		if a.ID == h.CipherID && slices.Contains(a.KeySizes, h.KeySize) {
+			spec := cipherSpec{alg: a, keySize: h.KeySize}
+			return spec.name(), spec, nil
+		}
+	}
+	return "", cipherSpec{}, fmt.Errorf("unsupported cipher in header: %v with %d-byte key", h.CipherID, h.KeySize)
+}
+
+// XTS шифрует отдельные секторы и в контейнер не пишется (modes.ErrXTSNotStreamable)
+func parseMode(name string) (ciphers.CipherMode, error) {
+	mode, err := ciphers.ParseCipherMode(name)
+	if err != nil {
+		return 0, usagef("%v", err)
+	}
+	if mode == ciphers.XTS {
+		return 0, usagef("mode XTS encrypts disk sectors and is not supported for files (supported: %s)", fileModeNames())
+	}
+	return mode, nil
+}
+
+func fileModeNames() string {
+	var names []string
+	for m := ciphers.ECB; m.String() != "Unknown"; m++ {
+		if m != ciphers.XTS {
+			names = append(names, m.String())
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func parsePadding(name string) (ciphers.PaddingMode, error) {
+	padding, err := ciphers.ParsePaddingMode(name)
+	if err != nil {
+		return 0, usagef("%v", err)
+	}
+	return padding, nil
+}
+
+func parseHex(flagName, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, usagef("-%s: invalid hex: %v", flagName, err)
+	}
+	return b, nil
+}
+
+// источники ключа: ровно один из -key, -key-file, -passphrase
+type keySource struct {
+	hexKey     string
+	keyFile    string
+	passphrase string
+}
+
+func (k *keySource) validate() error {
+	count := 0
+	for _, v := range []string{k.hexKey, k.keyFile, k.passphrase} {
+		if v != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return usagef("exactly one of -key, -key-file or -passphrase is required")
+	}
+	return nil
+}
+
+// salt используется только для -passphrase
+func (k *keySource) key(keySize int, salt []byte) ([]byte, error) {
+	switch {
+	case k.hexKey != "":
+		return parseHex("key", k.hexKey)
+	case k.keyFile != "":
+		data, err := os.ReadFile(k.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		// файл от keygen (hex) или сырые байты ключа
+		if decoded, err := hex.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+			return decoded, nil
+		}
+		return data, nil
+	default:
+		if len(salt) == 0 {
+			return nil, errors.New("container has no KDF salt: it was not encrypted with -passphrase")
+		}
+		return kdf.PBKDF2(hash.NewSHA256, []byte(k.passphrase), salt, passphraseIterations, keySize)
+	}
+}
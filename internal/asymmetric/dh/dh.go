@@ -0,0 +1,64 @@
+package dh
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidPublicKey = errors.New("dh: invalid public key")
+
+type PrivateKey struct {
+	Group *Group
+	X     *big.Int // секретный показатель
+	Y     *big.Int // открытый ключ G^X mod P
+}
+
+// GenerateKey выбирает X из [2, Q-1]
+func GenerateKey(g *Group) (*PrivateKey, error) {
+	limit := new(big.Int).Sub(g.Q, bigTwo) // X = 2 + rand[0, Q-2)
+	x, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return nil, err
+	}
+	x.Add(x, bigTwo)
+	return &PrivateKey{Group: g, X: x, Y: new(big.Int).Exp(g.G, x, g.P)}, nil
+}
+
+// ValidatePublicKey (NIST SP 800-56A, 5.6.2.3.1): 2 <= y <= P-2 и y^Q = 1 mod P,
+// т.е. y лежит в подгруппе порядка Q; иначе возможна атака с малой подгруппой
+func (g *Group) ValidatePublicKey(y *big.Int) error {
+	if y == nil || y.Cmp(bigTwo) < 0 || y.Cmp(new(big.Int).Sub(g.P, bigTwo)) > 0 {
+		return ErrInvalidPublicKey
+	}
+	if new(big.Int).Exp(y, g.Q, g.P).Cmp(bigOne) != 0 {
+		return ErrInvalidPublicKey
+	}
+	return nil
+}
+
+// SharedSecret = peer^X mod P фиксированной длины g.Size() байт
+func (priv *PrivateKey) SharedSecret(peer *big.Int) ([]byte, error) {
+	if err := priv.Group.ValidatePublicKey(peer); err != nil {
+		return nil, err
+	}
+	z := new(big.Int).Exp(peer, priv.X, priv.Group.P)
+	return z.FillBytes(make([]byte, priv.Group.Size())), nil
+}
+
+// PublicKeyBytes - открытый ключ big-endian фиксированной длины
+func (priv *PrivateKey) PublicKeyBytes() []byte {
+	return priv.Y.FillBytes(make([]byte, priv.Group.Size()))
+}
+
+// ParsePublicKey разбирает и проверяет открытый ключ собеседника
+func (g *Group) ParsePublicKey(data []byte) (*big.Int, error) {
+	if len(data) != g.Size() {
+		return nil, ErrInvalidPublicKey
+	}
+	y := new(big.Int).SetBytes(data)
+	if err := g.ValidatePublicKey(y); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
@@ -0,0 +1,200 @@
+package dh
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/kdf"
+)
+
+// все группы RFC 3526 начинаются с pi-константы и заканчиваются 64 единицами
+func TestMODPGroupsMatchRFC3526(t *testing.T) {
+	const prefix = "ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd1"
+	suffixes := map[int]string{
+		1536: "ca237327ffffffffffffffff",
+		2048: "8aacaa68ffffffffffffffff",
+		3072: "a93ad2caffffffffffffffff",
+		4096: "34063199ffffffffffffffff",
+	}
+
+	for bits, suffix := range suffixes {
+		g, err := MODPGroup(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := hex.EncodeToString(g.P.Bytes())
+		if g.P.BitLen() != bits || !strings.HasPrefix(h, prefix) || !strings.HasSuffix(h, suffix) {
+			t.Errorf("%d: unexpected prime %s...%s", bits, h[:48], h[len(h)-24:])
+		}
+	}
+
+	// смещение k в формуле верно, только если P и Q простые
+	for _, bits := range []int{1536, 2048} {
+		g, _ := MODPGroup(bits)
+		if !g.P.ProbablyPrime(4) || !g.Q.ProbablyPrime(4) {
+			t.Errorf("%d: P is not a safe prime", bits)
+		}
+	}
+
+	if _, err := MODPGroup(1024); err == nil {
+		t.Errorf("expected error for unknown group size")
+	}
+}
+
+func TestKeyAgreement(t *testing.T) {
+	g, _ := MODPGroup(2048)
+	alice, err := GenerateKey(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := GenerateKey(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bobPub, err := g.ParsePublicKey(bob.PublicKeyBytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, err := alice.SharedSecret(bobPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := bob.SharedSecret(alice.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s1, s2) || len(s1) != 256 {
+		t.Errorf("shared secrets differ or have wrong length")
+	}
+}
+
+func TestPublicKeyValidation(t *testing.T) {
+	g, _ := MODPGroup(1536)
+	priv, _ := GenerateKey(g)
+
+	pMinus1 := new(big.Int).Sub(g.P, bigOne)
+	invalid := map[string]*big.Int{
+		"zero":  big.NewInt(0),
+		"one":   big.NewInt(1),
+		"P-1":   pMinus1, // порядок 2
+		"P":     g.P,
+		"P+5":   new(big.Int).Add(g.P, big.NewInt(5)),
+		"nonQR": new(big.Int).Sub(g.P, bigTwo), // -2: при P = 7 mod 8 не вычет
+	}
+	for name, y := range invalid {
+		if _, err := priv.SharedSecret(y); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("%s: expected ErrInvalidPublicKey, got %v", name, err)
+		}
+	}
+	if _, err := g.ParsePublicKey([]byte{1, 2, 3}); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("expected ErrInvalidPublicKey for short encoding, got %v", err)
+	}
+}
+
+func TestGenerateGroup(t *testing.T) {
+	g, err := GenerateGroup(128, nil, 0.999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.P.BitLen() != 128 || !g.P.ProbablyPrime(20) || !g.Q.ProbablyPrime(20) {
+		t.Errorf("not a 128-bit safe prime: %v", g.P)
+	}
+	if new(big.Int).Exp(g.G, g.Q, g.P).Cmp(bigOne) != 0 {
+		t.Errorf("generator is not in the subgroup of order Q")
+	}
+
+	a, _ := GenerateKey(g)
+	b, _ := GenerateKey(g)
+	s1, err1 := a.SharedSecret(b.Y)
+	s2, err2 := b.SharedSecret(a.Y)
+	if err1 != nil || err2 != nil || !bytes.Equal(s1, s2) {
+		t.Errorf("key agreement in custom group failed: %v %v", err1, err2)
+	}
+}
+
+// send одной стороны расшифровывает receive другой, в обе стороны; направления
+// не делят гамму
+func TestNewSessionContexts(t *testing.T) {
+	g, _ := MODPGroup(1536)
+	alice, _ := GenerateKey(g)
+	bob, _ := GenerateKey(g)
+	s1, _ := alice.SharedSecret(bob.Y)
+	s2, _ := bob.SharedSecret(alice.Y)
+	salt := []byte("session-1")
+
+	newDEAL := func() ciphers.SymmetricCipher { c, _ := deal.NewDEALCipher(32); return c }
+	newDES := func() ciphers.SymmetricCipher { return des.NewDES() }
+	scrypt := kdf.NewScryptDeriver(16, 1, 1)
+
+	tests := []struct {
+		name      string
+		newCipher func() ciphers.SymmetricCipher
+		mode      ciphers.CipherMode
+		deriver   kdf.KeyDeriver
+	}{
+		{"DEAL-256 CBC", newDEAL, ciphers.CBC, nil},
+		{"DEAL-256 CTR", newDEAL, ciphers.CTR, nil},
+		{"DES CBC", newDES, ciphers.CBC, nil},
+		{"DES OFB scrypt", newDES, ciphers.OFB, scrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aliceSend, aliceRecv, err := NewSessionContexts(s1, salt, Initiator, tt.newCipher, tt.mode, ciphers.PKCS7, tt.deriver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bobSend, bobRecv, err := NewSessionContexts(s2, salt, Responder, tt.newCipher, tt.mode, ciphers.PKCS7, tt.deriver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg := []byte("key agreed over Diffie-Hellman")
+			ct, err := aliceSend.Encrypt(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pt, err := bobRecv.Decrypt(ct)
+			if err != nil || !bytes.Equal(pt, msg) {
+				t.Errorf("alice->bob: expected %q, got %q (%v)", msg, pt, err)
+			}
+
+			reply, err := bobSend.Encrypt(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pt, err = aliceRecv.Decrypt(reply)
+			if err != nil || !bytes.Equal(pt, msg) {
+				t.Errorf("bob->alice: expected %q, got %q (%v)", msg, pt, err)
+			}
+
+			// одинаковое сообщение в разных направлениях - разный шифртекст
+			if bytes.Equal(ct, reply) {
+				t.Errorf("both directions produced the same ciphertext")
+			}
+		})
+	}
+
+	send, _, _ := NewSessionContexts(s1, []byte("session-2"), Initiator, newDES, ciphers.CBC, ciphers.PKCS7, nil)
+	other, _, _ := NewSessionContexts(s1, salt, Initiator, newDES, ciphers.CBC, ciphers.PKCS7, nil)
+	a, _ := send.Encrypt([]byte("12345678"))
+	b, _ := other.Encrypt([]byte("12345678"))
+	if bytes.Equal(a, b) {
+		t.Errorf("different salts must give different session keys")
+	}
+
+	if _, _, err := NewSessionContexts(nil, salt, Initiator, newDES, ciphers.CBC, ciphers.PKCS7, nil); err == nil {
+		t.Errorf("expected error for empty secret")
+	}
+	if _, _, err := NewSessionContexts(s1, salt, Role(7), newDES, ciphers.CBC, ciphers.PKCS7, nil); err == nil {
+		t.Errorf("expected error for unknown role")
+	}
+}
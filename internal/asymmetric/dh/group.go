@@ -0,0 +1,152 @@
+package dh
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"crypto-lab/internal/math/primality"
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTwo = big.NewInt(2)
+)
+
+// Group - группа по модулю безопасного простого P = 2Q + 1;
+// G порождает подгруппу квадратичных вычетов простого порядка Q
+type Group struct {
+	P *big.Int
+	G *big.Int
+	Q *big.Int
+}
+
+// Size - длина модуля в байтах (длина открытого ключа и общего секрета)
+func (g *Group) Size() int {
+	return (g.P.BitLen() + 7) / 8
+}
+
+// RFC 3526: P = 2^n - 2^(n-64) - 1 + 2^64 * (floor(2^(n-130) * pi) + k), G = 2.
+// Простые не хранятся константами, а вычисляются по этой формуле при первом обращении.
+var modpOffsets = map[int]int64{
+	1536: 741804,  // группа 5
+	2048: 124476,  // группа 14
+	3072: 1690314, // группа 15
+	4096: 240904,  // группа 16
+	6144: 929484,  // группа 17
+	8192: 4743158, // группа 18
+}
+
+var (
+	modpMu     sync.Mutex
+	modpGroups = map[int]*Group{}
+)
+
+// MODPGroup возвращает группу RFC 3526 с модулем bits бит
+func MODPGroup(bits int) (*Group, error) {
+	k, ok := modpOffsets[bits]
+	if !ok {
+		return nil, fmt.Errorf("dh: no RFC 3526 MODP group of %d bits (supported: 1536, 2048, 3072, 4096, 6144, 8192)", bits)
+	}
+
+	modpMu.Lock()
+	defer modpMu.Unlock()
+	if g, ok := modpGroups[bits]; ok {
+		return g, nil
+	}
+
+	n := uint(bits)
+	p := new(big.Int).Lsh(bigOne, n)
+	p.Sub(p, new(big.Int).Lsh(bigOne, n-64))
+	p.Sub(p, bigOne)
+	t := piFixed(n - 130)
+	t.Add(t, big.NewInt(k))
+	p.Add(p, t.Lsh(t, 64))
+
+	g := &Group{P: p, G: big.NewInt(2), Q: new(big.Int).Rsh(p, 1)}
+	modpGroups[bits] = g
+	return g, nil
+}
+
+// piFixed = floor(pi * 2^bits) по формуле Мэчина: pi = 16 arctg(1/5) - 4 arctg(1/239)
+func piFixed(bits uint) *big.Int {
+	const guard = 64
+	prec := bits + guard
+	a := arctanInv(5, prec)
+	a.Mul(a, big.NewInt(16))
+	b := arctanInv(239, prec)
+	b.Mul(b, big.NewInt(4))
+	return a.Sub(a, b).Rsh(a, guard)
+}
+
+// arctg(1/x) * 2^prec рядом Тейлора: sum (-1)^k / ((2k+1) x^(2k+1))
+func arctanInv(x int64, prec uint) *big.Int {
+	bx := big.NewInt(x)
+	xx := big.NewInt(x * x)
+	power := new(big.Int).Lsh(bigOne, prec)
+	power.Quo(power, bx) // 2^prec / x^(2k+1)
+	sum := new(big.Int).Set(power)
+	term := new(big.Int)
+
+	for k := int64(1); power.Sign() != 0; k++ {
+		power.Quo(power, xx)
+		term.Quo(power, big.NewInt(2*k+1))
+		if k%2 == 1 {
+			sum.Sub(sum, term)
+		} else {
+			sum.Add(sum, term)
+		}
+	}
+	return sum
+}
+
+const MinGroupBits = 64
+
+// нечетные простые для отсева кандидатов q и 2q + 1
+var smallPrimes = []int64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71, 73, 79, 83, 89, 97}
+
+// GenerateGroup ищет безопасное простое P = 2Q + 1 длиной bits бит;
+// test == nil - Миллер–Рабин
+func GenerateGroup(bits int, test primality.PrimalityTest, minProbability float64) (*Group, error) {
+	if bits < MinGroupBits {
+		return nil, fmt.Errorf("dh: group size must be >= %d bits, got %d", MinGroupBits, bits)
+	}
+	if test == nil {
+		test = primality.NewMillerRabinTest()
+	}
+
+	mod := new(big.Int)
+	for {
+		q, err := rand.Int(rand.Reader, new(big.Int).Lsh(bigOne, uint(bits-1)))
+		if err != nil {
+			return nil, err
+		}
+		q.SetBit(q, bits-2, 1) // P ровно bits бит
+		q.SetBit(q, 0, 1)
+		p := new(big.Int).Lsh(q, 1)
+		p.Add(p, bigOne)
+
+		composite := false
+		for _, sp := range smallPrimes {
+			b := big.NewInt(sp)
+			if mod.Mod(q, b).Sign() == 0 || mod.Mod(p, b).Sign() == 0 {
+				composite = true
+				break
+			}
+		}
+		if composite {
+			continue
+		}
+		if !test.IsProbablyPrime(q, minProbability) || !test.IsProbablyPrime(p, minProbability) {
+			continue
+		}
+
+		// 2 - квадратичный вычет при P = +-1 mod 8, иначе берем 4 = 2^2
+		g := big.NewInt(2)
+		if r := p.Bits()[0] & 7; r != 1 && r != 7 {
+			g.SetInt64(4)
+		}
+		return &Group{P: p, G: g, Q: q}, nil
+	}
+}
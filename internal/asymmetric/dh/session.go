@@ -0,0 +1,110 @@
+package dh
+
+import (
+	"errors"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/hash"
+	"crypto-lab/internal/kdf"
+	"crypto-lab/internal/modes"
+)
+
+// секрет DH высокоэнтропийный, растягивать его итерациями незачем
+const sessionKDFIterations = 1
+
+// Role - сторона обмена. Направления инициатор -> ответчик и ответчик -> инициатор
+// выводятся с разными метками, поэтому ключи, IV и nonce у них разные
+type Role int
+
+const (
+	Initiator Role = iota
+	Responder
+)
+
+// метки направлений одной длины: label || salt разбирается однозначно
+var (
+	labelInitiatorToResponder = []byte("dh session: initiator->responder")
+	labelResponderToInitiator = []byte("dh session: responder->initiator")
+)
+
+// NewSessionContexts выводит из общего секрета ключ и IV (и nonce для CTR/RandomDelta)
+// для каждого направления и возвращает контексты этой стороны: send шифрует исходящие,
+// receive расшифровывает входящие; send инициатора парен receive ответчика и наоборот.
+// newCipher вызывается дважды и должен возвращать шифр без ключа, размер ключа берется
+// из ciphers.CipherInfo (DES, 3DES, DEAL); ключам семейства DES выставляется нечетность.
+// d == nil - PBKDF2-HMAC-SHA-256.
+//
+// IV и nonce контекста фиксированы: в CTR, OFB и RandomDelta второй Encrypt тем же
+// контекстом повторяет гамму. Для каждого сообщения вызывающий выводит новые контексты
+// с другим salt (например, со счетчиком сообщений).
+func NewSessionContexts(
+	secret, salt []byte,
+	role Role,
+	newCipher func() ciphers.SymmetricCipher,
+	mode ciphers.CipherMode,
+	padding ciphers.PaddingMode,
+	d kdf.KeyDeriver,
+) (send, receive *modes.SymmetricContext, err error) {
+	if len(secret) == 0 {
+		return nil, nil, errors.New("dh: empty shared secret")
+	}
+	if d == nil {
+		d = kdf.NewPBKDF2Deriver(hash.NewSHA256, sessionKDFIterations)
+	}
+
+	sendLabel, receiveLabel := labelInitiatorToResponder, labelResponderToInitiator
+	switch role {
+	case Initiator:
+	case Responder:
+		sendLabel, receiveLabel = receiveLabel, sendLabel
+	default:
+		return nil, nil, fmt.Errorf("dh: unknown role %d", role)
+	}
+
+	send, err = sessionContext(secret, salt, sendLabel, newCipher(), mode, padding, d)
+	if err != nil {
+		return nil, nil, err
+	}
+	receive, err = sessionContext(secret, salt, receiveLabel, newCipher(), mode, padding, d)
+	if err != nil {
+		return nil, nil, err
+	}
+	return send, receive, nil
+}
+
+// контекст одного направления: ключ || IV || nonce одним выводом из (secret, label || salt)
+func sessionContext(
+	secret, salt, label []byte,
+	cipher ciphers.SymmetricCipher,
+	mode ciphers.CipherMode,
+	padding ciphers.PaddingMode,
+	d kdf.KeyDeriver,
+) (*modes.SymmetricContext, error) {
+	info, ok := cipher.(ciphers.CipherInfo)
+	if !ok {
+		return nil, errors.New("dh: cipher does not report its key size (ciphers.CipherInfo)")
+	}
+
+	keySize := info.GetKeySize()
+	blockSize := cipher.GetBlockSize()
+	nonceSize := blockSize / 2
+
+	labeledSalt := append(append([]byte(nil), label...), salt...)
+	material, err := d.DeriveKey(secret, labeledSalt, keySize+blockSize+nonceSize)
+	if err != nil {
+		return nil, fmt.Errorf("dh: derive session key: %w", err)
+	}
+	key := material[:keySize]
+	iv := material[keySize : keySize+blockSize]
+	nonce := material[keySize+blockSize:]
+
+	switch info.GetCipherID() {
+	case ciphers.CipherDES, ciphers.CipherTripleDES:
+		kdf.SetDESParity(key)
+	}
+	if err := cipher.SetSymmetricKey(key); err != nil {
+		return nil, fmt.Errorf("dh: set session key: %w", err)
+	}
+	return modes.NewSymmetricContext(cipher, mode, padding, iv, nonce)
+}
@@ -0,0 +1,141 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"crypto-lab/internal/math/primality"
+)
+
+// PrimalityTest - тест простоты для генерации простых p и q
+type PrimalityTest = primality.PrimalityTest
+
+const (
+	DefaultPublicExponent = 65537
+	DefaultMinProbability = 0.999999
+	MinKeyBits            = 512
+)
+
+// KeyGenerator порождает ключи с защитой от известных слабостей:
+// |p - q| не мало (атака Ферма), d > N^(1/4) (атака Винера)
+type KeyGenerator struct {
+	test           PrimalityTest
+	minProbability float64
+	bits           int
+	random         io.Reader
+}
+
+// NewKeyGenerator: test == nil - Миллер–Рабин
+func NewKeyGenerator(test PrimalityTest, minProbability float64, bits int) (*KeyGenerator, error) {
+	if minProbability < 0.5 || minProbability >= 1 {
+		return nil, fmt.Errorf("rsa: min probability must be in [0.5, 1), got %v", minProbability)
+	}
+	if bits < MinKeyBits || bits%2 != 0 {
+		return nil, fmt.Errorf("rsa: key size must be even and >= %d bits, got %d", MinKeyBits, bits)
+	}
+	if test == nil {
+		test = primality.NewMillerRabinTest()
+	}
+	return &KeyGenerator{test: test, minProbability: minProbability, bits: bits, random: rand.Reader}, nil
+}
+
+func GenerateKey(bits int) (*PrivateKey, error) {
+	g, err := NewKeyGenerator(nil, DefaultMinProbability, bits)
+	if err != nil {
+		return nil, err
+	}
+	return g.GenerateKey()
+}
+
+func (g *KeyGenerator) GenerateKey() (*PrivateKey, error) {
+	e := big.NewInt(DefaultPublicExponent)
+
+	// |p - q| >= 2^(bits/2 - 100), FIPS 186-4, B.3.1
+	minDiff := new(big.Int).Lsh(bigOne, uint(g.bits/2-100))
+	// d > N^(1/4) с запасом: d должно иметь больше bits/4 + 1 бит
+	minDBits := g.bits/4 + 2
+
+	for {
+		p, err := g.generatePrime(g.bits/2, e)
+		if err != nil {
+			return nil, err
+		}
+		q, err := g.generatePrime(g.bits/2, e)
+		if err != nil {
+			return nil, err
+		}
+		if new(big.Int).Sub(p, q).CmpAbs(minDiff) < 0 {
+			continue
+		}
+		if p.Cmp(q) < 0 {
+			p, q = q, p
+		}
+
+		pm1 := new(big.Int).Sub(p, bigOne)
+		qm1 := new(big.Int).Sub(q, bigOne)
+		gcd := new(big.Int).GCD(nil, nil, pm1, qm1)
+		lambda := new(big.Int).Div(new(big.Int).Mul(pm1, qm1), gcd)
+		d := new(big.Int).ModInverse(e, lambda)
+		if d == nil || d.BitLen() < minDBits {
+			continue
+		}
+
+		priv, err := NewPrivateKey(p, q, e, d)
+		if err != nil {
+			return nil, err
+		}
+		if priv.N.BitLen() != g.bits {
+			continue
+		}
+		return priv, nil
+	}
+}
+
+// первые нечетные простые для отсева кандидатов делением
+var smallPrimes = []uint64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71, 73, 79, 83, 89, 97}
+
+// простое из bits бит с двумя старшими единицами (тогда p*q ровно 2*bits бит)
+// и gcd(p-1, e) = 1
+func (g *KeyGenerator) generatePrime(bits int, e *big.Int) (*big.Int, error) {
+	buf := make([]byte, (bits+7)/8)
+	excess := uint(len(buf)*8 - bits)
+	mod := new(big.Int)
+	pm1 := new(big.Int)
+
+	for {
+		if _, err := io.ReadFull(g.random, buf); err != nil {
+			return nil, err
+		}
+		buf[0] &= 0xFF >> excess
+		buf[0] |= 0xC0 >> excess
+		if excess > 6 {
+			buf[1] |= 0x80
+		}
+		buf[len(buf)-1] |= 1
+
+		p := new(big.Int).SetBytes(buf)
+		if p.BitLen() != bits {
+			return nil, errors.New("rsa: prime candidate has wrong size")
+		}
+
+		composite := false
+		for _, sp := range smallPrimes {
+			if mod.Mod(p, new(big.Int).SetUint64(sp)).Sign() == 0 {
+				composite = true
+				break
+			}
+		}
+		if composite {
+			continue
+		}
+		if pm1.GCD(nil, nil, pm1.Sub(p, bigOne), e).Cmp(bigOne) != 0 {
+			continue
+		}
+		if g.test.IsProbablyPrime(p, g.minProbability) {
+			return p, nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rsa
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var bigOne = big.NewInt(1)
+
+type PublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// Size - длина модуля в байтах (длина шифртекста и подписи)
+func (pub *PublicKey) Size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// PrivateKey хранит параметры CRT: Dp = d mod (p-1), Dq = d mod (q-1), Qinv = q^-1 mod p
+type PrivateKey struct {
+	PublicKey
+	D    *big.Int
+	P, Q *big.Int
+	Dp   *big.Int
+	Dq   *big.Int
+	Qinv *big.Int
+}
+
+// NewPrivateKey собирает ключ из p, q, e и d и досчитывает параметры CRT
+func NewPrivateKey(p, q, e, d *big.Int) (*PrivateKey, error) {
+	priv := &PrivateKey{
+		PublicKey: PublicKey{N: new(big.Int).Mul(p, q), E: new(big.Int).Set(e)},
+		D:         new(big.Int).Set(d),
+		P:         new(big.Int).Set(p),
+		Q:         new(big.Int).Set(q),
+	}
+	if err := priv.Precompute(); err != nil {
+		return nil, err
+	}
+	return priv, priv.Validate()
+}
+
+func (priv *PrivateKey) Precompute() error {
+	pm1 := new(big.Int).Sub(priv.P, bigOne)
+	qm1 := new(big.Int).Sub(priv.Q, bigOne)
+	priv.Dp = new(big.Int).Mod(priv.D, pm1)
+	priv.Dq = new(big.Int).Mod(priv.D, qm1)
+	priv.Qinv = new(big.Int).ModInverse(priv.Q, priv.P)
+	if priv.Qinv == nil {
+		return errors.New("rsa: p and q are not coprime")
+	}
+	return nil
+}
+
+func (priv *PrivateKey) Public() *PublicKey {
+	return &priv.PublicKey
+}
+
+// Validate проверяет N = p*q и e*d = 1 mod lcm(p-1, q-1)
+func (priv *PrivateKey) Validate() error {
+	if priv.N == nil || priv.E == nil || priv.D == nil || priv.P == nil || priv.Q == nil {
+		return errors.New("rsa: incomplete private key")
+	}
+	if priv.E.Cmp(bigOne) <= 0 || priv.E.Cmp(priv.N) >= 0 {
+		return fmt.Errorf("rsa: invalid public exponent %v", priv.E)
+	}
+	if new(big.Int).Mul(priv.P, priv.Q).Cmp(priv.N) != 0 {
+		return errors.New("rsa: N != p*q")
+	}
+
+	pm1 := new(big.Int).Sub(priv.P, bigOne)
+	qm1 := new(big.Int).Sub(priv.Q, bigOne)
+	gcd := new(big.Int).GCD(nil, nil, pm1, qm1)
+	lambda := new(big.Int).Div(new(big.Int).Mul(pm1, qm1), gcd)
+
+	ed := new(big.Int).Mul(priv.E, priv.D)
+	if ed.Mod(ed, lambda).Cmp(bigOne) != 0 {
+		return errors.New("rsa: e*d != 1 mod lambda(N)")
+	}
+	return nil
+}
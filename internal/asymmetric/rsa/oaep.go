@@ -0,0 +1,112 @@
+package rsa
+
+import (
+	"crypto/subtle"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// mgf1 XOR-ит out с маской MGF1(seed) (RFC 8017, B.2.1)
+func mgf1XOR(out []byte, h hash.Hash, seed []byte) {
+	var counter [4]byte
+	var digest []byte
+
+	done := 0
+	for done < len(out) {
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		digest = h.Sum(digest[:0])
+
+		for i := 0; i < len(digest) && done < len(out); i++ {
+			out[done] ^= digest[i]
+			done++
+		}
+		for i := 3; i >= 0; i-- {
+			counter[i]++
+			if counter[i] != 0 {
+				break
+			}
+		}
+	}
+}
+
+// EncryptOAEP (RFC 8017, 7.1.1): EM = 0x00 || maskedSeed || maskedDB,
+// DB = lHash || PS || 0x01 || M
+func EncryptOAEP(newHash func() hash.Hash, random io.Reader, pub *PublicKey, msg, label []byte) ([]byte, error) {
+	h := newHash()
+	k := pub.Size()
+	hLen := h.Size()
+	if len(msg) > k-2*hLen-2 {
+		return nil, ErrMessageTooLong
+	}
+
+	h.Write(label)
+	lHash := h.Sum(nil)
+
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	copy(db, lHash)
+	db[len(db)-len(msg)-1] = 0x01
+	copy(db[len(db)-len(msg):], msg)
+
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, err
+	}
+	mgf1XOR(db, h, seed)
+	mgf1XOR(seed, h, db)
+
+	c, err := EncryptRaw(pub, new(big.Int).SetBytes(em))
+	if err != nil {
+		return nil, err
+	}
+	return i2osp(c, k), nil
+}
+
+// DecryptOAEP разбирает EM без ветвлений по секретным данным: любая ошибка формата
+// возвращает один и тот же ErrDecryption (против атаки Манже)
+func DecryptOAEP(newHash func() hash.Hash, priv *PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	h := newHash()
+	k := priv.Size()
+	hLen := h.Size()
+	if len(ciphertext) != k || k < 2*hLen+2 {
+		return nil, ErrDecryption
+	}
+
+	m, err := DecryptRaw(priv, new(big.Int).SetBytes(ciphertext))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	em := i2osp(m, k)
+
+	h.Write(label)
+	lHash := h.Sum(nil)
+
+	firstByteIsZero := subtle.ConstantTimeByteEq(em[0], 0)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	mgf1XOR(seed, h, db)
+	mgf1XOR(db, h, seed)
+
+	lHashGood := subtle.ConstantTimeCompare(db[:hLen], lHash)
+
+	// ищем 0x01 после нулей PS, не выходя из цикла раньше времени
+	lookingForIndex := 1
+	index := 0
+	invalid := 0
+	rest := db[hLen:]
+	for i := range rest {
+		equals0 := subtle.ConstantTimeByteEq(rest[i], 0)
+		equals1 := subtle.ConstantTimeByteEq(rest[i], 1)
+		index = subtle.ConstantTimeSelect(lookingForIndex&equals1, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(equals1, 0, lookingForIndex)
+		invalid = subtle.ConstantTimeSelect(lookingForIndex&^equals0, 1, invalid)
+	}
+
+	if firstByteIsZero&lHashGood&^invalid&^lookingForIndex != 1 {
+		return nil, ErrDecryption
+	}
+	return rest[index+1:], nil
+}
@@ -0,0 +1,126 @@
+package rsa
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// PEM с телом PKCS#1 (RFC 8017, приложение A.1) - совместимо с OpenSSL
+const (
+	privateKeyPEMType = "RSA PRIVATE KEY"
+	publicKeyPEMType  = "RSA PUBLIC KEY"
+)
+
+var (
+	ErrNotPEM       = errors.New("rsa: no PEM block found")
+	ErrTrailingData = errors.New("rsa: trailing data after key")
+)
+
+type pkcs1PublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+type pkcs1PrivateKey struct {
+	Version int
+	N       *big.Int
+	E       *big.Int
+	D       *big.Int
+	P       *big.Int
+	Q       *big.Int
+	Dp      *big.Int
+	Dq      *big.Int
+	Qinv    *big.Int
+}
+
+func MarshalPublicKeyPEM(pub *PublicKey) ([]byte, error) {
+	der, err := asn1.Marshal(pkcs1PublicKey{N: pub.N, E: pub.E})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: der}), nil
+}
+
+func MarshalPrivateKeyPEM(priv *PrivateKey) ([]byte, error) {
+	if priv.Dp == nil {
+		if err := priv.Precompute(); err != nil {
+			return nil, err
+		}
+	}
+	der, err := asn1.Marshal(pkcs1PrivateKey{
+		N: priv.N, E: priv.E, D: priv.D, P: priv.P, Q: priv.Q,
+		Dp: priv.Dp, Dq: priv.Dq, Qinv: priv.Qinv,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: der}), nil
+}
+
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, err := decodePEM(data, publicKeyPEMType)
+	if err != nil {
+		return nil, err
+	}
+	var k pkcs1PublicKey
+	if err := unmarshalKey(block.Bytes, &k, "public"); err != nil {
+		return nil, err
+	}
+	if k.N == nil || k.N.Sign() <= 0 || k.E == nil || k.E.Cmp(bigOne) <= 0 {
+		return nil, errors.New("rsa: invalid public key values")
+	}
+	return &PublicKey{N: k.N, E: k.E}, nil
+}
+
+// ParsePrivateKeyPEM пересчитывает CRT-параметры из p, q, d и проверяет ключ
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, err := decodePEM(data, privateKeyPEMType)
+	if err != nil {
+		return nil, err
+	}
+	var k pkcs1PrivateKey
+	if err := unmarshalKey(block.Bytes, &k, "private"); err != nil {
+		return nil, err
+	}
+	if k.Version != 0 {
+		return nil, fmt.Errorf("rsa: unsupported private key version %d", k.Version)
+	}
+	if k.P == nil || k.Q == nil || k.D == nil || k.E == nil || k.P.Sign() <= 0 || k.Q.Sign() <= 0 {
+		return nil, errors.New("rsa: invalid private key values")
+	}
+
+	priv, err := NewPrivateKey(k.P, k.Q, k.E, k.D)
+	if err != nil {
+		return nil, err
+	}
+	if priv.N.Cmp(k.N) != 0 {
+		return nil, errors.New("rsa: N != p*q")
+	}
+	return priv, nil
+}
+
+func decodePEM(data []byte, blockType string) (*pem.Block, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNotPEM
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("rsa: unexpected PEM block %q, want %q", block.Type, blockType)
+	}
+	return block, nil
+}
+
+// разбор DER ключа: байты после него - отдельная ошибка ErrTrailingData
+func unmarshalKey(der []byte, key interface{}, kind string) error {
+	rest, err := asn1.Unmarshal(der, key)
+	if err != nil {
+		return fmt.Errorf("rsa: malformed %s key: %w", kind, err)
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("%w: %d bytes after %s key", ErrTrailingData, len(rest), kind)
+	}
+	return nil
+}
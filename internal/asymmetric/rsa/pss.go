@@ -0,0 +1,135 @@
+package rsa
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+)
+
+// PSSSaltLengthEqualsHash - соль длиной в дайджест (рекомендация RFC 8017)
+const PSSSaltLengthEqualsHash = -1
+
+// SignPSS подписывает готовый дайджест (RFC 8017, 8.1.1, EMSA-PSS, 9.1.1)
+func SignPSS(newHash func() hash.Hash, random io.Reader, priv *PrivateKey, digest []byte, saltLen int) ([]byte, error) {
+	h := newHash()
+	if saltLen == PSSSaltLengthEqualsHash {
+		saltLen = h.Size()
+	}
+	if saltLen < 0 {
+		return nil, errors.New("rsa: invalid PSS salt length")
+	}
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, err
+	}
+
+	emBits := priv.N.BitLen() - 1
+	em, err := emsaPSSEncode(h, digest, salt, emBits)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := DecryptRaw(priv, new(big.Int).SetBytes(em))
+	if err != nil {
+		return nil, err
+	}
+	// проверка подписи перед выдачей: сбой в CRT раскрыл бы множитель N
+	if new(big.Int).Exp(s, priv.E, priv.N).Cmp(new(big.Int).SetBytes(em)) != 0 {
+		return nil, errors.New("rsa: internal error: signature self-check failed")
+	}
+	return i2osp(s, priv.Size()), nil
+}
+
+func VerifyPSS(newHash func() hash.Hash, pub *PublicKey, digest, sig []byte, saltLen int) error {
+	h := newHash()
+	if saltLen == PSSSaltLengthEqualsHash {
+		saltLen = h.Size()
+	}
+	if len(sig) != pub.Size() {
+		return ErrVerification
+	}
+	s := new(big.Int).SetBytes(sig)
+	m, err := EncryptRaw(pub, s)
+	if err != nil {
+		return ErrVerification
+	}
+
+	emBits := pub.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	if m.BitLen() > emLen*8 {
+		return ErrVerification
+	}
+	return emsaPSSVerify(h, digest, i2osp(m, emLen), emBits, saltLen)
+}
+
+// M' = 0x00*8 || mHash || salt, H = Hash(M'), EM = maskedDB || H || 0xBC
+func emsaPSSEncode(h hash.Hash, mHash, salt []byte, emBits int) ([]byte, error) {
+	hLen := h.Size()
+	emLen := (emBits + 7) / 8
+	if len(mHash) != hLen {
+		return nil, errors.New("rsa: digest length does not match hash")
+	}
+	if emLen < hLen+len(salt)+2 {
+		return nil, ErrMessageTooLong
+	}
+
+	em := make([]byte, emLen)
+	db := em[:emLen-hLen-1]
+	hPart := em[emLen-hLen-1 : emLen-1]
+
+	h.Reset()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	h.Sum(hPart[:0])
+
+	db[len(db)-len(salt)-1] = 0x01
+	copy(db[len(db)-len(salt):], salt)
+	mgf1XOR(db, h, hPart)
+	db[0] &= 0xFF >> uint(8*emLen-emBits)
+	em[emLen-1] = 0xBC
+	return em, nil
+}
+
+func emsaPSSVerify(h hash.Hash, mHash, em []byte, emBits, saltLen int) error {
+	hLen := h.Size()
+	emLen := (emBits + 7) / 8
+	if len(mHash) != hLen || emLen != len(em) || emLen < hLen+saltLen+2 {
+		return ErrVerification
+	}
+	if em[emLen-1] != 0xBC {
+		return ErrVerification
+	}
+
+	db := append([]byte(nil), em[:emLen-hLen-1]...)
+	hPart := em[emLen-hLen-1 : emLen-1]
+	topBits := byte(0xFF << uint(8-(8*emLen-emBits)))
+	if 8*emLen-emBits > 0 && db[0]&topBits != 0 {
+		return ErrVerification
+	}
+
+	mgf1XOR(db, h, hPart)
+	db[0] &= 0xFF >> uint(8*emLen-emBits)
+
+	psLen := emLen - hLen - saltLen - 2
+	for _, b := range db[:psLen] {
+		if b != 0 {
+			return ErrVerification
+		}
+	}
+	if db[psLen] != 0x01 {
+		return ErrVerification
+	}
+	salt := db[len(db)-saltLen:]
+
+	h.Reset()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	if !bytes.Equal(h.Sum(nil), hPart) {
+		return ErrVerification
+	}
+	return nil
+}
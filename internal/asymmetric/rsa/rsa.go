@@ -0,0 +1,51 @@
+package rsa
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrMessageTooLong = errors.New("rsa: message too long for RSA key size")
+	ErrDecryption     = errors.New("rsa: decryption error")
+	ErrVerification   = errors.New("rsa: verification error")
+)
+
+// EncryptRaw - учебное RSA без дополнения: c = m^e mod N; только для 0 <= m < N
+func EncryptRaw(pub *PublicKey, m *big.Int) (*big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(pub.N) >= 0 {
+		return nil, ErrMessageTooLong
+	}
+	return new(big.Int).Exp(m, pub.E, pub.N), nil
+}
+
+// DecryptRaw: m = c^d mod N через CRT (см. decryptCRT)
+func DecryptRaw(priv *PrivateKey, c *big.Int) (*big.Int, error) {
+	if c.Sign() < 0 || c.Cmp(priv.N) >= 0 {
+		return nil, ErrDecryption
+	}
+	return priv.decryptCRT(c), nil
+}
+
+// decryptCRT: m1 = c^Dp mod p, m2 = c^Dq mod q, h = Qinv*(m1 - m2) mod p, m = m2 + h*q.
+// Две экспоненты по модулям вдвое короче - примерно в 4 раза быстрее c^d mod N.
+func (priv *PrivateKey) decryptCRT(c *big.Int) *big.Int {
+	if priv.Dp == nil || priv.Dq == nil || priv.Qinv == nil {
+		return new(big.Int).Exp(c, priv.D, priv.N)
+	}
+
+	m1 := new(big.Int).Exp(c, priv.Dp, priv.P)
+	m2 := new(big.Int).Exp(c, priv.Dq, priv.Q)
+
+	h := m1.Sub(m1, m2)
+	h.Mul(h, priv.Qinv)
+	h.Mod(h, priv.P)
+
+	h.Mul(h, priv.Q)
+	return h.Add(h, m2)
+}
+
+// I2OSP: число -> строка байт фиксированной длины (RFC 8017, 4.1)
+func i2osp(x *big.Int, size int) []byte {
+	return x.FillBytes(make([]byte, size))
+}
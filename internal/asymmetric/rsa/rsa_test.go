@@ -0,0 +1,244 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"crypto-lab/internal/hash"
+)
+
+var (
+	testKeyOnce sync.Once
+	testKey     *PrivateKey
+)
+
+func getTestKey(t *testing.T) *PrivateKey {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		var err error
+		testKey, err = GenerateKey(1024)
+		if err != nil {
+			t.Fatalf("GenerateKey: %v", err)
+		}
+	})
+	return testKey
+}
+
+func toStdlib(priv *PrivateKey) *stdrsa.PrivateKey {
+	k := &stdrsa.PrivateKey{
+		PublicKey: stdrsa.PublicKey{N: priv.N, E: int(priv.E.Int64())},
+		D:         priv.D,
+		Primes:    []*big.Int{priv.P, priv.Q},
+	}
+	k.Precompute()
+	return k
+}
+
+// считает вызовы, чтобы убедиться, что генератор использует переданный тест
+type countingTest struct{ calls int }
+
+func (c *countingTest) IsProbablyPrime(n *big.Int, minProbability float64) bool {
+	c.calls++
+	return n.ProbablyPrime(20)
+}
+
+func TestGenerateKey(t *testing.T) {
+	test := &countingTest{}
+	g, err := NewKeyGenerator(test, 0.99, 768)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := g.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if priv.N.BitLen() != 768 {
+		t.Errorf("expected 768-bit modulus, got %d", priv.N.BitLen())
+	}
+	if err := priv.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	if test.calls == 0 {
+		t.Errorf("custom primality test was not used")
+	}
+	// защита от Винера: d > N^(1/4)
+	if priv.D.BitLen() <= 768/4 {
+		t.Errorf("private exponent too small: %d bits", priv.D.BitLen())
+	}
+	if err := toStdlib(priv).Validate(); err != nil {
+		t.Errorf("stdlib rejects generated key: %v", err)
+	}
+}
+
+func TestKeyGeneratorInvalidParameters(t *testing.T) {
+	if _, err := NewKeyGenerator(nil, 0.4, 1024); err == nil {
+		t.Errorf("expected error for probability < 0.5")
+	}
+	if _, err := NewKeyGenerator(nil, 1, 1024); err == nil {
+		t.Errorf("expected error for probability 1")
+	}
+	if _, err := NewKeyGenerator(nil, 0.9, 256); err == nil {
+		t.Errorf("expected error for too short key")
+	}
+}
+
+func TestRawRSAAndCRT(t *testing.T) {
+	priv := getTestKey(t)
+	m := big.NewInt(0x1234567890)
+
+	c, err := EncryptRaw(&priv.PublicKey, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecryptRaw(priv, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(m) != 0 {
+		t.Errorf("expected %v, got %v", m, got)
+	}
+	if plain := new(big.Int).Exp(c, priv.D, priv.N); plain.Cmp(got) != 0 {
+		t.Errorf("CRT result differs from c^d mod N")
+	}
+
+	if _, err := EncryptRaw(&priv.PublicKey, priv.N); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("expected ErrMessageTooLong for m = N, got %v", err)
+	}
+}
+
+func TestOAEPInteroperability(t *testing.T) {
+	priv := getTestKey(t)
+	std := toStdlib(priv)
+	msg := []byte("OAEP message")
+	label := []byte("label")
+
+	ours, err := EncryptOAEP(hash.NewSHA256, rand.Reader, &priv.PublicKey, msg, label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := stdrsa.DecryptOAEP(sha256.New(), nil, std, ours, label)
+	if err != nil || !bytes.Equal(plain, msg) {
+		t.Errorf("stdlib cannot decrypt our ciphertext: %v", err)
+	}
+
+	theirs, err := stdrsa.EncryptOAEP(sha256.New(), rand.Reader, &std.PublicKey, msg, label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err = DecryptOAEP(hash.NewSHA256, priv, theirs, label)
+	if err != nil || !bytes.Equal(plain, msg) {
+		t.Errorf("cannot decrypt stdlib ciphertext: %v", err)
+	}
+
+	if _, err := DecryptOAEP(hash.NewSHA256, priv, ours, []byte("other")); !errors.Is(err, ErrDecryption) {
+		t.Errorf("expected ErrDecryption for wrong label, got %v", err)
+	}
+	ours[len(ours)-1] ^= 1
+	if _, err := DecryptOAEP(hash.NewSHA256, priv, ours, label); !errors.Is(err, ErrDecryption) {
+		t.Errorf("expected ErrDecryption for tampered ciphertext, got %v", err)
+	}
+
+	tooLong := make([]byte, priv.Size()-2*sha256.Size-1)
+	if _, err := EncryptOAEP(hash.NewSHA256, rand.Reader, &priv.PublicKey, tooLong, nil); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("expected ErrMessageTooLong, got %v", err)
+	}
+}
+
+func TestPSSInteroperability(t *testing.T) {
+	priv := getTestKey(t)
+	std := toStdlib(priv)
+	digest := hash.Sum256([]byte("message to sign"))
+
+	sig, err := SignPSS(hash.NewSHA256, rand.Reader, priv, digest, PSSSaltLengthEqualsHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &stdrsa.PSSOptions{SaltLength: stdrsa.PSSSaltLengthEqualsHash}
+	if err := stdrsa.VerifyPSS(&std.PublicKey, crypto.SHA256, digest, sig, opts); err != nil {
+		t.Errorf("stdlib rejects our signature: %v", err)
+	}
+
+	theirs, err := stdrsa.SignPSS(rand.Reader, std, crypto.SHA256, digest, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPSS(hash.NewSHA256, &priv.PublicKey, digest, theirs, PSSSaltLengthEqualsHash); err != nil {
+		t.Errorf("stdlib signature rejected: %v", err)
+	}
+
+	other := hash.Sum256([]byte("another message"))
+	if err := VerifyPSS(hash.NewSHA256, &priv.PublicKey, other, sig, PSSSaltLengthEqualsHash); !errors.Is(err, ErrVerification) {
+		t.Errorf("expected ErrVerification for other digest, got %v", err)
+	}
+	sig[0] ^= 1
+	if err := VerifyPSS(hash.NewSHA256, &priv.PublicKey, digest, sig, PSSSaltLengthEqualsHash); !errors.Is(err, ErrVerification) {
+		t.Errorf("expected ErrVerification for tampered signature, got %v", err)
+	}
+}
+
+func TestPEMRoundTrip(t *testing.T) {
+	priv := getTestKey(t)
+
+	privPEM, err := MarshalPrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePrivateKeyPEM(privPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.N.Cmp(priv.N) != 0 || parsed.D.Cmp(priv.D) != 0 || parsed.Qinv.Cmp(priv.Qinv) != 0 {
+		t.Errorf("private key changed after round trip")
+	}
+
+	block, _ := pem.Decode(privPEM)
+	if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		t.Errorf("exported key is not PKCS#1: %v", err)
+	}
+
+	pubPEM, err := MarshalPublicKeyPEM(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePublicKeyPEM(pubPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.N.Cmp(priv.N) != 0 || pub.E.Cmp(priv.E) != 0 {
+		t.Errorf("public key changed after round trip")
+	}
+
+	if _, err := ParsePrivateKeyPEM(pubPEM); err == nil {
+		t.Errorf("expected error parsing public key as private")
+	}
+	if _, err := ParsePublicKeyPEM([]byte("garbage")); !errors.Is(err, ErrNotPEM) {
+		t.Errorf("expected ErrNotPEM, got %v", err)
+	}
+
+	// лишние байты после DER и битый DER - разные ошибки
+	pubBlock, _ := pem.Decode(pubPEM)
+	trailing := pem.EncodeToMemory(&pem.Block{Type: pubBlock.Type, Bytes: append(pubBlock.Bytes, 0)})
+	if _, err := ParsePublicKeyPEM(trailing); !errors.Is(err, ErrTrailingData) {
+		t.Errorf("expected ErrTrailingData, got %v", err)
+	}
+	trailing = pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: append(block.Bytes, 0)})
+	if _, err := ParsePrivateKeyPEM(trailing); !errors.Is(err, ErrTrailingData) {
+		t.Errorf("expected ErrTrailingData, got %v", err)
+	}
+	var syntaxErr asn1.SyntaxError
+	truncated := pem.EncodeToMemory(&pem.Block{Type: pubBlock.Type, Bytes: pubBlock.Bytes[:10]})
+	if _, err := ParsePublicKeyPEM(truncated); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected wrapped asn1.SyntaxError, got %v", err)
+	}
+}
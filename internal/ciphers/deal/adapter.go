@@ -7,14 +7,14 @@ import (
 	"crypto-lab/internal/ciphers/des"
 )
 
-type DESAdapter struct {
-	desCipher *des.DESCipher
-}
+// DESAdapter - DES как раундовая функция DEAL, ключ раунда - ключ DES.
+// Состояния нет: DES заводится на каждый вызов, поэтому адаптер безопасен
+// для параллельных вызовов. DEALCipher держит заранее настроенные DES по
+// раундам и адаптер не использует
+type DESAdapter struct{}
 
 func NewDESAdapter() *DESAdapter {
-	return &DESAdapter{
-		desCipher: des.NewDES(),
-	}
+	return &DESAdapter{}
 }
 
 func (d *DESAdapter) Transform(inputBlock, roundKey []byte) ([]byte, error) {
@@ -25,11 +25,11 @@ func (d *DESAdapter) Transform(inputBlock, roundKey []byte) ([]byte, error) {
 		return nil, fmt.Errorf("invalid round key (%d)", len(roundKey))
 	}
 
-	if err := d.desCipher.SetSymmetricKey(roundKey); err != nil {
+	desCipher := des.NewDES()
+	if err := desCipher.SetSymmetricKey(roundKey); err != nil {
 		return nil, err
 	}
-
-	return d.desCipher.Encrypt(inputBlock)
+	return desCipher.Encrypt(inputBlock)
 }
 
 func (d *DESAdapter) GetInputBlockSize() int  { return 8 }
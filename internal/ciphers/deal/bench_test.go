@@ -0,0 +1,49 @@
+package deal
+
+import (
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/modes"
+)
+
+func BenchmarkDEALEncrypt(b *testing.B) {
+	deal, _ := NewDEALCipher(16)
+	deal.SetSymmetricKey(make([]byte, 16))
+	block := make([]byte, 16)
+
+	b.SetBytes(16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		deal.Encrypt(block)
+	}
+}
+
+func BenchmarkDEALEncryptInto(b *testing.B) {
+	deal, _ := NewDEALCipher(16)
+	deal.SetSymmetricKey(make([]byte, 16))
+	block := make([]byte, 16)
+
+	b.SetBytes(16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		deal.EncryptInto(block, block)
+	}
+}
+
+func BenchmarkDEALCTR(b *testing.B) {
+	deal, _ := NewDEALCipher(16)
+	deal.SetSymmetricKey(make([]byte, 16))
+	ctx, err := modes.NewSymmetricContext(deal, ciphers.CTR, ciphers.Zeros, nil, make([]byte, 8))
+	if err != nil {
+		b.Fatal(err)
+	}
+	data := make([]byte, 4096)
+
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := ctx.Encrypt(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,14 +4,27 @@ import (
 	"fmt"
 
 	"crypto-lab/internal/ciphers"
-	"crypto-lab/internal/ciphers/feistel"
+	"crypto-lab/internal/ciphers/des"
 )
 
 type DEALCipher struct {
 	keySize      int
-	feistel      *feistel.Feistel
 	keyExpansion ciphers.KeyExpansion
 	roundKeys    [][]byte
+	roundCiphers []*des.DESCipher // DES с ключами раундов, раундовая функция сети Фейстеля
+}
+
+func init() {
+	ciphers.Register(ciphers.Algorithm{
+		Name: "DEAL", ID: ciphers.CipherDEAL, KeySizes: []int{16, 24, 32}, BlockSize: 16,
+		New: func(keySize int) (ciphers.SymmetricCipher, error) {
+			d, err := NewDEALCipher(keySize)
+			if err != nil {
+				return nil, err
+			}
+			return d, nil
+		},
+	})
 }
 
 func NewDEALCipher(keySize int) (*DEALCipher, error) {
@@ -24,18 +37,8 @@ func NewDEALCipher(keySize int) (*DEALCipher, error) {
 		return nil, fmt.Errorf("error: NewDEALKeyExpansion: %w", err)
 	}
 
-	roundFunction := NewDESAdapter()
-
-	numRounds := 6
-	if keySize == 32 {
-		numRounds = 8
-	}
-
-	fe := feistel.NewFeistel(keyExpansion, roundFunction, numRounds)
-
 	return &DEALCipher{
 		keySize:      keySize,
-		feistel:      fe,
 		keyExpansion: keyExpansion,
 	}, nil
 }
@@ -48,38 +51,83 @@ func (d *DEALCipher) SetSymmetricKey(key []byte) error {
 	if err != nil {
 		return fmt.Errorf("error: key expansion: %w", err)
 	}
+	roundCiphers := make([]*des.DESCipher, len(roundKeys))
+	for i, k := range roundKeys {
+		roundCiphers[i] = des.NewDES()
+		if err := roundCiphers[i].SetSymmetricKey(k); err != nil {
+			return fmt.Errorf("error: round %d DES key: %w", i, err)
+		}
+	}
 	d.roundKeys = roundKeys
+	d.roundCiphers = roundCiphers
 	return nil
 }
 
 func (d *DEALCipher) Encrypt(block []byte) ([]byte, error) {
-	if d.roundKeys == nil {
-		return nil, fmt.Errorf("error: roundKeys empty")
-	}
-	if len(block) != 16 {
-		return nil, fmt.Errorf(" block hasn`t 16 bytes(%d)", len(block))
-	}
-	out, err := d.feistel.EncryptRounds(d.roundKeys, block)
-	if err != nil {
-		return nil, fmt.Errorf("error: Feistel encryption %w", err)
+	out := make([]byte, 16)
+	if err := d.cryptInto(out, block, false); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
 func (d *DEALCipher) Decrypt(block []byte) ([]byte, error) {
+	out := make([]byte, 16)
+	if err := d.cryptInto(out, block, true); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EncryptInto - Encrypt без аллокаций: сеть Фейстеля на буферах в стеке,
+// раунды через DES EncryptInto
+func (d *DEALCipher) EncryptInto(dst, src []byte) error {
+	return d.cryptInto(dst, src, false)
+}
+
+func (d *DEALCipher) DecryptInto(dst, src []byte) error {
+	return d.cryptInto(dst, src, true)
+}
+
+func (d *DEALCipher) cryptInto(dst, src []byte, decrypt bool) error {
 	if d.roundKeys == nil {
-		return nil, fmt.Errorf("error: roundKeys empty")
+		return fmt.Errorf("error: roundKeys empty")
 	}
-	if len(block) != 16 {
-		return nil, fmt.Errorf(" block hasn`t 16 bytes(%d)", len(block))
+	if len(src) != 16 {
+		return fmt.Errorf(" block hasn`t 16 bytes(%d)", len(src))
 	}
-	out, err := d.feistel.DecryptRounds(d.roundKeys, block)
-	if err != nil {
-		return nil, fmt.Errorf("error: Feistel encryption %w", err)
+	if len(dst) < 16 {
+		return fmt.Errorf("error: output buffer must be at least 16 bytes, got %d", len(dst))
 	}
-	return out, nil
+
+	var l, r, f [8]byte
+	copy(l[:], src[:8])
+	copy(r[:], src[8:16])
+	n := len(d.roundCiphers)
+	for i := 0; i < n; i++ {
+		round := i
+		if decrypt {
+			round = n - 1 - i
+		}
+		//Li=Ri-1, Ri=Li-1 XOR DES(Ki, Ri-1)
+		if err := d.roundCiphers[round].EncryptInto(f[:], r[:]); err != nil {
+			return fmt.Errorf("error: round %d: %w", i, err)
+		}
+		for j := range f {
+			f[j] ^= l[j]
+		}
+		l, r = r, f
+	}
+	copy(dst[:8], r[:])
+	copy(dst[8:16], l[:])
+	return nil
 }
 
 func (d *DEALCipher) GetBlockSize() int { return 16 }
 
+func (d *DEALCipher) GetCipherID() ciphers.CipherID { return ciphers.CipherDEAL }
+func (d *DEALCipher) GetKeySize() int               { return d.keySize }
+
 var _ ciphers.SymmetricCipher = (*DEALCipher)(nil)
+var _ ciphers.CipherInfo = (*DEALCipher)(nil)
+var _ ciphers.BlockCipherInto = (*DEALCipher)(nil)
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"crypto/rand"
 	"testing"
+
+	"crypto-lab/internal/ciphers/feistel"
 )
 
 // 256
@@ -81,3 +83,38 @@ func TestDEALKnownVector(t *testing.T) {
 		t.Errorf("error: different ciphertexts")
 	}
 }
+
+// Encrypt и EncryptInto совпадают с сетью Фейстеля на DESAdapter (эталон), DecryptInto обращает
+func TestEncryptIntoMatchesFeistel(t *testing.T) {
+	for _, keySize := range []int{16, 24, 32} {
+		deal, _ := NewDEALCipher(keySize)
+		deal.SetSymmetricKey(bytes.Repeat([]byte{0x3C}, keySize))
+		block := []byte("Hello world!!!!!")
+
+		reference := feistel.NewFeistel(deal.keyExpansion, NewDESAdapter(), len(deal.roundKeys))
+		expected, err := reference.EncryptRounds(deal.roundKeys, block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := deal.Encrypt(block); !bytes.Equal(got, expected) {
+			t.Errorf("DEAL-%d: Encrypt = %x, expected %x", keySize*8, got, expected)
+		}
+
+		buf := append([]byte(nil), block...)
+		if err := deal.EncryptInto(buf, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Errorf("DEAL-%d: EncryptInto = %x, expected %x", keySize*8, buf, expected)
+		}
+		if err := deal.DecryptInto(buf, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, block) {
+			t.Errorf("DEAL-%d: DecryptInto = %x, expected %x", keySize*8, buf, block)
+		}
+		if allocs := testing.AllocsPerRun(20, func() { deal.EncryptInto(buf, buf) }); allocs != 0 {
+			t.Errorf("DEAL-%d: EncryptInto: %.0f allocs", keySize*8, allocs)
+		}
+	}
+}
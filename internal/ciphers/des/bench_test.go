@@ -0,0 +1,58 @@
+package des
+
+import "testing"
+
+func BenchmarkRoundFunction(b *testing.B) {
+	in := []byte{0xF0, 0xAA, 0xF0, 0xAA}
+	key := []byte{0x1b, 0x02, 0xef, 0xfc, 0x70, 0x72}
+
+	b.Run("bitwise", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			bitwiseTransform(in, key)
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		rf := NewDESRoundFunction()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			rf.Transform(in, key)
+		}
+	})
+}
+
+func BenchmarkDESEncrypt(b *testing.B) {
+	des := NewDES()
+	des.SetSymmetricKey([]byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1})
+	block := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+
+	b.SetBytes(8)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		des.Encrypt(block)
+	}
+}
+
+func BenchmarkDESEncryptInto(b *testing.B) {
+	des := NewDES()
+	des.SetSymmetricKey([]byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1})
+	block := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+
+	b.SetBytes(8)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		des.EncryptInto(block, block)
+	}
+}
+
+func BenchmarkDESEncryptBatch(b *testing.B) {
+	des := NewDES()
+	des.SetSymmetricKey([]byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1})
+	buf := make([]byte, 8*BatchSize)
+
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		des.EncryptBatch(buf, buf)
+	}
+}
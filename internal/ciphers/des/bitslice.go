@@ -0,0 +1,171 @@
+package des
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Битовые срезы: 64 независимых блока обрабатываются разом, слово i хранит бит i+1
+// (нумерация стандарта, бит 1 - старший) всех блоков. IP, E и P становятся
+// перестановкой слов, S-блоки - дешифратором входа на 64 минтерма по таблицам
+// DES.S(): выходной бит - OR минтермов, на которых он равен 1.
+
+const BatchSize = 64
+
+type slice [64]uint64
+
+// sboxTerms[s][o] - входы S-блока s, на которых выходной бит o (0 - старший) равен 1
+var sboxTerms [8][4][]uint8
+
+var (
+	ipIndex    [64]int
+	ipInvIndex [64]int
+	eIndex     [48]int
+	pIndex     [32]int
+)
+
+func init() {
+	for i, src := range DES.IP() {
+		ipIndex[i] = src - 1
+	}
+	for i, src := range DES.IP_INV() {
+		ipInvIndex[i] = src - 1
+	}
+	for i, src := range DES.E() {
+		eIndex[i] = src - 1
+	}
+	for i, src := range DES.P() {
+		pIndex[i] = src - 1
+	}
+
+	for s, box := range sBoxes {
+		for x := 0; x < 64; x++ {
+			v := box[(x>>4)&2|x&1][(x>>1)&0x0F]
+			for o := 0; o < 4; o++ {
+				if v>>(3-o)&1 != 0 {
+					sboxTerms[s][o] = append(sboxTerms[s][o], uint8(x))
+				}
+			}
+		}
+	}
+}
+
+// decode3 - минтермы трех битов, a - старший: t[v] = 1 там, где (a, b, c) = v
+func decode3(a, b, c uint64, t *[8]uint64) {
+	na, nb, nc := ^a, ^b, ^c
+	ab := [4]uint64{na & nb, na & b, a & nb, a & b}
+	for i, x := range ab {
+		t[2*i] = x & nc
+		t[2*i+1] = x & c
+	}
+}
+
+// bitsliceSBox: in[0] - старший бит входа S-блока, out[0] - старший бит выхода
+func bitsliceSBox(s int, in *[6]uint64, out []uint64) {
+	var hi, lo [8]uint64
+	decode3(in[0], in[1], in[2], &hi)
+	decode3(in[3], in[4], in[5], &lo)
+
+	var terms [64]uint64
+	for h := 0; h < 8; h++ {
+		for l := 0; l < 8; l++ {
+			terms[h<<3|l] = hi[h] & lo[l]
+		}
+	}
+	for o := 0; o < 4; o++ {
+		var v uint64
+		for _, x := range sboxTerms[s][o] {
+			v |= terms[x]
+		}
+		out[o] = v
+	}
+}
+
+// sliceKeys - раундовые ключи в виде масок: бит установлен - слово из единиц
+func sliceKeys(roundKeys [][]byte) [][48]uint64 {
+	keys := make([][48]uint64, len(roundKeys))
+	for r, k := range roundKeys {
+		for i := 0; i < 48; i++ {
+			if k[i/8]>>(7-i%8)&1 != 0 {
+				keys[r][i] = ^uint64(0)
+			}
+		}
+	}
+	return keys
+}
+
+// transpose - транспонирование битовой матрицы 64x64 (Hacker's Delight, 7-3):
+// бит 63-c слова r переходит в бит 63-r слова c. Из блоков получаются срезы
+// (блок j - бит 63-j каждого слова), повторный вызов возвращает блоки.
+func transpose(a *slice) {
+	m := uint64(0x00000000FFFFFFFF)
+	for j := 32; j != 0; j, m = j>>1, m^(m<<(j>>1)) {
+		for k := 0; k < 64; k = (k + j + 1) &^ j {
+			t := (a[k] ^ a[k+j]>>uint(j)) & m
+			a[k] ^= t
+			a[k+j] ^= t << uint(j)
+		}
+	}
+}
+
+// bitsliceCrypt прогоняет срезы через IP, раунды с keys по порядку и IP^-1
+func bitsliceCrypt(state *slice, keys [][48]uint64) {
+	var l, r, f [32]uint64
+	for i := 0; i < 32; i++ {
+		l[i] = state[ipIndex[i]]
+		r[i] = state[ipIndex[32+i]]
+	}
+
+	var in [6]uint64
+	var sOut [32]uint64
+	for _, k := range keys {
+		for s := 0; s < 8; s++ {
+			for b := 0; b < 6; b++ {
+				in[b] = r[eIndex[6*s+b]] ^ k[6*s+b]
+			}
+			bitsliceSBox(s, &in, sOut[4*s:4*s+4])
+		}
+		for i := 0; i < 32; i++ {
+			f[i] = l[i] ^ sOut[pIndex[i]]
+		}
+		l, r = r, f
+	}
+
+	// финальная перестановка половин: R || L
+	var pre slice
+	copy(pre[:32], r[:])
+	copy(pre[32:], l[:])
+	for i := 0; i < 64; i++ {
+		state[i] = pre[ipInvIndex[i]]
+	}
+}
+
+// cryptBatch обрабатывает src кусками по BatchSize блоков, неполный хвост
+// дополняется нулевыми блоками
+func cryptBatch(dst, src []byte, keys [][48]uint64) error {
+	if len(src)%8 != 0 {
+		return fmt.Errorf("DES: batch length must be a multiple of 8, got %d", len(src))
+	}
+	if len(dst) < len(src) {
+		return fmt.Errorf("DES: batch output too short: %d < %d", len(dst), len(src))
+	}
+
+	var state slice
+	for off := 0; off < len(src); off += 8 * BatchSize {
+		n := (len(src) - off) / 8
+		if n > BatchSize {
+			n = BatchSize
+		}
+		state = slice{}
+		for j := 0; j < n; j++ {
+			state[j] = binary.BigEndian.Uint64(src[off+8*j:])
+		}
+		transpose(&state)
+		bitsliceCrypt(&state, keys)
+		transpose(&state)
+		for j := 0; j < n; j++ {
+			binary.BigEndian.PutUint64(dst[off+8*j:], state[j])
+		}
+	}
+	return nil
+}
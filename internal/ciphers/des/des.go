@@ -1,7 +1,9 @@
 package des
 
 import (
+	"encoding/binary"
 	"fmt"
+	"sync"
 
 	"crypto-lab/internal/ciphers"
 	feistel "crypto-lab/internal/ciphers/feistel"
@@ -12,22 +14,81 @@ type DESCipher struct {
 	feistel      *feistel.Feistel
 	keyExpansion ciphers.KeyExpansion
 	roundKeys    [][]byte
+	rounds       int
+	keys48       []uint64 // раундовые ключи числами, для EncryptInto
+
+	// ключи для битовых срезов строятся при первом пакетном вызове:
+	// DEAL меняет ключ DES в каждом раунде, и пакетный путь ему не нужен
+	sliceMu  sync.Mutex
+	sliceEnc [][48]uint64 // по порядку шифрования
+	sliceDec [][48]uint64
 }
 
-func (d *DESCipher) GetBlockSize() int {
-	panic("unimplemented")
+func (d *DESCipher) GetBlockSize() int { return 8 }
+
+func (d *DESCipher) GetCipherID() ciphers.CipherID { return ciphers.CipherDES }
+func (d *DESCipher) GetKeySize() int               { return 8 }
+
+const Rounds = 16
+
+func init() {
+	ciphers.Register(ciphers.Algorithm{
+		Name: "DES", ID: ciphers.CipherDES, KeySizes: []int{8}, BlockSize: 8,
+		New: func(int) (ciphers.SymmetricCipher, error) { return NewDES(), nil },
+	})
+	// 3DES-128 - EDE2 (K3 = K1), 3DES-192 - EDE3
+	ciphers.Register(ciphers.Algorithm{
+		Name: "3DES", ID: ciphers.CipherTripleDES, KeySizes: []int{24, 16}, BlockSize: 8,
+		New: func(int) (ciphers.SymmetricCipher, error) { return NewTripleDES(), nil },
+	})
+}
+
+var (
+	ipTable    = mustCompile(DES.IP(), 8)
+	ipInvTable = mustCompile(DES.IP_INV(), 8)
+)
+
+// таблицы DES заданы константами, ошибка компиляции - ошибка в самих таблицах
+func mustCompile(pBlock []int, inputBytes int) *permute.Table {
+	table, err := permute.Compile(pBlock, inputBytes, true, true)
+	if err != nil {
+		panic(fmt.Sprintf("DES: invalid permutation table: %v", err))
+	}
+	return table
 }
 
 func NewDES() *DESCipher {
+	cipher, _ := NewDESWithRounds(Rounds)
+	return cipher
+}
+
+// NewDESWithRounds - DES с уменьшенным числом раундов для криптоанализа:
+// используются первые rounds раундовых ключей полного расписания
+func NewDESWithRounds(rounds int) (*DESCipher, error) {
+	if rounds < 1 || rounds > Rounds {
+		return nil, fmt.Errorf("DES: rounds must be in [1, %d], got %d", Rounds, rounds)
+	}
 	keyExpansion := NewDESKeyExpansion()
 	roundFunction := NewDESRoundFunction()
 
-	feistelNetwork := feistel.NewFeistel(keyExpansion, roundFunction, 16)
+	feistelNetwork := feistel.NewFeistel(keyExpansion, roundFunction, rounds)
 
 	return &DESCipher{
 		feistel:      feistelNetwork,
 		keyExpansion: keyExpansion,
+		rounds:       rounds,
+	}, nil
+}
+
+func (d *DESCipher) GetRounds() int { return d.rounds }
+
+// RoundKeys - копия раундовых ключей (по 6 байт), nil до SetSymmetricKey
+func (d *DESCipher) RoundKeys() [][]byte {
+	keys := make([][]byte, len(d.roundKeys))
+	for i, k := range d.roundKeys {
+		keys[i] = append([]byte(nil), k...)
 	}
+	return keys
 }
 
 func (d *DESCipher) SetSymmetricKey(key []byte) error {
@@ -40,10 +101,56 @@ func (d *DESCipher) SetSymmetricKey(key []byte) error {
 		return fmt.Errorf("DES key expansion failed: %w", err)
 	}
 
-	d.roundKeys = roundKeys
+	d.roundKeys = roundKeys[:d.rounds]
+	d.keys48 = make([]uint64, len(d.roundKeys))
+	for i, k := range d.roundKeys {
+		for _, b := range k {
+			d.keys48[i] = d.keys48[i]<<8 | uint64(b)
+		}
+	}
+	d.sliceMu.Lock()
+	d.sliceEnc, d.sliceDec = nil, nil
+	d.sliceMu.Unlock()
 	return nil
 }
 
+func (d *DESCipher) batchKeys(encrypt bool) ([][48]uint64, error) {
+	if d.roundKeys == nil {
+		return nil, fmt.Errorf("DES: round keys not set, call SetSymmetricKey first")
+	}
+	d.sliceMu.Lock()
+	defer d.sliceMu.Unlock()
+	if d.sliceEnc == nil {
+		d.sliceEnc = sliceKeys(d.roundKeys)
+		d.sliceDec = make([][48]uint64, len(d.sliceEnc))
+		for i, k := range d.sliceEnc {
+			d.sliceDec[len(d.sliceEnc)-1-i] = k
+		}
+	}
+	if encrypt {
+		return d.sliceEnc, nil
+	}
+	return d.sliceDec, nil
+}
+
+// EncryptBatch шифрует len(src)/8 независимых блоков битовыми срезами,
+// по BatchSize блоков за проход; dst и src могут совпадать
+func (d *DESCipher) EncryptBatch(dst, src []byte) error {
+	keys, err := d.batchKeys(true)
+	if err != nil {
+		return err
+	}
+	return cryptBatch(dst, src, keys)
+}
+
+func (d *DESCipher) DecryptBatch(dst, src []byte) error {
+	keys, err := d.batchKeys(false)
+	if err != nil {
+		return err
+	}
+	return cryptBatch(dst, src, keys)
+}
+
 //	IP E IP⁻¹
 func (d *DESCipher) Encrypt(block []byte) ([]byte, error) {
 	if d.roundKeys == nil {
@@ -54,7 +161,7 @@ func (d *DESCipher) Encrypt(block []byte) ([]byte, error) {
 	}
 
 	// IP
-	permuted, err := permute.Permute(block, DES.IP(), true, true)
+	permuted, err := ipTable.Permute(block)
 	if err != nil {
 		return nil, fmt.Errorf("DES IP failed: %w", err)
 	}
@@ -66,7 +173,7 @@ func (d *DESCipher) Encrypt(block []byte) ([]byte, error) {
 	}
 
 	// IP⁻¹
-	finalResult, err := permute.Permute(result, DES.IP_INV(), true, true)
+	finalResult, err := ipInvTable.Permute(result)
 	if err != nil {
 		return nil, fmt.Errorf("DES IP_INV failed: %w", err)
 	}
@@ -85,7 +192,7 @@ func (d *DESCipher) Decrypt(block []byte) ([]byte, error) {
 	}
 
 	//IP
-	permuted, err := permute.Permute(block, DES.IP(), true, true)
+	permuted, err := ipTable.Permute(block)
 	if err != nil {
 		return nil, fmt.Errorf("DES IP failed: %w", err)
 	}
@@ -96,10 +203,48 @@ func (d *DESCipher) Decrypt(block []byte) ([]byte, error) {
 	}
 
 	// IP^-1
-	finalResult, err := permute.Permute(result, DES.IP_INV(), true, true)
+	finalResult, err := ipInvTable.Permute(result)
 	if err != nil {
 		return nil, fmt.Errorf("DES IP_INV failed: %w", err)
 	}
 
 	return finalResult, nil
 }
+
+// EncryptInto - то же, что Encrypt, но на машинных словах и без аллокаций
+func (d *DESCipher) EncryptInto(dst, src []byte) error {
+	return d.cryptInto(dst, src, false)
+}
+
+func (d *DESCipher) DecryptInto(dst, src []byte) error {
+	return d.cryptInto(dst, src, true)
+}
+
+func (d *DESCipher) cryptInto(dst, src []byte, decrypt bool) error {
+	if d.roundKeys == nil {
+		return fmt.Errorf("DES: round keys not set, call SetSymmetricKey first")
+	}
+	if len(src) != 8 {
+		return fmt.Errorf("DES: block must be 8 bytes, got %d", len(src))
+	}
+	if len(dst) < 8 {
+		return fmt.Errorf("DES: output buffer must be at least 8 bytes, got %d", len(dst))
+	}
+
+	x := ipTable.Uint64(src)
+	l, r := uint32(x>>32), uint32(x)
+	n := len(d.keys48)
+	for i := 0; i < n; i++ {
+		k := d.keys48[i]
+		if decrypt {
+			k = d.keys48[n-1-i]
+		}
+		l, r = r, l^roundF(r, k)
+	}
+
+	// финальная перестановка половин: R || L
+	var pre [8]byte
+	binary.BigEndian.PutUint64(pre[:], uint64(r)<<32|uint64(l))
+	binary.BigEndian.PutUint64(dst, ipInvTable.Uint64(pre[:]))
+	return nil
+}
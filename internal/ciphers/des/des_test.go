@@ -338,3 +338,123 @@ func TestSplitMergeCD(t *testing.T) {
 		})
 	}
 }
+
+// bitwiseTransform - прежняя раундовая функция через permute.Permute,
+// для сравнения со скомпилированными таблицами
+func bitwiseTransform(inputBlock, roundKey []byte) ([]byte, error) {
+	expanded, err := permute.Permute(inputBlock, DES.E(), true, true)
+	if err != nil {
+		return nil, err
+	}
+	var bits48 uint64
+	for i := range expanded {
+		bits48 = bits48<<8 | uint64(expanded[i]^roundKey[i])
+	}
+	var output uint32
+	for i := 0; i < 8; i++ {
+		bits6 := (bits48 >> uint(42-i*6)) & 0x3F
+		row := ((bits6 & 0x20) >> 4) | (bits6 & 0x01)
+		output = output<<4 | uint32(DES.S()[i][row][(bits6>>1)&0x0F])
+	}
+	sbox := []byte{byte(output >> 24), byte(output >> 16), byte(output >> 8), byte(output)}
+	return permute.Permute(sbox, DES.P(), true, true)
+}
+
+func TestRoundFunctionMatchesBitwise(t *testing.T) {
+	rf := NewDESRoundFunction()
+	key := []byte{0x1b, 0x02, 0xef, 0xfc, 0x70, 0x72}
+	for _, in := range [][]byte{{0, 0, 0, 0}, {0xF0, 0xAA, 0xF0, 0xAA}, {0xFF, 0xFF, 0xFF, 0xFF}} {
+		got, err := rf.Transform(in, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := bitwiseTransform(in, key)
+		if string(got) != string(expected) {
+			t.Errorf("F(%x) = %x, expected %x", in, got, expected)
+		}
+	}
+}
+
+// EncryptInto совпадает с Encrypt (вектор FIPS 81), в том числе при dst == src
+// и уменьшенном числе раундов; DecryptInto обращает
+func TestEncryptIntoMatchesEncrypt(t *testing.T) {
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	block := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+	for _, rounds := range []int{Rounds, 4} {
+		des, err := NewDESWithRounds(rounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		des.SetSymmetricKey(key)
+		expected, _ := des.Encrypt(block)
+
+		buf := append([]byte(nil), block...)
+		if err := des.EncryptInto(buf, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Errorf("%d rounds: EncryptInto = %x, expected %x", rounds, buf, expected)
+		}
+		if err := des.DecryptInto(buf, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, block) {
+			t.Errorf("%d rounds: DecryptInto = %x, expected %x", rounds, buf, block)
+		}
+	}
+
+	des := NewDES()
+	des.SetSymmetricKey(key)
+	if err := des.EncryptInto(make([]byte, 4), block); err == nil {
+		t.Error("short dst accepted")
+	}
+	if allocs := testing.AllocsPerRun(100, func() { des.EncryptInto(block, block) }); allocs != 0 {
+		t.Errorf("EncryptInto: %.0f allocs", allocs)
+	}
+}
+
+// битовые срезы совпадают с DESCipher, в том числе для неполного прохода и
+// уменьшенного числа раундов
+func TestBatchMatchesEncrypt(t *testing.T) {
+	key := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	for _, rounds := range []int{Rounds, 5} {
+		des, _ := NewDESWithRounds(rounds)
+		if err := des.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+
+		src := make([]byte, 8*(BatchSize+3))
+		for i := range src {
+			src[i] = byte(i*37 + i>>3)
+		}
+		copy(src, []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF})
+
+		dst := make([]byte, len(src))
+		if err := des.EncryptBatch(dst, src); err != nil {
+			t.Fatal(err)
+		}
+		for off := 0; off < len(src); off += 8 {
+			expected, _ := des.Encrypt(src[off : off+8])
+			if !bytes.Equal(dst[off:off+8], expected) {
+				t.Fatalf("rounds %d, block %d: batch %x, expected %x", rounds, off/8, dst[off:off+8], expected)
+			}
+		}
+
+		back := make([]byte, len(src))
+		if err := des.DecryptBatch(back, dst); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(back, src) {
+			t.Errorf("rounds %d: DecryptBatch(EncryptBatch(x)) != x", rounds)
+		}
+	}
+
+	des := NewDES()
+	if err := des.EncryptBatch(make([]byte, 8), make([]byte, 8)); err == nil {
+		t.Error("EncryptBatch without key must fail")
+	}
+	des.SetSymmetricKey(key)
+	if err := des.EncryptBatch(make([]byte, 8), make([]byte, 7)); err == nil {
+		t.Error("partial block must fail")
+	}
+}
@@ -1,11 +1,17 @@
 package des
 
 import (
-	permute "crypto-lab/internal/ciphers/permute"
 	//"encoding/binary"
 	"fmt"
 )
 
+// DEAL переустанавливает ключ DES в каждом раунде, расписание ключей - горячий путь
+var (
+	pc1Table      = mustCompile(DES.PC1(), 8)
+	pc2Table      = mustCompile(DES.PC2(), 7)
+	shiftSchedule = DES.SHIFT_SCHEDULE()
+)
+
 type DESKeyExpansion struct{}
 
 func NewDESKeyExpansion() *DESKeyExpansion {
@@ -17,7 +23,7 @@ func (d *DESKeyExpansion) GenerateRoundKeys(key []byte) ([][]byte, error) {
 	}
 
 	// PC-1
-	cd7bytes, err := permute.Permute(key, DES.PC1(), true, true)
+	cd7bytes, err := pc1Table.Permute(key)
 	if err != nil {
 		return nil, fmt.Errorf("PC-1 permutation failed: %w", err)
 	}
@@ -32,7 +38,7 @@ func (d *DESKeyExpansion) GenerateRoundKeys(key []byte) ([][]byte, error) {
 	roundKeys := make([][]byte, 16)
 
 	for i := 0; i < 16; i++ {
-		shift := DES.SHIFT_SCHEDULE()[i]
+		shift := shiftSchedule[i]
 
 		C = RotateLeft(C, shift)
 		D = RotateLeft(D, shift)
@@ -41,7 +47,7 @@ func (d *DESKeyExpansion) GenerateRoundKeys(key []byte) ([][]byte, error) {
 		cd56bytes := MergeCD(C, D)
 
 		// PC-2
-		roundKeys[i], err = permute.Permute(cd56bytes, DES.PC2(), true, true)
+		roundKeys[i], err = pc2Table.Permute(cd56bytes)
 		if err != nil {
 			return nil, fmt.Errorf("PC2 permutation round %d failed: %w", i, err)
 		}
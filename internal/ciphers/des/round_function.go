@@ -1,9 +1,16 @@
 package des
 
 import (
+	"encoding/binary"
 	"fmt"
+)
 
-	permute "crypto-lab/internal/ciphers/permute"
+// E и P компилируются один раз, S-блоки тоже берутся один раз:
+// DES.S() строит таблицы заново при каждом вызове
+var (
+	eTable = mustCompile(DES.E(), 4)
+	pTable = mustCompile(DES.P(), 4)
+	sBoxes = DES.S()
 )
 
 type DESRoundFunction struct{}
@@ -13,41 +20,36 @@ func NewDESRoundFunction() *DESRoundFunction {
 }
 
 func (d *DESRoundFunction) Transform(inputBlock, roundKey []byte) ([]byte, error) {
-
-	expanded, err := permute.Permute(inputBlock, DES.E(), true, true)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(expanded) != len(roundKey) {
-		return nil, fmt.Errorf("expanded block size %d doesn't match round key size %d", len(expanded), len(roundKey))
+	if len(inputBlock) != eTable.InputSize() {
+		return nil, fmt.Errorf("input block must be %d bytes, got %d", eTable.InputSize(), len(inputBlock))
 	}
-
-	xored := make([]byte, len(expanded))
-	for i := range expanded {
-		xored[i] = expanded[i] ^ roundKey[i]
+	if len(roundKey) != eTable.OutputSize() {
+		return nil, fmt.Errorf("expanded block size %d doesn't match round key size %d", eTable.OutputSize(), len(roundKey))
 	}
 
-	sboxResult := d.applySBoxes(xored)
-
-	result, err := permute.Permute(sboxResult, DES.P(), true, true)
-	if err != nil {
-		return nil, err
+	var key uint64
+	for _, b := range roundKey {
+		key = key<<8 | uint64(b)
 	}
 
+	result := make([]byte, 4)
+	binary.BigEndian.PutUint32(result, roundF(binary.BigEndian.Uint32(inputBlock), key))
 	return result, nil
 }
 
-func (d *DESRoundFunction) applySBoxes(input []byte) []byte {
-	if len(input) != 6 {
-		panic("S-box input must be 6 bytes")
-	}
+// roundF - P(S(E(r) xor key)), key - 48-битный раундовый ключ
+func roundF(r uint32, key uint64) uint32 {
+	var in [4]byte
+	binary.BigEndian.PutUint32(in[:], r)
+	// E(R) - старшие 48 бит слова
+	bits48 := eTable.Uint64(in[:])>>16 ^ key
 
-	var bits48 uint64
-	for i := 0; i < 6; i++ {
-		bits48 = (bits48 << 8) | uint64(input[i])
-	}
+	var sboxResult [4]byte
+	applySBoxes(bits48, &sboxResult)
+	return uint32(pTable.Uint64(sboxResult[:]) >> 32)
+}
 
+func applySBoxes(bits48 uint64, out *[4]byte) {
 	var output uint32
 
 	for i := 0; i < 8; i++ {
@@ -58,18 +60,12 @@ func (d *DESRoundFunction) applySBoxes(input []byte) []byte {
 		row := ((bits6 & 0x20) >> 4) | (bits6 & 0x01)
 		column := (bits6 >> 1) & 0x0F
 
-		sboxValue := DES.S()[i][row][column]
+		sboxValue := sBoxes[i][row][column]
 
 		output = (output << 4) | uint32(sboxValue)
 	}
 
-	result := make([]byte, 4)
-	result[0] = byte((output >> 24) & 0xFF)
-	result[1] = byte((output >> 16) & 0xFF)
-	result[2] = byte((output >> 8) & 0xFF)
-	result[3] = byte(output & 0xFF)
-
-	return result
+	binary.BigEndian.PutUint32(out[:], output)
 }
 
 func (d *DESRoundFunction) GetInputBlockSize() int  { return 4 }
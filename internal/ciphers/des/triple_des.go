@@ -0,0 +1,114 @@
+package des
+
+import (
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// TripleDESCipher - 3DES в режиме EDE: C = E(K3, D(K2, E(K1, P)))
+// ключ 16 байт (EDE2, K3 = K1) или 24 байта (EDE3)
+type TripleDESCipher struct {
+	des1    *DESCipher
+	des2    *DESCipher
+	des3    *DESCipher
+	keySize int
+}
+
+func NewTripleDES() *TripleDESCipher {
+	return &TripleDESCipher{
+		des1: NewDES(),
+		des2: NewDES(),
+		des3: NewDES(),
+	}
+}
+
+func (t *TripleDESCipher) SetSymmetricKey(key []byte) error {
+	if len(key) != 16 && len(key) != 24 {
+		return fmt.Errorf("3DES key must be 16 or 24 bytes, got %d", len(key))
+	}
+
+	k1 := key[0:8]
+	k2 := key[8:16]
+	k3 := k1 // EDE2
+	if len(key) == 24 {
+		k3 = key[16:24]
+	}
+
+	if err := t.des1.SetSymmetricKey(k1); err != nil {
+		return fmt.Errorf("3DES K1: %w", err)
+	}
+	if err := t.des2.SetSymmetricKey(k2); err != nil {
+		return fmt.Errorf("3DES K2: %w", err)
+	}
+	if err := t.des3.SetSymmetricKey(k3); err != nil {
+		return fmt.Errorf("3DES K3: %w", err)
+	}
+
+	t.keySize = len(key)
+	return nil
+}
+
+// E(K1) -> D(K2) -> E(K3)
+func (t *TripleDESCipher) Encrypt(block []byte) ([]byte, error) {
+	if t.keySize == 0 {
+		return nil, fmt.Errorf("3DES: key not set, call SetSymmetricKey first")
+	}
+	if len(block) != 8 {
+		return nil, fmt.Errorf("3DES: block must be 8 bytes, got %d", len(block))
+	}
+
+	step1, err := t.des1.Encrypt(block)
+	if err != nil {
+		return nil, fmt.Errorf("3DES encrypt K1: %w", err)
+	}
+	step2, err := t.des2.Decrypt(step1)
+	if err != nil {
+		return nil, fmt.Errorf("3DES decrypt K2: %w", err)
+	}
+	step3, err := t.des3.Encrypt(step2)
+	if err != nil {
+		return nil, fmt.Errorf("3DES encrypt K3: %w", err)
+	}
+
+	return step3, nil
+}
+
+// D(K3) -> E(K2) -> D(K1)
+func (t *TripleDESCipher) Decrypt(block []byte) ([]byte, error) {
+	if t.keySize == 0 {
+		return nil, fmt.Errorf("3DES: key not set, call SetSymmetricKey first")
+	}
+	if len(block) != 8 {
+		return nil, fmt.Errorf("3DES: block must be 8 bytes, got %d", len(block))
+	}
+
+	step1, err := t.des3.Decrypt(block)
+	if err != nil {
+		return nil, fmt.Errorf("3DES decrypt K3: %w", err)
+	}
+	step2, err := t.des2.Encrypt(step1)
+	if err != nil {
+		return nil, fmt.Errorf("3DES encrypt K2: %w", err)
+	}
+	step3, err := t.des1.Decrypt(step2)
+	if err != nil {
+		return nil, fmt.Errorf("3DES decrypt K1: %w", err)
+	}
+
+	return step3, nil
+}
+
+func (t *TripleDESCipher) GetBlockSize() int { return 8 }
+
+func (t *TripleDESCipher) GetCipherID() ciphers.CipherID { return ciphers.CipherTripleDES }
+
+// 16 или 24; 0 пока ключ не установлен
+func (t *TripleDESCipher) GetKeySize() int { return t.keySize }
+
+var _ ciphers.SymmetricCipher = (*TripleDESCipher)(nil)
+var _ ciphers.SymmetricCipher = (*DESCipher)(nil)
+var _ ciphers.CipherInfo = (*TripleDESCipher)(nil)
+var _ ciphers.CipherInfo = (*DESCipher)(nil)
+var _ ciphers.BatchCipher = (*DESCipher)(nil)
+var _ ciphers.BlockCipherInto = (*DESCipher)(nil)
@@ -0,0 +1,126 @@
+package des
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/modes"
+)
+
+// векторы из NIST SP 800-67 (пример TDEA ECB)
+func TestTripleDESKnownVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "EDE3",
+			key:        "0123456789ABCDEF23456789ABCDEF01456789ABCDEF0123",
+			plaintext:  "54686520717566636B2062726F776E20666F78206A756D70", // "The qufck brown fox jump"
+			ciphertext: "A826FD8CE53B855FCCE21C8112256FE668D5C05DD9B6B900",
+		},
+		{
+			name:       "EDE2",
+			key:        "0123456789ABCDEF23456789ABCDEF01",
+			plaintext:  "54686520717566636B2062726F776E20666F78206A756D70",
+			ciphertext: "C44862F70CF2FBDC9077D0909FA91B884CABD61FC58E0CBB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.key)
+			plaintext, _ := hex.DecodeString(tt.plaintext)
+			expected, _ := hex.DecodeString(tt.ciphertext)
+
+			tdes := NewTripleDES()
+			if err := tdes.SetSymmetricKey(key); err != nil {
+				t.Fatalf("SetSymmetricKey failed: %v", err)
+			}
+
+			for i := 0; i < len(plaintext); i += 8 {
+				ciphertext, err := tdes.Encrypt(plaintext[i : i+8])
+				if err != nil {
+					t.Fatalf("Encrypt failed: %v", err)
+				}
+				if !bytes.Equal(ciphertext, expected[i:i+8]) {
+					t.Errorf("block %d: expected %X, got %X", i/8, expected[i:i+8], ciphertext)
+				}
+
+				decrypted, err := tdes.Decrypt(ciphertext)
+				if err != nil {
+					t.Fatalf("Decrypt failed: %v", err)
+				}
+				if !bytes.Equal(decrypted, plaintext[i:i+8]) {
+					t.Errorf("block %d: decrypt mismatch, got %X", i/8, decrypted)
+				}
+			}
+		})
+	}
+}
+
+// при K1=K2=K3 3DES вырождается в обычный DES
+func TestTripleDESDegeneratesToDES(t *testing.T) {
+	key, _ := hex.DecodeString("133457799BBCDFF1")
+	plaintext, _ := hex.DecodeString("0123456789ABCDEF")
+
+	tdes := NewTripleDES()
+	if err := tdes.SetSymmetricKey(bytes.Repeat(key, 3)); err != nil {
+		t.Fatalf("SetSymmetricKey failed: %v", err)
+	}
+
+	ciphertext, err := tdes.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	expected, _ := hex.DecodeString("85E813540F0AB405")
+	if !bytes.Equal(ciphertext, expected) {
+		t.Errorf("expected %X, got %X", expected, ciphertext)
+	}
+}
+
+func TestTripleDESInvalidKeySize(t *testing.T) {
+	tdes := NewTripleDES()
+
+	for _, size := range []int{0, 8, 15, 17, 23, 25, 32} {
+		if err := tdes.SetSymmetricKey(make([]byte, size)); err == nil {
+			t.Errorf("Expected error for key size %d, got nil", size)
+		}
+	}
+
+	if _, err := tdes.Encrypt(make([]byte, 8)); err == nil {
+		t.Errorf("Expected error when key is not set")
+	}
+}
+
+func TestTripleDESWithSymmetricContext(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789ABCDEF23456789ABCDEF01456789ABCDEF0123")
+	tdes := NewTripleDES()
+	if err := tdes.SetSymmetricKey(key); err != nil {
+		t.Fatalf("SetSymmetricKey failed: %v", err)
+	}
+
+	iv, _ := modes.GenerateRandomBytes(8)
+	ctx, err := modes.NewSymmetricContext(tdes, ciphers.CBC, ciphers.PKCS7, iv)
+	if err != nil {
+		t.Fatalf("NewSymmetricContext failed: %v", err)
+	}
+
+	message := []byte("legacy interop message for 3DES")
+	encrypted, err := ctx.Encrypt(message)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	decrypted, err := ctx.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, message) {
+		t.Errorf("round-trip failed: got %q", decrypted)
+	}
+}
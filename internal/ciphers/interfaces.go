@@ -21,6 +21,26 @@ type SymmetricCipher interface {
 	GetBlockSize() int //for des=8, deal=16
 }
 
+// 2.4 - необязательный: для самоописываемого заголовка зашифрованного файла
+type CipherInfo interface {
+	GetCipherID() CipherID
+	GetKeySize() int
+}
+
+// 2.5 - необязательный: много независимых блоков за вызов (ECB, CTR, RandomDelta),
+// len(src) кратна размеру блока, dst не короче src
+type BatchCipher interface {
+	EncryptBatch(dst, src []byte) error
+	DecryptBatch(dst, src []byte) error
+}
+
+// 2.6 - необязательный: результат пишется в dst (не короче блока) без аллокаций,
+// dst и src могут совпадать
+type BlockCipherInto interface {
+	EncryptInto(dst, src []byte) error
+	DecryptInto(dst, src []byte) error
+}
+
 type CipherModeStrategy interface {
     Process(data []byte, iv []byte, isEncrypt bool) ([]byte, error)
     NeedsIV() bool
@@ -36,10 +56,15 @@ const (
 	OFB
 	CTR
 	RandomDelta
+	GCM
+	CBCCS1 // CBC с кражей шифртекста, NIST SP 800-38A Addendum
+	CBCCS2
+	CBCCS3
+	XTS // IEEE 1619, IV = твик (номер сектора)
 )
 
 func (c CipherMode) String() string {
-	modes := []string{"ECB", "CBC", "PCBC", "CFB", "OFB", "CTR", "RandomDelta"}
+	modes := []string{"ECB", "CBC", "PCBC", "CFB", "OFB", "CTR", "RandomDelta", "GCM", "CBC-CS1", "CBC-CS2", "CBC-CS3", "XTS"}
 	if int(c) < 0 || int(c) >= len(modes) {
 		return "Unknown"
 	}
@@ -62,3 +87,21 @@ func (p PaddingMode) String() string {
 	}
 	return paddings[p]
 }
+
+type CipherID byte
+
+const (
+	CipherUnknown CipherID = iota //0
+	CipherDES
+	CipherTripleDES
+	CipherDEAL
+	CipherRijndael
+)
+
+func (c CipherID) String() string {
+	names := []string{"Unknown", "DES", "3DES", "DEAL", "Rijndael"}
+	if int(c) >= len(names) {
+		return "Unknown"
+	}
+	return names[c]
+}
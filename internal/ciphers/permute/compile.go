@@ -0,0 +1,113 @@
+package permute
+
+import "fmt"
+
+// Table - скомпилированная перестановка: для каждого входного байта и каждого его
+// значения заранее посчитан вклад в выход (OR по всем байтам входа).
+// Выход хранится словами uint64, байты выхода - big-endian внутри слова.
+type Table struct {
+	inputBytes  int
+	outputBytes int
+	words       int      // слов uint64 на одну запись
+	lut         []uint64 // [inputBytes][256][words]
+}
+
+// Compile строит таблицу для входа ровно inputBytes байт; msbFirst и oneIndexed
+// значат то же, что и в Permute
+func Compile(pBlock []int, inputBytes int, msbFirst bool, oneIndexed bool) (*Table, error) {
+	if len(pBlock) == 0 {
+		return nil, fmt.Errorf("pBlock is empty")
+	}
+	if inputBytes <= 0 {
+		return nil, fmt.Errorf("input size must be positive, got %d", inputBytes)
+	}
+
+	totalBits := inputBytes * 8
+	t := &Table{
+		inputBytes:  inputBytes,
+		outputBytes: (len(pBlock) + 7) / 8,
+	}
+	t.words = (t.outputBytes + 7) / 8
+	t.lut = make([]uint64, inputBytes*256*t.words)
+
+	for outBit, inBit := range pBlock {
+		if oneIndexed {
+			inBit--
+		}
+		if inBit < 0 || inBit >= totalBits {
+			return nil, fmt.Errorf("illegal bit position: %d out of range [0, %d]", inBit, totalBits-1)
+		}
+
+		// бит outBit выхода: байт outBit/8, позиция внутри байта как в setBit
+		outPhys := outBit % 8
+		if msbFirst {
+			outPhys = 7 - outPhys
+		}
+		outByte := outBit / 8
+		word := outByte / 8
+		mask := uint64(1) << uint(8*(7-outByte%8)+outPhys)
+
+		inPhys := inBit % 8
+		if msbFirst {
+			inPhys = 7 - inPhys
+		}
+		byteIdx := inBit / 8
+		for v := 0; v < 256; v++ {
+			if (v>>uint(inPhys))&1 != 0 {
+				t.lut[(byteIdx*256+v)*t.words+word] |= mask
+			}
+		}
+	}
+	return t, nil
+}
+
+func (t *Table) InputSize() int  { return t.inputBytes }
+func (t *Table) OutputSize() int { return t.outputBytes }
+
+// Permute - то же, что Permute(input, pBlock, ...), но без перебора битов
+func (t *Table) Permute(input []byte) ([]byte, error) {
+	output := make([]byte, t.outputBytes)
+	if err := t.PermuteInto(output, input); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// PermuteInto пишет результат в dst длиной не меньше OutputSize, без аллокаций
+func (t *Table) PermuteInto(dst, input []byte) error {
+	if len(input) != t.inputBytes {
+		return fmt.Errorf("input must be %d bytes, got %d", t.inputBytes, len(input))
+	}
+	if len(dst) < t.outputBytes {
+		return fmt.Errorf("output buffer must be at least %d bytes, got %d", t.outputBytes, len(dst))
+	}
+
+	if t.words == 1 {
+		out := t.Uint64(input)
+		for i := 0; i < t.outputBytes; i++ {
+			dst[i] = byte(out >> uint(56-8*i))
+		}
+		return nil
+	}
+
+	for w := 0; w < t.words; w++ {
+		var out uint64
+		for i, b := range input {
+			out |= t.lut[(i*256+int(b))*t.words+w]
+		}
+		for i := 8 * w; i < t.outputBytes && i < 8*w+8; i++ {
+			dst[i] = byte(out >> uint(56-8*(i-8*w)))
+		}
+	}
+	return nil
+}
+
+// Uint64 - выход до 8 байт одним словом, выровненным по старшим битам;
+// вход должен быть ровно InputSize байт
+func (t *Table) Uint64(input []byte) uint64 {
+	var out uint64
+	for i, b := range input[:t.inputBytes] {
+		out |= t.lut[(i*256+int(b))*t.words]
+	}
+	return out
+}
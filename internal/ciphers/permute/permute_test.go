@@ -95,3 +95,87 @@ func TestPermuteAllZeros(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, result)
 	}
 }
+
+// скомпилированная таблица совпадает с Permute при всех комбинациях флагов
+func TestCompileMatchesPermute(t *testing.T) {
+	// 64 -> 48 и 16 -> 96 бит (выход больше одного слова), с повторами
+	pBlocks := [][]int{
+		{32, 1, 2, 3, 4, 5, 4, 5, 6, 7, 8, 9, 8, 9, 10, 11, 12, 13, 12, 13, 14, 15, 16, 17,
+			16, 17, 18, 19, 20, 21, 20, 21, 22, 23, 24, 25, 24, 25, 26, 27, 28, 29, 28, 29, 30, 31, 32, 1},
+		make([]int, 96),
+	}
+	for i := range pBlocks[1] {
+		pBlocks[1][i] = (i*7)%16 + 1
+	}
+	inputs := [][]byte{
+		{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF},
+		{0xA5, 0x3C},
+	}
+
+	for n, pBlock := range pBlocks {
+		for _, msbFirst := range []bool{true, false} {
+			for _, oneIndexed := range []bool{true, false} {
+				block := pBlock
+				if !oneIndexed {
+					block = make([]int, len(pBlock))
+					for i, p := range pBlock {
+						block[i] = p - 1
+					}
+				}
+				table, err := Compile(block, len(inputs[n]), msbFirst, oneIndexed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, _ := Permute(inputs[n], block, msbFirst, oneIndexed)
+				got, err := table.Permute(inputs[n])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, expected) {
+					t.Errorf("pBlock %d, msbFirst=%v, oneIndexed=%v: got %x, expected %x",
+						n, msbFirst, oneIndexed, got, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	if _, err := Compile(nil, 1, true, true); err == nil {
+		t.Error("empty pBlock must fail")
+	}
+	if _, err := Compile([]int{9}, 1, true, true); err == nil {
+		t.Error("bit out of range must fail")
+	}
+	table, _ := Compile([]int{1, 2, 3, 4, 5, 6, 7, 8}, 1, true, true)
+	if _, err := table.Permute([]byte{1, 2}); err == nil {
+		t.Error("wrong input size must fail")
+	}
+	if err := table.PermuteInto(nil, []byte{1}); err == nil {
+		t.Error("short output buffer must fail")
+	}
+}
+
+func BenchmarkPermute(b *testing.B) {
+	ip := []int{58, 50, 42, 34, 26, 18, 10, 2, 60, 52, 44, 36, 28, 20, 12, 4,
+		62, 54, 46, 38, 30, 22, 14, 6, 64, 56, 48, 40, 32, 24, 16, 8,
+		57, 49, 41, 33, 25, 17, 9, 1, 59, 51, 43, 35, 27, 19, 11, 3,
+		61, 53, 45, 37, 29, 21, 13, 5, 63, 55, 47, 39, 31, 23, 15, 7}
+	input := []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+
+	b.Run("bitwise", func(b *testing.B) {
+		b.SetBytes(8)
+		for i := 0; i < b.N; i++ {
+			Permute(input, ip, true, true)
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		table, _ := Compile(ip, 8, true, true)
+		out := make([]byte, 8)
+		b.SetBytes(8)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			table.PermuteInto(out, input)
+		}
+	})
+}
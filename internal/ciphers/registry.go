@@ -0,0 +1,196 @@
+// Реестр шифров по имени: пакеты des, deal, rijndael регистрируют себя в init,
+// поэтому для Lookup пакет шифра должен быть импортирован (хотя бы как _)
+
+package ciphers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownCipher = errors.New("ciphers: unknown cipher")
+
+// Algorithm - запись реестра; размеры ключа и блока в байтах
+type Algorithm struct {
+	Name      string
+	ID        CipherID
+	KeySizes  []int // первый - размер по умолчанию
+	BlockSize int
+	New       func(keySize int) (SymmetricCipher, error) // шифр без ключа
+}
+
+// Names - имена с размером ключа в битах: DEAL-128, DEAL-192, DEAL-256;
+// при единственном размере ключа - просто Name
+func (a Algorithm) Names() []string {
+	if len(a.KeySizes) == 1 {
+		return []string{a.Name}
+	}
+	names := make([]string, len(a.KeySizes))
+	for i, size := range a.KeySizes {
+		names[i] = fmt.Sprintf("%s-%d", a.Name, size*8)
+	}
+	return names
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Algorithm{}
+)
+
+// Register вызывается из init пакета шифра; повтор имени - паника, как в database/sql
+func Register(a Algorithm) {
+	if a.Name == "" || a.New == nil || len(a.KeySizes) == 0 || a.BlockSize <= 0 {
+		panic(fmt.Sprintf("ciphers: invalid registration of %q", a.Name))
+	}
+	key := strings.ToUpper(a.Name)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[key]; dup {
+		panic(fmt.Sprintf("ciphers: Register called twice for %q", a.Name))
+	}
+	a.KeySizes = slices.Clone(a.KeySizes)
+	registry[key] = a
+}
+
+// Algorithms - зарегистрированные шифры, по имени
+func Algorithms() []Algorithm {
+	registryMu.RLock()
+	list := make([]Algorithm, 0, len(registry))
+	for _, a := range registry {
+		list = append(list, a)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// LookupAlgorithm разбирает имя вида DEAL-192 (регистр не важен) и возвращает
+// запись и размер ключа в байтах; без суффикса - размер по умолчанию
+func LookupAlgorithm(name string) (Algorithm, int, error) {
+	a, keySize, _, err := lookupAlgorithm(name)
+	return a, keySize, err
+}
+
+// explicit - размер ключа задан суффиксом в имени
+func lookupAlgorithm(name string) (a Algorithm, keySize int, explicit bool, err error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	if a, ok := registry[upper]; ok {
+		return a, a.KeySizes[0], false, nil
+	}
+
+	// суффикс - размер ключа в битах: DEAL-192, AES-128
+	if i := strings.LastIndexByte(upper, '-'); i > 0 {
+		if bits, err := strconv.Atoi(upper[i+1:]); err == nil {
+			if a, ok := registry[upper[:i]]; ok {
+				if bits%8 == 0 && slices.Contains(a.KeySizes, bits/8) {
+					return a, bits / 8, true, nil
+				}
+				return Algorithm{}, 0, false, fmt.Errorf("%w: %s does not support %d-bit keys (supported: %s)",
+					ErrUnknownCipher, a.Name, bits, strings.Join(a.Names(), ", "))
+			}
+		}
+	}
+	return Algorithm{}, 0, false, fmt.Errorf("%w %q (registered: %s)", ErrUnknownCipher, name, strings.Join(namesLocked(), ", "))
+}
+
+// Lookup возвращает шифр с установленным ключом. Без суффикса в имени размер
+// ключа берется из len(key): Lookup("3DES", key16) - EDE2
+func Lookup(name string, key []byte) (SymmetricCipher, error) {
+	a, keySize, explicit, err := lookupAlgorithm(name)
+	if err != nil {
+		return nil, err
+	}
+	if !explicit && slices.Contains(a.KeySizes, len(key)) {
+		keySize = len(key)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key size for %s: expected %d bytes, got %d", name, keySize, len(key))
+	}
+
+	cipher, err := a.New(keySize)
+	if err != nil {
+		return nil, err
+	}
+	if err := cipher.SetSymmetricKey(key); err != nil {
+		return nil, fmt.Errorf("invalid key for %s: %w", name, err)
+	}
+	return cipher, nil
+}
+
+func namesLocked() []string {
+	var names []string
+	for _, a := range registry {
+		names = append(names, a.Names()...)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Spec - разобранная строка вида "DEAL-256/CBC/PKCS7"
+type Spec struct {
+	Cipher  string
+	Mode    CipherMode
+	Padding PaddingMode
+}
+
+// ParseSpec: шифр/режим[/паддинг], паддинг по умолчанию PKCS7
+// (GCM, CBC-CS и XTS его не используют); шифр в реестре не проверяется
+func ParseSpec(spec string) (Spec, error) {
+	parts := strings.Split(spec, "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Spec{}, fmt.Errorf("invalid cipher spec %q: expected cipher/mode[/padding]", spec)
+	}
+	s := Spec{Cipher: strings.TrimSpace(parts[0]), Padding: PKCS7}
+	if s.Cipher == "" {
+		return Spec{}, fmt.Errorf("invalid cipher spec %q: empty cipher name", spec)
+	}
+
+	var err error
+	if s.Mode, err = ParseCipherMode(parts[1]); err != nil {
+		return Spec{}, err
+	}
+	if len(parts) == 3 {
+		if s.Padding, err = ParsePaddingMode(parts[2]); err != nil {
+			return Spec{}, err
+		}
+	}
+	return s, nil
+}
+
+func (s Spec) String() string {
+	return s.Cipher + "/" + s.Mode.String() + "/" + s.Padding.String()
+}
+
+// ParseCipherMode - по имени из CipherMode.String(), регистр не важен
+func ParseCipherMode(name string) (CipherMode, error) {
+	name = strings.TrimSpace(name)
+	for m := ECB; m.String() != "Unknown"; m++ {
+		if strings.EqualFold(m.String(), name) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cipher mode %q", name)
+}
+
+// ParsePaddingMode - как PaddingMode.String() без учета регистра, пробелов, точек и дефисов
+func ParsePaddingMode(name string) (PaddingMode, error) {
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.NewReplacer(" ", "", ".", "", "-", "").Replace(s))
+	}
+	for p := Zeros; p.String() != "Unknown"; p++ {
+		if normalize(p.String()) == normalize(name) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown padding mode %q", name)
+}
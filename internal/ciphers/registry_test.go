@@ -0,0 +1,142 @@
+package ciphers_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	_ "crypto-lab/internal/ciphers/rijndael"
+)
+
+func TestLookupDEAL192(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 24)
+	cipher, err := ciphers.Lookup("DEAL-192", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, ok := cipher.(*deal.DEALCipher)
+	if !ok {
+		t.Fatalf("Lookup returned %T", cipher)
+	}
+	if d.GetKeySize() != 24 || d.GetBlockSize() != 16 {
+		t.Errorf("key %d, block %d", d.GetKeySize(), d.GetBlockSize())
+	}
+
+	// ключ уже установлен: тот же шифртекст, что у шифра из конструктора
+	expected, _ := deal.NewDEALCipher(24)
+	expected.SetSymmetricKey(key)
+	block := []byte("Hello world!!!!!")
+	got, err := cipher.Encrypt(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := expected.Encrypt(block)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encrypt = %x, expected %x", got, want)
+	}
+}
+
+func TestLookupNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		keySize int
+		id      ciphers.CipherID
+	}{
+		{"DES", 8, ciphers.CipherDES},
+		{"des-64", 8, ciphers.CipherDES},
+		{"3DES", 24, ciphers.CipherTripleDES},
+		{"3DES", 16, ciphers.CipherTripleDES}, // EDE2 по длине ключа
+		{"3DES-128", 16, ciphers.CipherTripleDES},
+		{"DEAL", 32, ciphers.CipherDEAL},
+		{"deal-128", 16, ciphers.CipherDEAL},
+		{"AES-256", 32, ciphers.CipherRijndael},
+	}
+	for _, tt := range tests {
+		cipher, err := ciphers.Lookup(tt.name, make([]byte, tt.keySize))
+		if err != nil {
+			t.Errorf("%s/%d: %v", tt.name, tt.keySize, err)
+			continue
+		}
+		info := cipher.(ciphers.CipherInfo)
+		if info.GetCipherID() != tt.id || info.GetKeySize() != tt.keySize {
+			t.Errorf("%s: got %v with %d-byte key", tt.name, info.GetCipherID(), info.GetKeySize())
+		}
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	for _, name := range []string{"Blowfish", "DEAL-64", "AES-100", ""} {
+		if _, err := ciphers.Lookup(name, make([]byte, 16)); !errors.Is(err, ciphers.ErrUnknownCipher) {
+			t.Errorf("%q: expected ErrUnknownCipher, got %v", name, err)
+		}
+	}
+	if _, err := ciphers.Lookup("DEAL-192", make([]byte, 16)); err == nil {
+		t.Error("16-byte key for DEAL-192 accepted")
+	}
+	if _, err := ciphers.Lookup("DES", make([]byte, 5)); err == nil {
+		t.Error("5-byte DES key accepted")
+	}
+}
+
+func TestAlgorithms(t *testing.T) {
+	var names []string
+	for _, a := range ciphers.Algorithms() {
+		names = append(names, a.Names()...)
+	}
+	expected := []string{"3DES-192", "3DES-128", "AES-128", "AES-192", "AES-256", "DEAL-128", "DEAL-192", "DEAL-256", "DES"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("names = %v, expected %v", names, expected)
+	}
+
+	a, keySize, err := ciphers.LookupAlgorithm("DEAL-256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.BlockSize != 16 || keySize != 32 || a.ID != ciphers.CipherDEAL {
+		t.Errorf("DEAL-256: %+v, key %d", a, keySize)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate registration accepted")
+		}
+	}()
+	ciphers.Register(ciphers.Algorithm{
+		Name: "des", KeySizes: []int{8}, BlockSize: 8,
+		New: func(int) (ciphers.SymmetricCipher, error) { return des.NewDES(), nil },
+	})
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected ciphers.Spec
+	}{
+		{"DEAL-256/CBC/PKCS7", ciphers.Spec{Cipher: "DEAL-256", Mode: ciphers.CBC, Padding: ciphers.PKCS7}},
+		{"des/ecb/ansi-x923", ciphers.Spec{Cipher: "des", Mode: ciphers.ECB, Padding: ciphers.ANSIX923}},
+		{"AES-128/GCM", ciphers.Spec{Cipher: "AES-128", Mode: ciphers.GCM, Padding: ciphers.PKCS7}},
+		{"DEAL-128/cbc-cs3/Zeros", ciphers.Spec{Cipher: "DEAL-128", Mode: ciphers.CBCCS3, Padding: ciphers.Zeros}},
+	}
+	for _, tt := range tests {
+		got, err := ciphers.ParseSpec(tt.spec)
+		if err != nil {
+			t.Errorf("%s: %v", tt.spec, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%s: %+v, expected %+v", tt.spec, got, tt.expected)
+		}
+	}
+
+	for _, spec := range []string{"DEAL-256", "DEAL-256/XYZ/PKCS7", "DEAL-256/CBC/XYZ", "/CBC", "a/b/c/d"} {
+		if _, err := ciphers.ParseSpec(spec); err == nil {
+			t.Errorf("%q accepted", spec)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package rijndael
+
+import (
+	"math/bits"
+
+	"crypto-lab/internal/math/gf256"
+)
+
+// модуль AES: x^8 + x^4 + x^3 + x + 1
+const aesModulus = 0x1B
+
+var sBox, invSBox = mustGenerateSBoxes(aesModulus)
+
+// S-box: мультипликативная инверсия в GF(2^8) по модулю + аффинное преобразование
+func generateSBoxes(modulus byte) (box, inv [256]byte, err error) {
+	for x := 0; x < 256; x++ {
+		var b byte
+		if x != 0 {
+			b, err = gf256.Inverse(byte(x), modulus)
+			if err != nil {
+				return box, inv, err
+			}
+		}
+		// b ^ rotl(b,1) ^ rotl(b,2) ^ rotl(b,3) ^ rotl(b,4) ^ 0x63
+		s := b ^ bits.RotateLeft8(b, 1) ^ bits.RotateLeft8(b, 2) ^ bits.RotateLeft8(b, 3) ^ bits.RotateLeft8(b, 4) ^ 0x63
+		box[x] = s
+		inv[s] = byte(x)
+	}
+	return box, inv, nil
+}
+
+func mustGenerateSBoxes(modulus byte) (box, inv [256]byte) {
+	box, inv, err := generateSBoxes(modulus)
+	if err != nil {
+		panic(err)
+	}
+	return box, inv
+}
+
+// смещения ShiftRows для строк 1..3 в зависимости от Nb (число столбцов состояния)
+func shiftOffsets(nb int) [4]int {
+	if nb == 8 {
+		return [4]int{0, 1, 3, 4}
+	}
+	return [4]int{0, 1, 2, 3}
+}
+
+// Nr = max(Nk, Nb) + 6
+func roundCount(keySize, blockSize int) int {
+	nk := keySize / 4
+	nb := blockSize / 4
+	if nk > nb {
+		return nk + 6
+	}
+	return nb + 6
+}
+
+// умножение на x в GF(2^8)
+func xtime(b byte) byte {
+	if b&0x80 != 0 {
+		return (b << 1) ^ 0x1b
+	}
+	return b << 1
+}
+
+// умножение в GF(2^8) по модулю 0x11B
+func gmul(a, b byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		a = xtime(a)
+		b >>= 1
+	}
+	return p
+}
@@ -0,0 +1,71 @@
+package rijndael
+
+import (
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+type RijndaelKeyExpansion struct {
+	keySize   int
+	blockSize int
+}
+
+func NewRijndaelKeyExpansion(keySize, blockSize int) (*RijndaelKeyExpansion, error) {
+	if !validSize(keySize) {
+		return nil, fmt.Errorf("invalid rijndael key size (%d)", keySize)
+	}
+	if !validSize(blockSize) {
+		return nil, fmt.Errorf("invalid rijndael block size (%d)", blockSize)
+	}
+	return &RijndaelKeyExpansion{keySize: keySize, blockSize: blockSize}, nil
+}
+
+// возвращает Nr+1 раундовых ключей по blockSize байт
+func (r *RijndaelKeyExpansion) GenerateRoundKeys(key []byte) ([][]byte, error) {
+	if len(key) != r.keySize {
+		return nil, fmt.Errorf("key size mismatch (%d), got %d", r.keySize, len(key))
+	}
+
+	nk := r.keySize / 4
+	nb := r.blockSize / 4
+	nr := roundCount(r.keySize, r.blockSize)
+	totalWords := nb * (nr + 1)
+
+	w := make([][4]byte, totalWords)
+	for i := 0; i < nk; i++ {
+		copy(w[i][:], key[4*i:4*i+4])
+	}
+
+	rcon := byte(0x01)
+	for i := nk; i < totalWords; i++ {
+		temp := w[i-1]
+		if i%nk == 0 {
+			// RotWord + SubWord + Rcon
+			temp = [4]byte{sBox[temp[1]], sBox[temp[2]], sBox[temp[3]], sBox[temp[0]]}
+			temp[0] ^= rcon
+			rcon = xtime(rcon)
+		} else if nk > 6 && i%nk == 4 {
+			temp = [4]byte{sBox[temp[0]], sBox[temp[1]], sBox[temp[2]], sBox[temp[3]]}
+		}
+		for j := 0; j < 4; j++ {
+			w[i][j] = w[i-nk][j] ^ temp[j]
+		}
+	}
+
+	roundKeys := make([][]byte, nr+1)
+	for round := 0; round <= nr; round++ {
+		roundKeys[round] = make([]byte, r.blockSize)
+		for c := 0; c < nb; c++ {
+			copy(roundKeys[round][4*c:], w[round*nb+c][:])
+		}
+	}
+
+	return roundKeys, nil
+}
+
+func validSize(size int) bool {
+	return size == 16 || size == 24 || size == 32
+}
+
+var _ ciphers.KeyExpansion = (*RijndaelKeyExpansion)(nil)
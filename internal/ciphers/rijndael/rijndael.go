@@ -0,0 +1,174 @@
+package rijndael
+
+import (
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// RijndaelCipher - полный Rijndael: ключ и блок 128/192/256 бит независимо.
+// AES = Rijndael с блоком 16 байт.
+type RijndaelCipher struct {
+	keySize      int
+	blockSize    int
+	rounds       int
+	keyExpansion ciphers.KeyExpansion
+	roundKeys    [][]byte
+}
+
+func NewRijndaelCipher(keySize, blockSize int) (*RijndaelCipher, error) {
+	keyExpansion, err := NewRijndaelKeyExpansion(keySize, blockSize)
+	if err != nil {
+		return nil, fmt.Errorf("error: NewRijndaelKeyExpansion: %w", err)
+	}
+
+	return &RijndaelCipher{
+		keySize:      keySize,
+		blockSize:    blockSize,
+		rounds:       roundCount(keySize, blockSize),
+		keyExpansion: keyExpansion,
+	}, nil
+}
+
+func NewAES(keySize int) (*RijndaelCipher, error) {
+	return NewRijndaelCipher(keySize, 16)
+}
+
+// в реестре - только AES; Rijndael с блоком 24 и 32 байта - через NewRijndaelCipher
+func init() {
+	ciphers.Register(ciphers.Algorithm{
+		Name: "AES", ID: ciphers.CipherRijndael, KeySizes: []int{16, 24, 32}, BlockSize: 16,
+		New: func(keySize int) (ciphers.SymmetricCipher, error) {
+			r, err := NewAES(keySize)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		},
+	})
+}
+
+func (r *RijndaelCipher) SetSymmetricKey(key []byte) error {
+	if len(key) != r.keySize {
+		return fmt.Errorf("error: key size mismatch: expected %d bytes, got %d", r.keySize, len(key))
+	}
+	roundKeys, err := r.keyExpansion.GenerateRoundKeys(key)
+	if err != nil {
+		return fmt.Errorf("error: key expansion: %w", err)
+	}
+	r.roundKeys = roundKeys
+	return nil
+}
+
+// AddRoundKey -> (SubBytes, ShiftRows, MixColumns, AddRoundKey) x (Nr-1) -> SubBytes, ShiftRows, AddRoundKey
+func (r *RijndaelCipher) Encrypt(block []byte) ([]byte, error) {
+	if r.roundKeys == nil {
+		return nil, fmt.Errorf("Rijndael: round keys not set, call SetSymmetricKey first")
+	}
+	if len(block) != r.blockSize {
+		return nil, fmt.Errorf("Rijndael: block must be %d bytes, got %d", r.blockSize, len(block))
+	}
+
+	state := make([]byte, r.blockSize)
+	copy(state, block)
+
+	addRoundKey(state, r.roundKeys[0])
+	for round := 1; round < r.rounds; round++ {
+		subBytes(state, &sBox)
+		r.shiftRows(state, false)
+		mixColumns(state)
+		addRoundKey(state, r.roundKeys[round])
+	}
+	subBytes(state, &sBox)
+	r.shiftRows(state, false)
+	addRoundKey(state, r.roundKeys[r.rounds])
+
+	return state, nil
+}
+
+func (r *RijndaelCipher) Decrypt(block []byte) ([]byte, error) {
+	if r.roundKeys == nil {
+		return nil, fmt.Errorf("Rijndael: round keys not set, call SetSymmetricKey first")
+	}
+	if len(block) != r.blockSize {
+		return nil, fmt.Errorf("Rijndael: block must be %d bytes, got %d", r.blockSize, len(block))
+	}
+
+	state := make([]byte, r.blockSize)
+	copy(state, block)
+
+	addRoundKey(state, r.roundKeys[r.rounds])
+	for round := r.rounds - 1; round > 0; round-- {
+		r.shiftRows(state, true)
+		subBytes(state, &invSBox)
+		addRoundKey(state, r.roundKeys[round])
+		invMixColumns(state)
+	}
+	r.shiftRows(state, true)
+	subBytes(state, &invSBox)
+	addRoundKey(state, r.roundKeys[0])
+
+	return state, nil
+}
+
+func (r *RijndaelCipher) GetBlockSize() int { return r.blockSize }
+
+func (r *RijndaelCipher) GetCipherID() ciphers.CipherID { return ciphers.CipherRijndael }
+func (r *RijndaelCipher) GetKeySize() int               { return r.keySize }
+
+// состояние хранится по столбцам: state[4*c+row]
+func (r *RijndaelCipher) shiftRows(state []byte, inverse bool) {
+	nb := r.blockSize / 4
+	offsets := shiftOffsets(nb)
+	row := make([]byte, nb)
+
+	for i := 1; i < 4; i++ {
+		for c := 0; c < nb; c++ {
+			row[c] = state[4*c+i]
+		}
+		for c := 0; c < nb; c++ {
+			src := (c + offsets[i]) % nb
+			if inverse {
+				src = (c - offsets[i] + nb) % nb
+			}
+			state[4*c+i] = row[src]
+		}
+	}
+}
+
+func subBytes(state []byte, box *[256]byte) {
+	for i := range state {
+		state[i] = box[state[i]]
+	}
+}
+
+func addRoundKey(state, roundKey []byte) {
+	for i := range state {
+		state[i] ^= roundKey[i]
+	}
+}
+
+// умножение столбца на {03}x^3 + {01}x^2 + {01}x + {02}
+func mixColumns(state []byte) {
+	for c := 0; c < len(state); c += 4 {
+		s0, s1, s2, s3 := state[c], state[c+1], state[c+2], state[c+3]
+		state[c] = gmul(s0, 2) ^ gmul(s1, 3) ^ s2 ^ s3
+		state[c+1] = s0 ^ gmul(s1, 2) ^ gmul(s2, 3) ^ s3
+		state[c+2] = s0 ^ s1 ^ gmul(s2, 2) ^ gmul(s3, 3)
+		state[c+3] = gmul(s0, 3) ^ s1 ^ s2 ^ gmul(s3, 2)
+	}
+}
+
+// умножение столбца на {0b}x^3 + {0d}x^2 + {09}x + {0e}
+func invMixColumns(state []byte) {
+	for c := 0; c < len(state); c += 4 {
+		s0, s1, s2, s3 := state[c], state[c+1], state[c+2], state[c+3]
+		state[c] = gmul(s0, 0x0e) ^ gmul(s1, 0x0b) ^ gmul(s2, 0x0d) ^ gmul(s3, 0x09)
+		state[c+1] = gmul(s0, 0x09) ^ gmul(s1, 0x0e) ^ gmul(s2, 0x0b) ^ gmul(s3, 0x0d)
+		state[c+2] = gmul(s0, 0x0d) ^ gmul(s1, 0x09) ^ gmul(s2, 0x0e) ^ gmul(s3, 0x0b)
+		state[c+3] = gmul(s0, 0x0b) ^ gmul(s1, 0x0d) ^ gmul(s2, 0x09) ^ gmul(s3, 0x0e)
+	}
+}
+
+var _ ciphers.SymmetricCipher = (*RijndaelCipher)(nil)
+var _ ciphers.CipherInfo = (*RijndaelCipher)(nil)
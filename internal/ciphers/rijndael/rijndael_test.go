@@ -0,0 +1,256 @@
+package rijndael
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/modes"
+)
+
+// FIPS-197, Appendix C
+func TestAESKnownVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "AES-128",
+			key:        "000102030405060708090a0b0c0d0e0f",
+			plaintext:  "00112233445566778899aabbccddeeff",
+			ciphertext: "69c4e0d86a7b0430d8cdb78070b4c55a",
+		},
+		{
+			name:       "AES-192",
+			key:        "000102030405060708090a0b0c0d0e0f1011121314151617",
+			plaintext:  "00112233445566778899aabbccddeeff",
+			ciphertext: "dda97ca4864cdfe06eaf70a0ec0d7191",
+		},
+		{
+			name:       "AES-256",
+			key:        "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			plaintext:  "00112233445566778899aabbccddeeff",
+			ciphertext: "8ea2b7ca516745bfeafc49904b496089",
+		},
+		{
+			// FIPS-197, Appendix B
+			name:       "AES-128 cipher example",
+			key:        "2b7e151628aed2a6abf7158809cf4f3c",
+			plaintext:  "3243f6a8885a308d313198a2e0370734",
+			ciphertext: "3925841d02dc09fbdc118597196a0b32",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.key)
+			plaintext, _ := hex.DecodeString(tt.plaintext)
+			expected, _ := hex.DecodeString(tt.ciphertext)
+
+			aes, err := NewAES(len(key))
+			if err != nil {
+				t.Fatalf("NewAES failed: %v", err)
+			}
+			if err := aes.SetSymmetricKey(key); err != nil {
+				t.Fatalf("SetSymmetricKey failed: %v", err)
+			}
+
+			ciphertext, err := aes.Encrypt(plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if !bytes.Equal(ciphertext, expected) {
+				t.Errorf("Expected %x, got %x", expected, ciphertext)
+			}
+
+			decrypted, err := aes.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("Decrypt mismatch: expected %x, got %x", plaintext, decrypted)
+			}
+		})
+	}
+}
+
+// FIPS-197, Appendix A.1: последний раундовый ключ (w[40..43])
+func TestKeyExpansionAES128(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+
+	keyExp, err := NewRijndaelKeyExpansion(16, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundKeys, err := keyExp.GenerateRoundKeys(key)
+	if err != nil {
+		t.Fatalf("GenerateRoundKeys failed: %v", err)
+	}
+
+	if len(roundKeys) != 11 {
+		t.Fatalf("Expected 11 round keys, got %d", len(roundKeys))
+	}
+
+	expected, _ := hex.DecodeString("d014f9a8c9ee2589e13f0cc8b6630ca6")
+	if !bytes.Equal(roundKeys[10], expected) {
+		t.Errorf("Expected last round key %x, got %x", expected, roundKeys[10])
+	}
+}
+
+// все 9 комбинаций размеров ключа и блока
+// блоки 192 и 256 бит (Nb = 6, 8; для Nb = 8 сдвиги ShiftRows 1, 3, 4):
+// вектора Гладмана к спецификации Rijndael - открытый текст и ключ из FIPS-197
+// Appendix B, продолженные до нужной длины, берутся первые Nb и Nk слов
+func TestRijndaelKnownVectors(t *testing.T) {
+	plaintext := "3243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c8"
+	key := "2b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfe"
+	tests := []struct {
+		blockSize, keySize int
+		ciphertext         string
+	}{
+		{24, 16, "b24d275489e82bb8f7375e0d5fcdb1f481757c538b65148a"},
+		{24, 24, "725ae43b5f3161de806a7c93e0bca93c967ec1ae1b71e1cf"},
+		{24, 32, "0ebacf199e3315c2e34b24fcc7c46ef4388aa475d66c194c"},
+		{32, 16, "7d15479076b69a46ffb3b3beae97ad8313f622f67fedb487de9f06b9ed9c8f19"},
+		{32, 24, "5d7101727bb25781bf6715b0e6955282b9610e23a43c2eb062699f0ebf5887b2"},
+		{32, 32, "a49406115dfb30a40418aafa4869b7c6a886ff31602a7dd19c889dc64f7e4e7a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("block%d-key%d", tt.blockSize*8, tt.keySize*8), func(t *testing.T) {
+			pt, _ := hex.DecodeString(plaintext[:tt.blockSize*2])
+			k, _ := hex.DecodeString(key[:tt.keySize*2])
+			expected, _ := hex.DecodeString(tt.ciphertext)
+
+			r, err := NewRijndaelCipher(tt.keySize, tt.blockSize)
+			if err != nil {
+				t.Fatalf("NewRijndaelCipher failed: %v", err)
+			}
+			if err := r.SetSymmetricKey(k); err != nil {
+				t.Fatalf("SetSymmetricKey failed: %v", err)
+			}
+
+			ciphertext, err := r.Encrypt(pt)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if !bytes.Equal(ciphertext, expected) {
+				t.Errorf("Expected %x, got %x", expected, ciphertext)
+			}
+
+			decrypted, err := r.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, pt) {
+				t.Errorf("Decrypt mismatch: expected %x, got %x", pt, decrypted)
+			}
+		})
+	}
+}
+
+func TestRijndaelRoundTrip(t *testing.T) {
+	sizes := []int{16, 24, 32}
+
+	for _, keySize := range sizes {
+		for _, blockSize := range sizes {
+			t.Run(fmt.Sprintf("key%d_block%d", keySize*8, blockSize*8), func(t *testing.T) {
+				r, err := NewRijndaelCipher(keySize, blockSize)
+				if err != nil {
+					t.Fatalf("NewRijndaelCipher failed: %v", err)
+				}
+
+				key := make([]byte, keySize)
+				rand.Read(key)
+				if err := r.SetSymmetricKey(key); err != nil {
+					t.Fatalf("SetSymmetricKey failed: %v", err)
+				}
+
+				plaintext := make([]byte, blockSize)
+				rand.Read(plaintext)
+
+				ciphertext, err := r.Encrypt(plaintext)
+				if err != nil {
+					t.Fatalf("Encrypt failed: %v", err)
+				}
+				if bytes.Equal(ciphertext, plaintext) {
+					t.Errorf("ciphertext equals plaintext")
+				}
+
+				decrypted, err := r.Decrypt(ciphertext)
+				if err != nil {
+					t.Fatalf("Decrypt failed: %v", err)
+				}
+				if !bytes.Equal(decrypted, plaintext) {
+					t.Errorf("round-trip failed\nexpected: %x\ngot: %x", plaintext, decrypted)
+				}
+			})
+		}
+	}
+}
+
+func TestRijndaelInvalidSizes(t *testing.T) {
+	if _, err := NewRijndaelCipher(20, 16); err == nil {
+		t.Errorf("Expected error for key size 20")
+	}
+	if _, err := NewRijndaelCipher(16, 8); err == nil {
+		t.Errorf("Expected error for block size 8")
+	}
+
+	r, _ := NewAES(16)
+	if _, err := r.Encrypt(make([]byte, 16)); err == nil {
+		t.Errorf("Expected error when key is not set")
+	}
+	if err := r.SetSymmetricKey(make([]byte, 24)); err == nil {
+		t.Errorf("Expected error for key size mismatch")
+	}
+	r.SetSymmetricKey(make([]byte, 16))
+	if _, err := r.Encrypt(make([]byte, 15)); err == nil {
+		t.Errorf("Expected error for block size 15")
+	}
+}
+
+func TestAESWithAllModes(t *testing.T) {
+	aes, _ := NewAES(32)
+	key := make([]byte, 32)
+	rand.Read(key)
+	if err := aes.SetSymmetricKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []byte("AES inside SymmetricContext, all seven modes")
+
+	modesList := []ciphers.CipherMode{
+		ciphers.ECB, ciphers.CBC, ciphers.PCBC,
+		ciphers.CFB, ciphers.OFB, ciphers.CTR, ciphers.RandomDelta,
+	}
+
+	for _, mode := range modesList {
+		t.Run(mode.String(), func(t *testing.T) {
+			iv, _ := modes.GenerateRandomBytes(16)
+			nonce, _ := modes.GenerateRandomBytes(8)
+
+			ctx, err := modes.NewSymmetricContext(aes, mode, ciphers.PKCS7, iv, nonce, int64(777))
+			if err != nil {
+				t.Fatalf("Failed to create context: %v", err)
+			}
+
+			encrypted, err := ctx.Encrypt(testData)
+			if err != nil {
+				t.Fatalf("Encryption error: %v", err)
+			}
+			decrypted, err := ctx.Decrypt(encrypted)
+			if err != nil {
+				t.Fatalf("Decryption error: %v", err)
+			}
+			if !bytes.Equal(testData, decrypted) {
+				t.Errorf("Mode %s: data mismatch", mode)
+			}
+		})
+	}
+}
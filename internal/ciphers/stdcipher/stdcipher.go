@@ -0,0 +1,151 @@
+// Адаптеры между ciphers.SymmetricCipher и crypto/cipher.Block: DES и DEAL
+// в режимах стандартной библиотеки (cipher.NewGCM, cipher.NewCBCEncrypter) и,
+// наоборот, шифры стандартной библиотеки в режимах internal/modes
+
+package stdcipher
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// Block - SymmetricCipher с уже установленным ключом как cipher.Block.
+// Как и в стандартной библиотеке, неполный блок и ошибка шифра - паника.
+type Block struct {
+	cipher    ciphers.SymmetricCipher
+	into      ciphers.BlockCipherInto // nil, если шифр не умеет писать в dst
+	blockSize int
+}
+
+func NewBlock(c ciphers.SymmetricCipher) *Block {
+	b := &Block{cipher: c, blockSize: c.GetBlockSize()}
+	b.into, _ = c.(ciphers.BlockCipherInto)
+	return b
+}
+
+func (b *Block) BlockSize() int { return b.blockSize }
+
+func (b *Block) Encrypt(dst, src []byte) { b.crypt(dst, src, false) }
+func (b *Block) Decrypt(dst, src []byte) { b.crypt(dst, src, true) }
+
+func (b *Block) crypt(dst, src []byte, decrypt bool) {
+	if len(src) < b.blockSize {
+		panic("stdcipher: input not full block")
+	}
+	if len(dst) < b.blockSize {
+		panic("stdcipher: output not full block")
+	}
+	src, dst = src[:b.blockSize], dst[:b.blockSize]
+
+	if b.into != nil {
+		var err error
+		if decrypt {
+			err = b.into.DecryptInto(dst, src)
+		} else {
+			err = b.into.EncryptInto(dst, src)
+		}
+		if err != nil {
+			panic("stdcipher: " + err.Error())
+		}
+		return
+	}
+
+	var out []byte
+	var err error
+	if decrypt {
+		out, err = b.cipher.Decrypt(src)
+	} else {
+		out, err = b.cipher.Encrypt(src)
+	}
+	if err != nil {
+		panic("stdcipher: " + err.Error())
+	}
+	copy(dst, out)
+}
+
+// Cipher - cipher.Block как SymmetricCipher; SetSymmetricKey создает блок
+// через newBlock (aes.NewCipher, des.NewCipher, des.NewTripleDESCipher)
+type Cipher struct {
+	newBlock  func(key []byte) (cipher.Block, error)
+	block     cipher.Block
+	blockSize int
+}
+
+func NewCipher(blockSize int, newBlock func(key []byte) (cipher.Block, error)) *Cipher {
+	return &Cipher{newBlock: newBlock, blockSize: blockSize}
+}
+
+// WrapBlock оборачивает блок с уже установленным ключом; SetSymmetricKey недоступен
+func WrapBlock(block cipher.Block) *Cipher {
+	return &Cipher{block: block, blockSize: block.BlockSize()}
+}
+
+func (c *Cipher) SetSymmetricKey(key []byte) error {
+	if c.newBlock == nil {
+		return fmt.Errorf("stdcipher: wrapped block has fixed key")
+	}
+	block, err := c.newBlock(key)
+	if err != nil {
+		return fmt.Errorf("stdcipher: %w", err)
+	}
+	if block.BlockSize() != c.blockSize {
+		return fmt.Errorf("stdcipher: block size %d, expected %d", block.BlockSize(), c.blockSize)
+	}
+	c.block = block
+	return nil
+}
+
+func (c *Cipher) Encrypt(block []byte) ([]byte, error) {
+	out := make([]byte, c.blockSize)
+	if err := c.EncryptInto(out, block); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Cipher) Decrypt(block []byte) ([]byte, error) {
+	out := make([]byte, c.blockSize)
+	if err := c.DecryptInto(out, block); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Cipher) EncryptInto(dst, src []byte) error {
+	if err := c.check(dst, src); err != nil {
+		return err
+	}
+	c.block.Encrypt(dst, src)
+	return nil
+}
+
+func (c *Cipher) DecryptInto(dst, src []byte) error {
+	if err := c.check(dst, src); err != nil {
+		return err
+	}
+	c.block.Decrypt(dst, src)
+	return nil
+}
+
+func (c *Cipher) check(dst, src []byte) error {
+	if c.block == nil {
+		return fmt.Errorf("stdcipher: key not set, call SetSymmetricKey first")
+	}
+	if len(src) != c.blockSize {
+		return fmt.Errorf("stdcipher: block must be %d bytes, got %d", c.blockSize, len(src))
+	}
+	if len(dst) < c.blockSize {
+		return fmt.Errorf("stdcipher: output buffer must be at least %d bytes, got %d", c.blockSize, len(dst))
+	}
+	return nil
+}
+
+func (c *Cipher) GetBlockSize() int { return c.blockSize }
+
+var (
+	_ cipher.Block            = (*Block)(nil)
+	_ ciphers.SymmetricCipher = (*Cipher)(nil)
+	_ ciphers.BlockCipherInto = (*Cipher)(nil)
+)
@@ -0,0 +1,123 @@
+package stdcipher
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	stddes "crypto/des"
+	"testing"
+
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/ciphers/rijndael"
+)
+
+// DES через Block совпадает с crypto/des (вектор FIPS 81);
+// Rijndael не умеет EncryptInto - проверяется путь через Encrypt
+func TestBlockMatchesStdlib(t *testing.T) {
+	desKey := []byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}
+	ours := des.NewDES()
+	ours.SetSymmetricKey(desKey)
+	std, _ := stddes.NewCipher(desKey)
+
+	aesKey := bytes.Repeat([]byte{0x2B}, 16)
+	rd, _ := rijndael.NewAES(16)
+	rd.SetSymmetricKey(aesKey)
+	stdAES, _ := aes.NewCipher(aesKey)
+
+	for name, pair := range map[string][2]cipher.Block{
+		"DES": {NewBlock(ours), std},
+		"AES": {NewBlock(rd), stdAES},
+	} {
+		got, expected := pair[0], pair[1]
+		src := make([]byte, got.BlockSize()+3) // хвост за блоком не трогается
+		for i := range src {
+			src[i] = byte(i * 17)
+		}
+		dst := make([]byte, len(src))
+		want := make([]byte, got.BlockSize())
+		got.Encrypt(dst, src)
+		expected.Encrypt(want, src)
+		if !bytes.Equal(dst[:got.BlockSize()], want) {
+			t.Errorf("%s: Encrypt = %x, expected %x", name, dst[:got.BlockSize()], want)
+		}
+		got.Decrypt(dst, dst)
+		if !bytes.Equal(dst[:got.BlockSize()], src[:got.BlockSize()]) {
+			t.Errorf("%s: Decrypt did not invert Encrypt", name)
+		}
+	}
+}
+
+func TestBlockPanics(t *testing.T) {
+	d, _ := deal.NewDEALCipher(16)
+	unkeyed := NewBlock(d)
+	for name, fn := range map[string]func(){
+		"short src": func() { unkeyed.Encrypt(make([]byte, 16), make([]byte, 8)) },
+		"short dst": func() { unkeyed.Encrypt(make([]byte, 8), make([]byte, 16)) },
+		"no key":    func() { unkeyed.Encrypt(make([]byte, 16), make([]byte, 16)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
+// AES стандартной библиотеки через Cipher совпадает с нашим Rijndael
+func TestCipherMatchesRijndael(t *testing.T) {
+	for _, keySize := range []int{16, 24, 32} {
+		key := bytes.Repeat([]byte{byte(keySize)}, keySize)
+		c := NewCipher(aes.BlockSize, aes.NewCipher)
+		if err := c.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+		rd, _ := rijndael.NewAES(keySize)
+		rd.SetSymmetricKey(key)
+
+		block := []byte("Hello world!!!!!")
+		got, err := c.Encrypt(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := rd.Encrypt(block)
+		if !bytes.Equal(got, expected) {
+			t.Errorf("AES-%d: %x, expected %x", keySize*8, got, expected)
+		}
+		decrypted, err := c.Decrypt(got)
+		if err != nil || !bytes.Equal(decrypted, block) {
+			t.Errorf("AES-%d: round trip failed: %v", keySize*8, err)
+		}
+	}
+}
+
+func TestCipherErrors(t *testing.T) {
+	c := NewCipher(aes.BlockSize, aes.NewCipher)
+	if _, err := c.Encrypt(make([]byte, 16)); err == nil {
+		t.Error("Encrypt without key accepted")
+	}
+	if err := c.SetSymmetricKey(make([]byte, 5)); err == nil {
+		t.Error("5-byte AES key accepted")
+	}
+	c.SetSymmetricKey(make([]byte, 16))
+	if _, err := c.Encrypt(make([]byte, 8)); err == nil {
+		t.Error("8-byte block accepted")
+	}
+
+	tdes := NewCipher(aes.BlockSize, stddes.NewTripleDESCipher)
+	if err := tdes.SetSymmetricKey(make([]byte, 24)); err == nil {
+		t.Error("block size mismatch accepted")
+	}
+
+	block, _ := aes.NewCipher(make([]byte, 16))
+	wrapped := WrapBlock(block)
+	if err := wrapped.SetSymmetricKey(make([]byte, 16)); err == nil {
+		t.Error("SetSymmetricKey on wrapped block accepted")
+	}
+	if _, err := wrapped.Encrypt(make([]byte, 16)); err != nil {
+		t.Error(err)
+	}
+}
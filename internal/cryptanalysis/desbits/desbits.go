@@ -0,0 +1,138 @@
+package desbits
+
+import (
+	"math/bits"
+
+	"crypto-lab/internal/ciphers/des"
+)
+
+// Раундовая функция DES на машинных словах для анализа: половина блока - uint32,
+// вход S-блоков и раундовый ключ - младшие 48 бит uint64. Биты нумеруются
+// как в стандарте: бит 1 - старший. Таблицы берутся из des.DES.
+
+const SBoxCount = 8
+
+var (
+	eTable  = des.DES.E()
+	pTable  = des.DES.P()
+	ipTable = des.DES.IP()
+	sBoxes  = des.DES.S()
+	pInv    [32]int
+	ipInv   [64]int
+)
+
+func init() {
+	for i, src := range pTable {
+		pInv[src-1] = i + 1
+	}
+	for i, src := range ipTable {
+		ipInv[src-1] = i + 1
+	}
+}
+
+// permute: бит i результата (из outBits) = бит table[i] входа (из inBits)
+func permute(x uint64, inBits int, table []int) uint64 {
+	var out uint64
+	for _, src := range table {
+		out = out<<1 | (x>>(inBits-src))&1
+	}
+	return out
+}
+
+// Expand - расширение E: 32 -> 48 бит
+func Expand(r uint32) uint64 {
+	return permute(uint64(r), 32, eTable)
+}
+
+// CompressMask - транспонированное E: маска a на E(r) как маска на r,
+// a·E(r) = CompressMask(a)·r (дублированные биты взаимно сокращаются)
+func CompressMask(a uint64) uint32 {
+	var m uint32
+	for j, src := range eTable {
+		if a>>(47-j)&1 != 0 {
+			m ^= 1 << (32 - src)
+		}
+	}
+	return m
+}
+
+// Parity - четность x·mask
+func Parity(x uint64) byte {
+	return byte(bits.OnesCount64(x) & 1)
+}
+
+func PermuteP(x uint32) uint32 {
+	return uint32(permute(uint64(x), 32, pTable))
+}
+
+func InversePermuteP(x uint32) uint32 {
+	return uint32(permute(uint64(x), 32, pInv[:]))
+}
+
+// SBox - значение S-блока i (0..7) на 6-битном входе x: строка - биты 1 и 6, столбец - 2..5
+func SBox(i int, x byte) byte {
+	row := (x>>4)&2 | x&1
+	col := (x >> 1) & 0x0F
+	return sBoxes[i][row][col]
+}
+
+// SBoxInput - 6 бит входа S-блока i из 48-битного значения
+func SBoxInput(x uint64, i int) byte {
+	return byte(x>>(42-6*i)) & 0x3F
+}
+
+// SBoxOutput - 4 бита выхода S-блока i из 32-битного значения до P
+func SBoxOutput(x uint32, i int) byte {
+	return byte(x>>(28-4*i)) & 0x0F
+}
+
+// F - раундовая функция: P(S(E(r) xor k))
+func F(r uint32, k uint64) uint32 {
+	x := Expand(r) ^ k
+	var s uint32
+	for i := 0; i < SBoxCount; i++ {
+		s = s<<4 | uint32(SBox(i, SBoxInput(x, i)))
+	}
+	return PermuteP(s)
+}
+
+// Split применяет IP к 8-байтному блоку и возвращает половины L, R
+func Split(block []byte) (l, r uint32) {
+	var x uint64
+	for _, b := range block {
+		x = x<<8 | uint64(b)
+	}
+	y := permute(x, 64, ipTable)
+	return uint32(y >> 32), uint32(y)
+}
+
+// Join - обратно к Split: собирает L, R и применяет IP^-1
+func Join(l, r uint32) []byte {
+	y := permute(uint64(l)<<32|uint64(r), 64, ipInv[:])
+	block := make([]byte, 8)
+	for i := range block {
+		block[i] = byte(y >> (56 - 8*i))
+	}
+	return block
+}
+
+// RoundKey - 6-байтный раундовый ключ как 48-битное число
+func RoundKey(k []byte) uint64 {
+	var x uint64
+	for _, b := range k {
+		x = x<<8 | uint64(b)
+	}
+	return x
+}
+
+// ActiveSBoxes - маска S-блоков (бит i - блок i) с ненулевой входной разностью от dr
+func ActiveSBoxes(dr uint32) uint8 {
+	e := Expand(dr)
+	var mask uint8
+	for i := 0; i < SBoxCount; i++ {
+		if SBoxInput(e, i) != 0 {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
@@ -0,0 +1,61 @@
+package desbits
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"crypto-lab/internal/ciphers/des"
+)
+
+// F на машинных словах совпадает с des.DESRoundFunction
+func TestFMatchesRoundFunction(t *testing.T) {
+	rf := des.NewDESRoundFunction()
+	key, _ := hex.DecodeString("1b02effc7072")
+	inputs := []uint32{0, 0xF0AAF0AA, 0x12345678, 0xFFFFFFFF}
+
+	for _, r := range inputs {
+		in := []byte{byte(r >> 24), byte(r >> 16), byte(r >> 8), byte(r)}
+		out, err := rf.Transform(in, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := uint32(out[0])<<24 | uint32(out[1])<<16 | uint32(out[2])<<8 | uint32(out[3])
+		if got := F(r, RoundKey(key)); got != expected {
+			t.Errorf("F(%08x) = %08x, expected %08x", r, got, expected)
+		}
+	}
+}
+
+func TestPermutationsAndSplit(t *testing.T) {
+	for _, x := range []uint32{1, 0x80000000, 0xDEADBEEF} {
+		if InversePermuteP(PermuteP(x)) != x {
+			t.Errorf("P^-1(P(%08x)) != %08x", x, x)
+		}
+	}
+
+	// FIPS 46: IP(0123456789ABCDEF) = CC00CCFF F0AAF0AA
+	block, _ := hex.DecodeString("0123456789ABCDEF")
+	l, r := Split(block)
+	if l != 0xCC00CCFF || r != 0xF0AAF0AA {
+		t.Errorf("IP: got %08x %08x", l, r)
+	}
+	if !bytes.Equal(Join(l, r), block) {
+		t.Errorf("Join(Split(x)) != x")
+	}
+
+	if ActiveSBoxes(0x60000000) != 1 || ActiveSBoxes(0) != 0 {
+		t.Errorf("0x60000000 must activate only S1")
+	}
+}
+
+// a·E(r) = CompressMask(a)·r
+func TestCompressMask(t *testing.T) {
+	for _, a := range []uint64{1, 0x800000000000, 0x3F0000000000, 0x123456789ABC} {
+		for _, r := range []uint32{0x12345678, 0xFFFFFFFF, 0x80000001} {
+			if Parity(Expand(r)&a) != Parity(uint64(CompressMask(a)&r)) {
+				t.Errorf("mask %012x, r %08x: parities differ", a, r)
+			}
+		}
+	}
+}
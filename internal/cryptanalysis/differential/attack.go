@@ -0,0 +1,148 @@
+package differential
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/cryptanalysis/desbits"
+)
+
+// Атака на последний раунд. После IP шифртекст = (R_r, L_r), вход F в раунде r - L_r,
+// выход F_r = R_r xor L_{r-1} = R_r xor R_{r-2}. Для m-раундовой характеристики:
+//
+//	r = m+2: dR_{r-2} = dR_m известна, выходные разности известны у всех S-блоков;
+//	r = m+3: dR_{r-2} = dL_m xor dF_{m+1}, у S-блоков, неактивных в раунде m+1,
+//	         выходная разность P^-1(dR_r xor dL_m) известна.
+//
+// Для каждой пары и каждого известного S-блока считаем 6-битные подключи k, при
+// которых S(x xor k) xor S(x' xor k) дает ожидаемую разность. Правильные пары
+// (вероятность характеристики) голосуют за верный подключ, остальные - вразброс.
+
+type Result struct {
+	Rounds   int
+	Subkeys  [desbits.SBoxCount]byte    // 6 бит подключа последнего раунда на S-блок
+	Mask     uint8                      // S-блоки, для которых подключ восстановлен
+	Counts   [desbits.SBoxCount][64]int // счетчики кандидатов
+	Pairs    int                        // всего пар
+	Filtered int                        // пар, прошедших фильтр
+}
+
+// Attack - атака с выбранными открытыми текстами на rounds раундов DES;
+// oracle - шифр с неизвестным ключом (например des.NewDESWithRounds(rounds)),
+// характеристика должна покрывать rounds-2 или rounds-3 раундов
+func Attack(oracle ciphers.SymmetricCipher, rounds int, c *Characteristic, pairs int) (*Result, error) {
+	if oracle.GetBlockSize() != 8 {
+		return nil, fmt.Errorf("differential: block size must be 8, got %d", oracle.GetBlockSize())
+	}
+	if pairs <= 0 {
+		return nil, fmt.Errorf("differential: pairs must be positive, got %d", pairs)
+	}
+	m := len(c.Rounds)
+	var known uint8
+	switch rounds - m {
+	case 2:
+		known = 0xFF
+	case 3:
+		known = c.NextInactive()
+	default:
+		return nil, fmt.Errorf("differential: %d-round characteristic cannot attack %d rounds", m, rounds)
+	}
+
+	ddts := DESDDTs()
+	res := &Result{Rounds: rounds, Pairs: pairs}
+	var buf [8]byte
+
+	for n := 0; n < pairs; n++ {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return nil, err
+		}
+		l0 := binary.BigEndian.Uint32(buf[:4])
+		r0 := binary.BigEndian.Uint32(buf[4:])
+
+		rr1, lr1, err := encryptHalves(oracle, l0, r0)
+		if err != nil {
+			return nil, err
+		}
+		rr2, lr2, err := encryptHalves(oracle, l0^c.InputL, r0^c.InputR)
+		if err != nil {
+			return nil, err
+		}
+
+		var sOut uint32
+		if rounds-m == 2 {
+			sOut = desbits.InversePermuteP(rr1 ^ rr2 ^ c.OutputR)
+		} else {
+			sOut = desbits.InversePermuteP(rr1 ^ rr2 ^ c.OutputL)
+		}
+		e1, e2 := desbits.Expand(lr1), desbits.Expand(lr2)
+
+		// фильтр: у известных S-блоков разность dx -> dy должна быть возможна
+		ok := true
+		for i := 0; i < desbits.SBoxCount && ok; i++ {
+			if known&(1<<i) == 0 {
+				continue
+			}
+			dx := desbits.SBoxInput(e1^e2, i)
+			ok = ddts[i][dx][desbits.SBoxOutput(sOut, i)] > 0
+		}
+		if !ok {
+			continue
+		}
+		res.Filtered++
+
+		for i := 0; i < desbits.SBoxCount; i++ {
+			x1, x2 := desbits.SBoxInput(e1, i), desbits.SBoxInput(e2, i)
+			if known&(1<<i) == 0 || x1 == x2 {
+				continue
+			}
+			dy := desbits.SBoxOutput(sOut, i)
+			for k := byte(0); k < 64; k++ {
+				if desbits.SBox(i, x1^k)^desbits.SBox(i, x2^k) == dy {
+					res.Counts[i][k]++
+				}
+			}
+		}
+	}
+
+	for i := 0; i < desbits.SBoxCount; i++ {
+		best, second := 0, 0
+		for k, cnt := range res.Counts[i] {
+			if cnt > best {
+				best, second = cnt, best
+				res.Subkeys[i] = byte(k)
+			} else if cnt > second {
+				second = cnt
+			}
+		}
+		// подключ считается восстановленным при однозначном максимуме
+		if known&(1<<i) != 0 && best > second {
+			res.Mask |= 1 << i
+		}
+	}
+	return res, nil
+}
+
+// encryptHalves шифрует блок, заданный половинами после IP, и возвращает (R_r, L_r)
+func encryptHalves(oracle ciphers.SymmetricCipher, l, r uint32) (uint32, uint32, error) {
+	ct, err := oracle.Encrypt(desbits.Join(l, r))
+	if err != nil {
+		return 0, 0, fmt.Errorf("differential: oracle: %w", err)
+	}
+	rr, lr := desbits.Split(ct)
+	return rr, lr, nil
+}
+
+// SubkeyBits - маска 48-битного раундового ключа по восстановленным S-блокам и сами биты
+func (r *Result) SubkeyBits() (mask, key uint64) {
+	for i := 0; i < desbits.SBoxCount; i++ {
+		if r.Mask&(1<<i) == 0 {
+			continue
+		}
+		shift := uint(42 - 6*i)
+		mask |= 0x3F << shift
+		key |= uint64(r.Subkeys[i]) << shift
+	}
+	return mask, key
+}
@@ -0,0 +1,193 @@
+package differential
+
+import (
+	"fmt"
+	"math/bits"
+
+	"crypto-lab/internal/cryptanalysis/desbits"
+)
+
+// RoundDifferential - переход разностей через F одного раунда
+type RoundDifferential struct {
+	InputDiff   uint32 // разность правой половины на входе F
+	OutputDiff  uint32 // разность выхода F (после P)
+	Probability float64
+}
+
+// Characteristic - многораундовая характеристика: разность (L0, R0) открытых текстов
+// после IP переходит в (L, R) после len(Rounds) раундов с вероятностью Probability
+type Characteristic struct {
+	InputL, InputR   uint32
+	OutputL, OutputR uint32
+	Rounds           []RoundDifferential
+	Probability      float64
+}
+
+func (c *Characteristic) String() string {
+	return fmt.Sprintf("%d rounds: (%08x, %08x) -> (%08x, %08x), p = 1/%.0f",
+		len(c.Rounds), c.InputL, c.InputR, c.OutputL, c.OutputR, 1/c.Probability)
+}
+
+// NextInactive - неактивные S-блоки раунда, следующего за характеристикой
+// (их выходная разность нулевая с вероятностью 1)
+func (c *Characteristic) NextInactive() uint8 {
+	return ^desbits.ActiveSBoxes(c.OutputR)
+}
+
+type SearchOptions struct {
+	MaxActiveSBoxes int // активных S-блоков в раунде, по умолчанию 2
+	OutputsPerSBox  int // сколько лучших выходных разностей S-блока перебирать, по умолчанию 2
+}
+
+func (o *SearchOptions) defaults() SearchOptions {
+	res := SearchOptions{MaxActiveSBoxes: 2, OutputsPerSBox: 2}
+	if o != nil && o.MaxActiveSBoxes > 0 {
+		res.MaxActiveSBoxes = o.MaxActiveSBoxes
+	}
+	if o != nil && o.OutputsPerSBox > 0 {
+		res.OutputsPerSBox = o.OutputsPerSBox
+	}
+	return res
+}
+
+type searcher struct {
+	ddts   [desbits.SBoxCount]*DDT
+	opts   SearchOptions
+	rounds int
+	best   *Characteristic
+	path   []RoundDifferential
+}
+
+// SearchCharacteristic ищет ветвями и границами характеристику на rounds раундов
+// с наибольшей вероятностью; при равной вероятности предпочитает больше
+// неактивных S-блоков в следующем раунде (больше бит ключа для атаки).
+// Перебор ограничен входными разностями, активирующими не больше
+// MaxActiveSBoxes соседних S-блоков.
+func SearchCharacteristic(rounds int, opts *SearchOptions) (*Characteristic, error) {
+	if rounds < 1 {
+		return nil, fmt.Errorf("differential: rounds must be >= 1, got %d", rounds)
+	}
+	s := &searcher{ddts: DESDDTs(), opts: opts.defaults(), rounds: rounds}
+
+	for _, dr := range s.startDifferences() {
+		if dr == 0 {
+			// первый раунд бесплатный, dL переходит в dR следующего
+			for _, dl := range s.startDifferences() {
+				if dl != 0 {
+					s.extend(dl, 0, dl, 0, 1)
+				}
+			}
+			continue
+		}
+		// dL = 0 или dL гасит вероятный выход F, тогда второй раунд бесплатный
+		s.extend(0, dr, 0, dr, 1)
+		for _, rd := range s.roundOutputs(dr) {
+			s.extend(rd.OutputDiff, dr, rd.OutputDiff, dr, 1)
+		}
+	}
+
+	if s.best == nil {
+		return nil, fmt.Errorf("differential: no %d-round characteristic within search limits", rounds)
+	}
+	return s.best, nil
+}
+
+// разности правой половины, у которых активны не больше MaxActiveSBoxes блоков:
+// все значения в окнах по 8 бит вокруг каждого S-блока
+func (s *searcher) startDifferences() []uint32 {
+	seen := map[uint32]bool{0: true}
+	diffs := []uint32{0}
+	for i := 0; i < desbits.SBoxCount; i++ {
+		for v := uint32(1); v < 256; v++ {
+			// окно начинается с последнего бита предыдущей четверки
+			shift := (32 - 4*i - 8 + 1 + 32) % 32
+			dr := bits.RotateLeft32(v, shift)
+			if seen[dr] || bits.OnesCount8(desbits.ActiveSBoxes(dr)) > s.opts.MaxActiveSBoxes {
+				continue
+			}
+			seen[dr] = true
+			diffs = append(diffs, dr)
+		}
+	}
+	return diffs
+}
+
+// roundOutputs перечисляет выходные разности F для dr: по OutputsPerSBox лучших
+// на каждый активный S-блок
+func (s *searcher) roundOutputs(dr uint32) []RoundDifferential {
+	e := desbits.Expand(dr)
+	active := desbits.ActiveSBoxes(dr)
+	if bits.OnesCount8(active) > s.opts.MaxActiveSBoxes {
+		return nil
+	}
+
+	results := []RoundDifferential{{InputDiff: dr, Probability: 1}}
+	var sOut []uint32 = []uint32{0}
+	for i := 0; i < desbits.SBoxCount; i++ {
+		shift := uint(28 - 4*i)
+		if active&(1<<i) == 0 {
+			continue
+		}
+		dx := desbits.SBoxInput(e, i)
+		outs := s.ddts[i].outputsByCount(dx)
+		if len(outs) > s.opts.OutputsPerSBox {
+			outs = outs[:s.opts.OutputsPerSBox]
+		}
+
+		var nextRes []RoundDifferential
+		var nextOut []uint32
+		for k, rd := range results {
+			for _, dy := range outs {
+				nextRes = append(nextRes, RoundDifferential{
+					InputDiff:   dr,
+					Probability: rd.Probability * s.ddts[i].Probability(dx, dy),
+				})
+				nextOut = append(nextOut, sOut[k]|uint32(dy)<<shift)
+			}
+		}
+		results, sOut = nextRes, nextOut
+	}
+	for k := range results {
+		results[k].OutputDiff = desbits.PermuteP(sOut[k])
+	}
+	return results
+}
+
+// extend: состояние (dl, dr) после уже пройденных раундов (в s.path), вероятность p
+func (s *searcher) extend(inL, inR, dl, dr uint32, p float64) {
+	if s.best != nil && p < s.best.Probability {
+		return
+	}
+	if len(s.path) == s.rounds {
+		s.consider(inL, inR, dl, dr, p)
+		return
+	}
+
+	if dr == 0 {
+		s.path = append(s.path, RoundDifferential{Probability: 1})
+		s.extend(inL, inR, 0, dl, p)
+		s.path = s.path[:len(s.path)-1]
+		return
+	}
+	for _, rd := range s.roundOutputs(dr) {
+		s.path = append(s.path, rd)
+		s.extend(inL, inR, dr, dl^rd.OutputDiff, p*rd.Probability)
+		s.path = s.path[:len(s.path)-1]
+	}
+}
+
+func (s *searcher) consider(inL, inR, dl, dr uint32, p float64) {
+	if dl == 0 && dr == 0 {
+		return
+	}
+	c := &Characteristic{
+		InputL: inL, InputR: inR,
+		OutputL: dl, OutputR: dr,
+		Rounds:      append([]RoundDifferential(nil), s.path...),
+		Probability: p,
+	}
+	if s.best == nil || p > s.best.Probability ||
+		p == s.best.Probability && bits.OnesCount8(c.NextInactive()) > bits.OnesCount8(s.best.NextInactive()) {
+		s.best = c
+	}
+}
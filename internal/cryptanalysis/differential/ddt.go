@@ -0,0 +1,66 @@
+package differential
+
+import (
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/cryptanalysis/desbits"
+)
+
+// DDT - таблица распределения разностей S-блока 6 -> 4:
+// DDT[dx][dy] = #{x : S(x) xor S(x xor dx) = dy}, сумма строки = 64
+type DDT [64][16]int
+
+func NewDDT(sbox [4][16]byte) *DDT {
+	lookup := func(x int) int {
+		row := (x>>4)&2 | x&1
+		return int(sbox[row][(x>>1)&0x0F])
+	}
+	var t DDT
+	for dx := 0; dx < 64; dx++ {
+		for x := 0; x < 64; x++ {
+			t[dx][lookup(x)^lookup(x^dx)]++
+		}
+	}
+	return &t
+}
+
+// DESDDTs строит таблицы для всех восьми S-блоков из des.DES.S()
+func DESDDTs() [desbits.SBoxCount]*DDT {
+	var tables [desbits.SBoxCount]*DDT
+	for i, sbox := range des.DES.S() {
+		tables[i] = NewDDT(sbox)
+	}
+	return tables
+}
+
+// Probability - вероятность перехода dx -> dy
+func (t *DDT) Probability(dx, dy byte) float64 {
+	return float64(t[dx][dy]) / 64
+}
+
+// Max - самая вероятная ненулевая разность: dx != 0, максимум по таблице
+func (t *DDT) Max() (dx, dy byte, count int) {
+	for x := 1; x < 64; x++ {
+		for y := 0; y < 16; y++ {
+			if t[x][y] > count {
+				dx, dy, count = byte(x), byte(y), t[x][y]
+			}
+		}
+	}
+	return dx, dy, count
+}
+
+// outputsByCount - выходные разности для dx по убыванию числа пар, без нулевых
+func (t *DDT) outputsByCount(dx byte) []byte {
+	var outs []byte
+	for y := 0; y < 16; y++ {
+		if t[dx][y] > 0 {
+			outs = append(outs, byte(y))
+		}
+	}
+	for i := 1; i < len(outs); i++ {
+		for j := i; j > 0 && t[dx][outs[j]] > t[dx][outs[j-1]]; j-- {
+			outs[j], outs[j-1] = outs[j-1], outs[j]
+		}
+	}
+	return outs
+}
@@ -0,0 +1,96 @@
+package differential_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/cryptanalysis/desbits"
+	"crypto-lab/internal/cryptanalysis/differential"
+)
+
+func TestDDT(t *testing.T) {
+	for i, ddt := range differential.DESDDTs() {
+		if ddt[0][0] != 64 {
+			t.Errorf("S%d: DDT[0][0] = %d, expected 64", i+1, ddt[0][0])
+		}
+		for dx := 0; dx < 64; dx++ {
+			sum := 0
+			for dy := 0; dy < 16; dy++ {
+				if ddt[dx][dy]%2 != 0 {
+					t.Errorf("S%d: DDT[%d][%d] is odd", i+1, dx, dy)
+				}
+				sum += ddt[dx][dy]
+			}
+			if sum != 64 {
+				t.Errorf("S%d: row %d sums to %d", i+1, dx, sum)
+			}
+		}
+		// критерий проектирования S-блоков DES: ни один переход не вероятнее 1/4
+		if _, _, count := ddt.Max(); count > 16 {
+			t.Errorf("S%d: max DDT entry %d > 16", i+1, count)
+		}
+	}
+
+	// S1: 0x34 -> 0x2 с вероятностью 16/64 (Biham-Shamir)
+	if p := differential.DESDDTs()[0].Probability(0x34, 0x2); p != 0.25 {
+		t.Errorf("S1: P(34 -> 2) = %v, expected 1/4", p)
+	}
+}
+
+func TestSearchCharacteristic(t *testing.T) {
+	expected := map[int]float64{1: 1, 2: 1.0 / 4, 3: 1.0 / 16}
+	for rounds, p := range expected {
+		c, err := differential.SearchCharacteristic(rounds, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(c.Rounds) != rounds || c.Probability < p {
+			t.Errorf("%d rounds: %v, expected p >= %v", rounds, c, p)
+		}
+	}
+
+	if _, err := differential.SearchCharacteristic(0, nil); err == nil {
+		t.Error("0 rounds must fail")
+	}
+}
+
+func TestAttack(t *testing.T) {
+	pairs := map[int]int{4: 64, 5: 256, 6: 1024}
+	for rounds := 4; rounds <= 6; rounds++ {
+		cipher, err := des.NewDESWithRounds(rounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := make([]byte, 8)
+		rand.Read(key)
+		if err := cipher.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := differential.SearchCharacteristic(rounds-3, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := differential.Attack(cipher, rounds, c, pairs[rounds])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mask, bits := res.SubkeyBits()
+		if mask == 0 {
+			t.Fatalf("%d rounds: no subkey bits recovered (%v)", rounds, c)
+		}
+		expected := desbits.RoundKey(cipher.RoundKeys()[rounds-1])
+		if bits != expected&mask {
+			t.Errorf("%d rounds: recovered %012x under mask %012x, expected %012x", rounds, bits, mask, expected&mask)
+		}
+		t.Logf("%d rounds: %v, %d of %d pairs passed, mask %012x", rounds, c, res.Filtered, res.Pairs, mask)
+	}
+
+	cipher, _ := des.NewDESWithRounds(6)
+	c, _ := differential.SearchCharacteristic(1, nil)
+	if _, err := differential.Attack(cipher, 6, c, 10); err == nil {
+		t.Error("1-round characteristic must not attack 6 rounds")
+	}
+}
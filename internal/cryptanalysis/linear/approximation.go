@@ -0,0 +1,282 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"crypto-lab/internal/cryptanalysis/desbits"
+)
+
+// Маски - над половинами блока после IP. Раунд i: L_i = R_{i-1}, R_i = L_{i-1} xor F(R_{i-1}, K_i).
+// Приближение F: InputMask·R xor OutputMask·F(R, K) = KeyMask·K со смещением Bias.
+// Маски состояния (uL, uR) переходят в (InputMask xor uR, uL) при OutputMask = uL.
+
+// RoundApproximation - приближение F одного раунда
+type RoundApproximation struct {
+	InputMask  uint32
+	OutputMask uint32
+	KeyMask    uint64 // маска 48-битного раундового ключа
+	Bias       float64
+}
+
+// Approximation - многораундовое приближение:
+// InputL·L0 xor InputR·R0 xor OutputL·L_n xor OutputR·R_n = xor_i KeyMask_i·K_i
+// с вероятностью 1/2 + Bias (лемма о накоплении)
+type Approximation struct {
+	InputL, InputR   uint32
+	OutputL, OutputR uint32
+	Rounds           []RoundApproximation
+	Bias             float64
+}
+
+func (a *Approximation) String() string {
+	return fmt.Sprintf("%d rounds: (%08x, %08x) -> (%08x, %08x), bias %+.3g (2^%.2f)",
+		len(a.Rounds), a.InputL, a.InputR, a.OutputL, a.OutputR, a.Bias, math.Log2(math.Abs(a.Bias)))
+}
+
+// KeyParity - правая часть приближения для известных раундовых ключей
+func (a *Approximation) KeyParity(roundKeys [][]byte) byte {
+	var p byte
+	for i, r := range a.Rounds {
+		p ^= desbits.Parity(r.KeyMask & desbits.RoundKey(roundKeys[i]))
+	}
+	return p
+}
+
+type SearchOptions struct {
+	MaxActiveSBoxes int // активных S-блоков в раунде, по умолчанию 2
+	InputsPerSBox   int // сколько лучших входных масок S-блока перебирать, по умолчанию 2
+}
+
+func (o *SearchOptions) defaults() SearchOptions {
+	res := SearchOptions{MaxActiveSBoxes: 2, InputsPerSBox: 2}
+	if o != nil && o.MaxActiveSBoxes > 0 {
+		res.MaxActiveSBoxes = o.MaxActiveSBoxes
+	}
+	if o != nil && o.InputsPerSBox > 0 {
+		res.InputsPerSBox = o.InputsPerSBox
+	}
+	return res
+}
+
+type searcher struct {
+	lats   [desbits.SBoxCount]*LAT
+	opts   SearchOptions
+	rounds int
+	best   *Approximation
+	path   []RoundApproximation
+	cache  map[uint32][]RoundApproximation
+	bounds []float64 // bounds[k] - |корреляция| лучшего приближения на k раундов
+}
+
+// SearchApproximation ищет ветвями и границами приближение на rounds раундов
+// с наибольшим |смещением|; при равном смещении предпочитает меньше активных
+// S-блоков в следующем раунде (меньше бит ключа перебирать в алгоритме 2).
+// Каждый раунд - не больше MaxActiveSBoxes активных S-блоков.
+func SearchApproximation(rounds int, opts *SearchOptions) (*Approximation, error) {
+	if rounds < 1 {
+		return nil, fmt.Errorf("linear: rounds must be >= 1, got %d", rounds)
+	}
+	s := &searcher{lats: DESLATs(), opts: opts.defaults(),
+		cache: make(map[uint32][]RoundApproximation), bounds: []float64{1}}
+
+	// как у Мацуи: лучшие приближения на 1..rounds-1 раундов ограничивают
+	// корреляцию оставшихся раундов в поиске на rounds
+	starts := s.startMasks()
+	for n := 1; n <= rounds; n++ {
+		s.rounds, s.best = n, nil
+		s.search(starts)
+		if s.best == nil {
+			return nil, fmt.Errorf("linear: no %d-round approximation within search limits", n)
+		}
+		s.bounds = append(s.bounds, 2*math.Abs(s.best.Bias))
+	}
+	return s.best, nil
+}
+
+func (s *searcher) search(starts []uint32) {
+	for _, beta := range starts {
+		// первый раунд бесплатный: uL0 = 0
+		s.path = append(s.path, RoundApproximation{Bias: 0.5})
+		s.extend(0, beta, beta, 0, 1)
+		s.path = s.path[:0]
+
+		for _, ra := range s.roundApproximations(beta) {
+			c := 2 * ra.Bias
+			if s.pruned(c, 1) {
+				break
+			}
+			s.path = append(s.path, ra)
+			// маска uR0 свободна, поэтому выходная маска второго раунда - любая:
+			// ноль (второй раунд бесплатный) или одна из начальных
+			s.extend(beta, ra.InputMask, 0, beta, c)
+			for _, next := range starts {
+				if s.pruned(c*s.maxCorrelation(next), 2) {
+					break
+				}
+				s.extend(beta, ra.InputMask^next, next, beta, c)
+			}
+			s.path = s.path[:0]
+		}
+	}
+}
+
+// выходные маски F, у которых активны не больше MaxActiveSBoxes S-блоков
+func (s *searcher) startMasks() []uint32 {
+	masks := []uint32{0}
+	for k := 0; k < s.opts.MaxActiveSBoxes; k++ {
+		var next []uint32
+		for _, m := range masks {
+			// новый S-блок правее последнего активного, чтобы не повторяться
+			first := 0
+			if m != 0 {
+				first = desbits.SBoxCount - bits.TrailingZeros32(m)/4
+			}
+			for i := first; i < desbits.SBoxCount; i++ {
+				for b := uint32(1); b < 16; b++ {
+					next = append(next, m|b<<(28-4*i))
+				}
+			}
+		}
+		masks = append(masks, next...)
+	}
+
+	res := make([]uint32, 0, len(masks)-1)
+	for _, m := range masks[1:] {
+		res = append(res, desbits.PermuteP(m))
+	}
+	// по убыванию лучшей корреляции раунда, чтобы граница отсекала раньше
+	sort.SliceStable(res, func(i, j int) bool {
+		return s.maxCorrelation(res[i]) > s.maxCorrelation(res[j])
+	})
+	return res
+}
+
+// |корреляция| лучшего приближения F с выходной маской beta
+func (s *searcher) maxCorrelation(beta uint32) float64 {
+	ras := s.roundApproximations(beta)
+	if len(ras) == 0 {
+		return 0
+	}
+	return math.Abs(2 * ras[0].Bias)
+}
+
+// ветвь с |корреляцией| c после done раундов не улучшит лучшее найденное
+func (s *searcher) pruned(c float64, done int) bool {
+	if s.best == nil {
+		return false
+	}
+	if done > s.rounds {
+		done = s.rounds
+	}
+	return math.Abs(c)*s.bounds[s.rounds-done] < 2*math.Abs(s.best.Bias)
+}
+
+func activeSBoxes(beta uint32) int {
+	m := desbits.InversePermuteP(beta)
+	n := 0
+	for i := 0; i < desbits.SBoxCount; i++ {
+		if desbits.SBoxOutput(m, i) != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// roundApproximations перечисляет приближения F с выходной маской beta по убыванию
+// |смещения|: по InputsPerSBox лучших входных масок на каждый активный S-блок
+func (s *searcher) roundApproximations(beta uint32) []RoundApproximation {
+	if ras, ok := s.cache[beta]; ok {
+		return ras
+	}
+	if activeSBoxes(beta) > s.opts.MaxActiveSBoxes {
+		s.cache[beta] = nil
+		return nil
+	}
+	m := desbits.InversePermuteP(beta)
+
+	type partial struct {
+		key  uint64
+		corr float64
+	}
+	results := []partial{{corr: 1}}
+	for i := 0; i < desbits.SBoxCount; i++ {
+		b := desbits.SBoxOutput(m, i)
+		if b == 0 {
+			continue
+		}
+		ins := s.lats[i].inputsByBias(b)
+		if len(ins) > s.opts.InputsPerSBox {
+			ins = ins[:s.opts.InputsPerSBox]
+		}
+		var next []partial
+		for _, p := range results {
+			for _, a := range ins {
+				next = append(next, partial{
+					key:  p.key | uint64(a)<<(42-6*i),
+					corr: p.corr * float64(s.lats[i][a][b]) / 32,
+				})
+			}
+		}
+		results = next
+	}
+
+	out := make([]RoundApproximation, len(results))
+	for k, p := range results {
+		out[k] = RoundApproximation{
+			InputMask:  desbits.CompressMask(p.key),
+			OutputMask: beta,
+			KeyMask:    p.key,
+			Bias:       p.corr / 2,
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return math.Abs(out[i].Bias) > math.Abs(out[j].Bias)
+	})
+	s.cache[beta] = out
+	return out
+}
+
+// extend: маски (uL, uR) после раундов из s.path, корреляция c = 2·смещение
+func (s *searcher) extend(inL, inR, uL, uR uint32, c float64) {
+	if s.pruned(c, len(s.path)) {
+		return
+	}
+	if len(s.path) == s.rounds {
+		s.consider(inL, inR, uL, uR, c)
+		return
+	}
+
+	if uL == 0 {
+		s.path = append(s.path, RoundApproximation{Bias: 0.5})
+		s.extend(inL, inR, uR, 0, c)
+		s.path = s.path[:len(s.path)-1]
+		return
+	}
+	for _, ra := range s.roundApproximations(uL) {
+		if s.pruned(c*2*ra.Bias, len(s.path)+1) {
+			break
+		}
+		s.path = append(s.path, ra)
+		s.extend(inL, inR, ra.InputMask^uR, uL, c*2*ra.Bias)
+		s.path = s.path[:len(s.path)-1]
+	}
+}
+
+func (s *searcher) consider(inL, inR, uL, uR uint32, c float64) {
+	if inL == 0 && inR == 0 || uL == 0 && uR == 0 {
+		return
+	}
+	a := &Approximation{
+		InputL: inL, InputR: inR,
+		OutputL: uL, OutputR: uR,
+		Rounds: append([]RoundApproximation(nil), s.path...),
+		Bias:   c / 2,
+	}
+	if s.best == nil || math.Abs(a.Bias) > math.Abs(s.best.Bias) ||
+		math.Abs(a.Bias) == math.Abs(s.best.Bias) && activeSBoxes(uL) < activeSBoxes(s.best.OutputL) {
+		s.best = a
+	}
+}
@@ -0,0 +1,79 @@
+package linear
+
+import (
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/cryptanalysis/desbits"
+)
+
+// LAT - таблица линейных приближений S-блока 6 -> 4:
+// LAT[a][b] = #{x : a·x = b·S(x)} - 32, смещение приближения = LAT[a][b]/64
+type LAT [64][16]int
+
+func NewLAT(sbox [4][16]byte) *LAT {
+	lookup := func(x int) int {
+		row := (x>>4)&2 | x&1
+		return int(sbox[row][(x>>1)&0x0F])
+	}
+	var t LAT
+	for a := 0; a < 64; a++ {
+		for b := 0; b < 16; b++ {
+			count := 0
+			for x := 0; x < 64; x++ {
+				if desbits.Parity(uint64(x&a)) == desbits.Parity(uint64(lookup(x)&b)) {
+					count++
+				}
+			}
+			t[a][b] = count - 32
+		}
+	}
+	return &t
+}
+
+// DESLATs строит таблицы для всех восьми S-блоков из des.DES.S()
+func DESLATs() [desbits.SBoxCount]*LAT {
+	var tables [desbits.SBoxCount]*LAT
+	for i, sbox := range des.DES.S() {
+		tables[i] = NewLAT(sbox)
+	}
+	return tables
+}
+
+// Bias - смещение вероятности a·x = b·S(x) от 1/2
+func (t *LAT) Bias(a, b byte) float64 {
+	return float64(t[a][b]) / 64
+}
+
+// Max - приближение с наибольшим |смещением| при b != 0
+func (t *LAT) Max() (a, b byte, value int) {
+	for x := 1; x < 64; x++ {
+		for y := 1; y < 16; y++ {
+			if abs(t[x][y]) > abs(value) {
+				a, b, value = byte(x), byte(y), t[x][y]
+			}
+		}
+	}
+	return a, b, value
+}
+
+// inputsByBias - входные маски для выходной маски b по убыванию |LAT|, без нулевых
+func (t *LAT) inputsByBias(b byte) []byte {
+	var ins []byte
+	for a := 1; a < 64; a++ {
+		if t[a][b] != 0 {
+			ins = append(ins, byte(a))
+		}
+	}
+	for i := 1; i < len(ins); i++ {
+		for j := i; j > 0 && abs(t[ins[j]][b]) > abs(t[ins[j-1]][b]); j-- {
+			ins[j], ins[j-1] = ins[j-1], ins[j]
+		}
+	}
+	return ins
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,115 @@
+package linear_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/cryptanalysis/desbits"
+	"crypto-lab/internal/cryptanalysis/linear"
+)
+
+func TestLAT(t *testing.T) {
+	lats := linear.DESLATs()
+	for i, lat := range lats {
+		if lat[0][0] != 32 {
+			t.Errorf("S%d: LAT[0][0] = %d, expected 32", i+1, lat[0][0])
+		}
+		// S-блоки сбалансированы: ненулевая выходная маска без входной - смещение 0
+		for b := 1; b < 16; b++ {
+			if lat[0][b] != 0 {
+				t.Errorf("S%d: LAT[0][%d] = %d", i+1, b, lat[0][b])
+			}
+		}
+	}
+
+	// лучшее приближение DES по Мацуи: S5, 0x10 -> 0xF, 12 совпадений из 64
+	if a, b, v := lats[4].Max(); a != 0x10 || b != 0xF || v != -20 {
+		t.Errorf("S5: best approximation %02x -> %x (%d), expected 10 -> f (-20)", a, b, v)
+	}
+}
+
+func TestSearchApproximation(t *testing.T) {
+	// смещения лучших приближений из статьи Мацуи
+	expected := map[int]float64{3: 1.56 / 8, 4: 1.95 / 32, 5: 1.19 / 64}
+	for rounds, bias := range expected {
+		a, err := linear.SearchApproximation(rounds, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(a.Rounds) != rounds || a.Bias < bias && -a.Bias < bias {
+			t.Errorf("%d rounds: %v, expected |bias| >= %v", rounds, a, bias)
+		}
+	}
+
+	if _, err := linear.SearchApproximation(0, nil); err == nil {
+		t.Error("0 rounds must fail")
+	}
+}
+
+func newReducedDES(t *testing.T, rounds int) *des.DESCipher {
+	t.Helper()
+	cipher, err := des.NewDESWithRounds(rounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := make([]byte, 8)
+	rand.Read(key)
+	if err := cipher.SetSymmetricKey(key); err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func TestAlgorithm1(t *testing.T) {
+	for rounds, n := range map[int]int{3: 512, 5: 16384} {
+		cipher := newReducedDES(t, rounds)
+		a, err := linear.SearchApproximation(rounds, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairs, err := linear.GeneratePairs(cipher, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := linear.Algorithm1(pairs, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := a.KeyParity(cipher.RoundKeys()); res.KeyParity != expected {
+			t.Errorf("%d rounds: key parity %d, expected %d (%d of %d)", rounds, res.KeyParity, expected, res.Count, res.Pairs)
+		}
+	}
+}
+
+func TestAlgorithm2(t *testing.T) {
+	for rounds, n := range map[int]int{4: 1024, 5: 4096} {
+		cipher := newReducedDES(t, rounds)
+		a, err := linear.SearchApproximation(rounds-1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairs, err := linear.GeneratePairs(cipher, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := linear.Algorithm2(pairs, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		roundKeys := cipher.RoundKeys()
+		last := desbits.RoundKey(roundKeys[rounds-1])
+		for i := 0; i < desbits.SBoxCount; i++ {
+			if res.Mask&(1<<i) == 0 {
+				continue
+			}
+			if expected := desbits.SBoxInput(last, i); res.Subkeys[i] != expected {
+				t.Errorf("%d rounds: S%d subkey %02x, expected %02x", rounds, i+1, res.Subkeys[i], expected)
+			}
+		}
+		if expected := a.KeyParity(roundKeys); res.KeyParity != expected {
+			t.Errorf("%d rounds: key parity %d, expected %d", rounds, res.KeyParity, expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package linear
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/cryptanalysis/desbits"
+)
+
+// MaxAlgorithm2SBoxes - сколько S-блоков последнего раунда алгоритм 2 перебирает
+// (2^(6k) кандидатов ключа)
+const MaxAlgorithm2SBoxes = 2
+
+// Pair - известная пара открытый текст / шифртекст
+type Pair struct {
+	Plaintext, Ciphertext []byte
+}
+
+// GeneratePairs шифрует n случайных блоков шифром с неизвестным ключом
+// (например des.NewDESWithRounds(rounds))
+func GeneratePairs(oracle ciphers.SymmetricCipher, n int) ([]Pair, error) {
+	if oracle.GetBlockSize() != 8 {
+		return nil, fmt.Errorf("linear: block size must be 8, got %d", oracle.GetBlockSize())
+	}
+	pairs := make([]Pair, n)
+	for i := range pairs {
+		pt := make([]byte, 8)
+		if _, err := rand.Read(pt); err != nil {
+			return nil, err
+		}
+		ct, err := oracle.Encrypt(pt)
+		if err != nil {
+			return nil, fmt.Errorf("linear: oracle: %w", err)
+		}
+		pairs[i] = Pair{Plaintext: pt, Ciphertext: ct}
+	}
+	return pairs, nil
+}
+
+type Algorithm1Result struct {
+	KeyParity byte // xor_i KeyMask_i·K_i
+	Count     int  // пар, для которых левая часть равна 0
+	Pairs     int
+}
+
+// Algorithm1 - алгоритм 1 Мацуи: приближение покрывает все раунды шифра,
+// по большинству определяется один бит - четность ключа
+func Algorithm1(pairs []Pair, a *Approximation) (*Algorithm1Result, error) {
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("linear: no pairs")
+	}
+	res := &Algorithm1Result{Pairs: len(pairs)}
+	for _, p := range pairs {
+		l0, r0 := desbits.Split(p.Plaintext)
+		rn, ln := desbits.Split(p.Ciphertext)
+		x := a.InputL&l0 ^ a.InputR&r0 ^ a.OutputL&ln ^ a.OutputR&rn
+		if desbits.Parity(uint64(x)) == 0 {
+			res.Count++
+		}
+	}
+	res.KeyParity = keyParity(res.Count, len(pairs), a.Bias)
+	return res, nil
+}
+
+// при смещении > 0 левая часть чаще равна правой
+func keyParity(count, n int, bias float64) byte {
+	var p byte
+	if 2*count < n {
+		p = 1
+	}
+	if bias < 0 {
+		p ^= 1
+	}
+	return p
+}
+
+type Algorithm2Result struct {
+	Subkeys   [desbits.SBoxCount]byte // 6 бит подключа последнего раунда на S-блок
+	Mask      uint8                   // S-блоки последнего раунда, подключи которых найдены
+	KeyParity byte                    // четность ключей первых len(a.Rounds) раундов
+	Count     int                     // для лучшего кандидата
+	Pairs     int
+}
+
+// Algorithm2 - алгоритм 2 Мацуи: приближение на r-1 раундов, последний раунд
+// расшифровывается по угаданным подключам активных S-блоков. Верный кандидат
+// дает наибольшее отклонение счетчика от N/2.
+func Algorithm2(pairs []Pair, a *Approximation) (*Algorithm2Result, error) {
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("linear: no pairs")
+	}
+	// OutputL·L_{r-1} = OutputL·R_r xor m·S(E(L_r) xor K_r)
+	m := desbits.InversePermuteP(a.OutputL)
+	var active []int
+	for i := 0; i < desbits.SBoxCount; i++ {
+		if desbits.SBoxOutput(m, i) != 0 {
+			active = append(active, i)
+		}
+	}
+	if len(active) == 0 {
+		return nil, fmt.Errorf("linear: approximation does not involve the last round")
+	}
+	if len(active) > MaxAlgorithm2SBoxes {
+		return nil, fmt.Errorf("linear: %d active S-boxes in the last round, at most %d supported",
+			len(active), MaxAlgorithm2SBoxes)
+	}
+
+	// счетчики по входам активных S-блоков и четности остальной части
+	size := 1 << (6 * len(active))
+	counts := make([][2]int, size)
+	for _, p := range pairs {
+		l0, r0 := desbits.Split(p.Plaintext)
+		rr, lr := desbits.Split(p.Ciphertext)
+		x := a.InputL&l0 ^ a.InputR&r0 ^ a.OutputL&rr ^ a.OutputR&lr
+		e := desbits.Expand(lr)
+		idx := 0
+		for _, i := range active {
+			idx = idx<<6 | int(desbits.SBoxInput(e, i))
+		}
+		counts[idx][desbits.Parity(uint64(x))]++
+	}
+
+	// f[y] = m·S(y) на входах активных S-блоков
+	f := make([]byte, size)
+	for y := range f {
+		var out uint32
+		for k, i := range active {
+			x := byte(y>>(6*(len(active)-1-k))) & 0x3F
+			out |= uint32(desbits.SBox(i, x)) << (28 - 4*i)
+		}
+		f[y] = desbits.Parity(uint64(out & m))
+	}
+
+	res := &Algorithm2Result{Pairs: len(pairs)}
+	bestGuess, bestDev := 0, -1
+	for g := 0; g < size; g++ {
+		count := 0
+		for idx, c := range counts {
+			count += c[f[idx^g]]
+		}
+		dev := 2*count - len(pairs)
+		if dev < 0 {
+			dev = -dev
+		}
+		if dev > bestDev {
+			bestGuess, bestDev, res.Count = g, dev, count
+		}
+	}
+
+	for k, i := range active {
+		res.Subkeys[i] = byte(bestGuess>>(6*(len(active)-1-k))) & 0x3F
+		res.Mask |= 1 << i
+	}
+	res.KeyParity = keyParity(res.Count, len(pairs), a.Bias)
+	return res, nil
+}
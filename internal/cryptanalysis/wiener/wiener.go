@@ -0,0 +1,145 @@
+package wiener
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"crypto-lab/internal/asymmetric/rsa"
+)
+
+// Атака Винера: при d < N^(1/4)/3 дробь k/d из e*d - k*phi = 1 является
+// подходящей дробью цепной дроби e/N. Для каждой подходящей k/d проверяем:
+// phi = (e*d - 1)/k целое, и x^2 - (N - phi + 1)x + N = 0 имеет корни p, q.
+
+var ErrNotVulnerable = errors.New("wiener: no convergent of e/N yields the private key")
+
+var bigOne = big.NewInt(1)
+
+// Convergent - подходящая дробь K/D цепной дроби e/N
+type Convergent struct {
+	K *big.Int
+	D *big.Int
+}
+
+type Result struct {
+	D, P, Q     *big.Int
+	Phi         *big.Int
+	Convergents []Convergent // все проверенные подходящие дроби, по порядку
+}
+
+// Attack восстанавливает d, p и q по открытому ключу. Result возвращается
+// и при ErrNotVulnerable - с перебранными подходящими дробями.
+func Attack(e, n *big.Int) (*Result, error) {
+	if e.Sign() <= 0 || n.Sign() <= 0 {
+		return nil, fmt.Errorf("wiener: e and N must be positive")
+	}
+
+	res := &Result{}
+	// h_i = a_i*h_{i-1} + h_{i-2} (числитель k), k_i - так же для знаменателя d
+	hPrev, h := big.NewInt(0), big.NewInt(1)
+	kPrev, k := big.NewInt(1), big.NewInt(0)
+	num, den := new(big.Int).Set(e), new(big.Int).Set(n)
+	a := new(big.Int)
+
+	for den.Sign() != 0 {
+		a.QuoRem(num, den, num)
+		num, den = den, num
+
+		hPrev, h = h, new(big.Int).Add(new(big.Int).Mul(a, h), hPrev)
+		kPrev, k = k, new(big.Int).Add(new(big.Int).Mul(a, k), kPrev)
+
+		c := Convergent{K: h, D: k}
+		res.Convergents = append(res.Convergents, c)
+		if p, q, phi, ok := tryConvergent(e, n, c); ok {
+			res.D, res.P, res.Q, res.Phi = new(big.Int).Set(c.D), p, q, phi
+			return res, nil
+		}
+	}
+	return res, ErrNotVulnerable
+}
+
+func tryConvergent(e, n *big.Int, c Convergent) (p, q, phi *big.Int, ok bool) {
+	if c.K.Sign() == 0 || c.D.Bit(0) == 0 {
+		return nil, nil, nil, false // d нечетно: e*d = 1 mod четного phi
+	}
+
+	ed1 := new(big.Int).Mul(e, c.D)
+	ed1.Sub(ed1, bigOne)
+	phi, rem := new(big.Int).QuoRem(ed1, c.K, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, nil, nil, false
+	}
+
+	// s = p + q = N - phi + 1, дискриминант s^2 - 4N = (p - q)^2
+	s := new(big.Int).Sub(n, phi)
+	s.Add(s, bigOne)
+	disc := new(big.Int).Mul(s, s)
+	disc.Sub(disc, new(big.Int).Lsh(n, 2))
+	if disc.Sign() < 0 {
+		return nil, nil, nil, false
+	}
+	root := new(big.Int).Sqrt(disc)
+	if new(big.Int).Mul(root, root).Cmp(disc) != 0 {
+		return nil, nil, nil, false
+	}
+
+	p = new(big.Int).Add(s, root)
+	p.Rsh(p, 1)
+	q = new(big.Int).Sub(s, root)
+	q.Rsh(q, 1)
+	if q.Sign() <= 0 || new(big.Int).Mul(p, q).Cmp(n) != 0 {
+		return nil, nil, nil, false
+	}
+	return p, q, phi, true
+}
+
+// GenerateVulnerableKey - ключ RSA с d < N^(1/4)/3 и q < p < 2q (условия теоремы
+// Винера). Только для демонстраций и тестов.
+func GenerateVulnerableKey(bits int) (*rsa.PrivateKey, error) {
+	if bits < 64 || bits%2 != 0 {
+		return nil, fmt.Errorf("wiener: key size must be even and >= 64 bits, got %d", bits)
+	}
+
+	for {
+		p, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rand.Reader, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		if p.Cmp(q) < 0 {
+			p, q = q, p
+		}
+		if p.Cmp(new(big.Int).Lsh(q, 1)) >= 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(p, q)
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, bigOne), new(big.Int).Sub(q, bigOne))
+
+		// граница N^(1/4)/3
+		bound := new(big.Int).Sqrt(new(big.Int).Sqrt(n))
+		bound.Div(bound, big.NewInt(3))
+
+		d, err := rand.Int(rand.Reader, bound)
+		if err != nil {
+			return nil, err
+		}
+		d.SetBit(d, 0, 1)
+		if d.Cmp(bigOne) <= 0 || d.Cmp(bound) >= 0 {
+			continue
+		}
+		e := new(big.Int).ModInverse(d, phi)
+		if e == nil {
+			continue
+		}
+		return rsa.NewPrivateKey(p, q, e, d)
+	}
+}
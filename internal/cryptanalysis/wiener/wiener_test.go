@@ -0,0 +1,77 @@
+package wiener
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"crypto-lab/internal/asymmetric/rsa"
+)
+
+// классический пример: N = 90581 = 379 * 239, e = 17993, d = 5
+func TestAttackTextbookExample(t *testing.T) {
+	res, err := Attack(big.NewInt(17993), big.NewInt(90581))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.D.Int64() != 5 || res.P.Int64() != 379 || res.Q.Int64() != 239 {
+		t.Errorf("expected d=5, p=379, q=239, got d=%v, p=%v, q=%v", res.D, res.P, res.Q)
+	}
+
+	// e/N = [0; 5, 29, 4, ...]: подходящие 0/1, 1/5, 29/146, ... ; d = 5 - вторая
+	if len(res.Convergents) != 2 {
+		t.Fatalf("expected 2 convergents tried, got %d", len(res.Convergents))
+	}
+	if c := res.Convergents[1]; c.K.Int64() != 1 || c.D.Int64() != 5 {
+		t.Errorf("expected convergent 1/5, got %v/%v", c.K, c.D)
+	}
+}
+
+func TestAttackRecoversVulnerableKeys(t *testing.T) {
+	for _, bits := range []int{256, 512, 1024} {
+		priv, err := GenerateVulnerableKey(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv.N.BitLen() != bits {
+			t.Errorf("expected %d-bit modulus, got %d", bits, priv.N.BitLen())
+		}
+
+		res, err := Attack(priv.E, priv.N)
+		if err != nil {
+			t.Fatalf("%d bits: %v", bits, err)
+		}
+		if res.D.Cmp(priv.D) != 0 {
+			t.Errorf("%d bits: recovered d = %v, expected %v", bits, res.D, priv.D)
+		}
+		if new(big.Int).Mul(res.P, res.Q).Cmp(priv.N) != 0 {
+			t.Errorf("%d bits: p*q != N", bits)
+		}
+
+		// восстановленный ключ расшифровывает
+		recovered, err := rsa.NewPrivateKey(res.P, res.Q, priv.E, res.D)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := big.NewInt(424242)
+		c, _ := rsa.EncryptRaw(&priv.PublicKey, m)
+		if got, _ := rsa.DecryptRaw(recovered, c); got.Cmp(m) != 0 {
+			t.Errorf("%d bits: recovered key does not decrypt", bits)
+		}
+	}
+}
+
+// ключи обычного генератора защищены: атака перебирает все подходящие дроби и сдается
+func TestAttackFailsOnSafeKey(t *testing.T) {
+	priv, err := rsa.GenerateKey(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Attack(priv.E, priv.N)
+	if !errors.Is(err, ErrNotVulnerable) {
+		t.Fatalf("expected ErrNotVulnerable, got %v", err)
+	}
+	if res == nil || len(res.Convergents) == 0 {
+		t.Errorf("failed attack must still report tried convergents")
+	}
+}
@@ -0,0 +1,161 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	stdhash "hash"
+	"strings"
+	"testing"
+)
+
+const (
+	msg448 = "abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq"
+	msg896 = "abcdefghbcdefghicdefghijdefghijkefghijklfghijklmghijklmnhijklmnoijklmnopjklmnopqklmnopqrlmnopqrsmnopqrstnopqrstu"
+)
+
+// NIST FIPS 180-4, примеры из CSRC (SHA_All.pdf)
+func TestSHA2KnownVectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		newHash  func() stdhash.Hash
+		message  string
+		expected string
+	}{
+		{"SHA-224 abc", NewSHA224, "abc",
+			"23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7"},
+		{"SHA-224 448", NewSHA224, msg448,
+			"75388b16512776cc5dba5da1fd890150b0c6455cb4f58b1952522525"},
+		{"SHA-256 empty", NewSHA256, "",
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"SHA-256 abc", NewSHA256, "abc",
+			"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"SHA-256 448", NewSHA256, msg448,
+			"248d6a61d20638b8e5c026930c3e6039a33ce45964ff2167f6ecedd419db06c1"},
+		{"SHA-384 abc", NewSHA384, "abc",
+			"cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed" +
+				"8086072ba1e7cc2358baeca134c825a7"},
+		{"SHA-384 896", NewSHA384, msg896,
+			"09330c33f71147e83d192fc782cd1b4753111b173b3b05d22fa08086e3b0f712" +
+				"fcc7c71a557e2db966c3e9fa91746039"},
+		{"SHA-512 abc", NewSHA512, "abc",
+			"ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a" +
+				"2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{"SHA-512 896", NewSHA512, msg896,
+			"8e959b75dae313da8cf4f72814fc143f8f7779c6eb9f7fa17299aeadb6889018" +
+				"501d289e4900f7e4331b99dec4b5433ac7d329eeb6dd26545e96e55b874be909"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.newHash()
+			h.Write([]byte(tt.message))
+			got := hex.EncodeToString(h.Sum(nil))
+			if got != tt.expected {
+				t.Errorf("expected %s\ngot      %s", tt.expected, got)
+			}
+			if h.Size()*2 != len(tt.expected) {
+				t.Errorf("Size() = %d, digest has %d bytes", h.Size(), len(tt.expected)/2)
+			}
+		})
+	}
+}
+
+func TestSHA2MillionA(t *testing.T) {
+	tests := []struct {
+		newHash  func() stdhash.Hash
+		expected string
+	}{
+		{NewSHA256, "cdc76e5c9914fb9281a1c7e284d73e67f1809a48a497200e046d39ccc7112cd0"},
+		{NewSHA512, "e718483d0ce769644e2e42c7bc15b4638e1f98b13b2044285632a803afa973eb" +
+			"de0ff244877ea60a4cb0432ce577c31beb009c5c2c49aa2e4eadb217ad8cc09b"},
+	}
+
+	chunk := []byte(strings.Repeat("a", 1000))
+	for _, tt := range tests {
+		h := tt.newHash()
+		for i := 0; i < 1000; i++ {
+			h.Write(chunk)
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.expected {
+			t.Errorf("expected %s\ngot      %s", tt.expected, got)
+		}
+	}
+}
+
+// потоковая запись кусками любой длины и граничные длины дополнения
+// должны совпадать со стандартной библиотекой
+func TestStreamingMatchesStdlib(t *testing.T) {
+	pairs := []struct {
+		name string
+		ours func() stdhash.Hash
+		std  func() stdhash.Hash
+	}{
+		{"SHA-224", NewSHA224, sha256.New224},
+		{"SHA-256", NewSHA256, sha256.New},
+		{"SHA-384", NewSHA384, sha512.New384},
+		{"SHA-512", NewSHA512, sha512.New},
+	}
+
+	data := make([]byte, 600)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	for _, p := range pairs {
+		t.Run(p.name, func(t *testing.T) {
+			for length := 0; length <= 300; length++ {
+				std := p.std()
+				std.Write(data[:length])
+				expected := std.Sum(nil)
+
+				h := p.ours()
+				for off, step := 0, 1; off < length; off, step = off+step, step%13+1 {
+					end := min(off+step, length)
+					h.Write(data[off:end])
+				}
+				if got := h.Sum(nil); !bytes.Equal(got, expected) {
+					t.Fatalf("length %d: expected %x, got %x", length, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSumDoesNotChangeState(t *testing.T) {
+	h := NewSHA256()
+	h.Write([]byte("ab"))
+	first := h.Sum(nil)
+	if !bytes.Equal(first, h.Sum(nil)) {
+		t.Errorf("repeated Sum must return the same digest")
+	}
+	h.Write([]byte("c"))
+	if !bytes.Equal(h.Sum(nil), Sum256([]byte("abc"))) {
+		t.Errorf("writing after Sum must continue the message")
+	}
+
+	h.Reset()
+	if !bytes.Equal(h.Sum(nil), Sum256(nil)) {
+		t.Errorf("Reset must return to the empty message")
+	}
+
+	prefix := []byte("prefix")
+	if got := h.Sum(prefix); !bytes.Equal(got[:len(prefix)], prefix) {
+		t.Errorf("Sum must append to the given slice")
+	}
+}
+
+// хеши библиотеки совместимы с crypto/hmac
+func TestHMACCompatibility(t *testing.T) {
+	key := []byte("key")
+	msg := []byte("The quick brown fox jumps over the lazy dog")
+
+	ours := hmac.New(NewSHA256, key)
+	ours.Write(msg)
+	expected := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got := hex.EncodeToString(ours.Sum(nil)); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,12 @@
+package hash
+
+// 3.1 - функция сжатия конструкции Меркла–Дамгора: хранит цепочечное
+// значение и обновляет его очередным блоком сообщения
+type CompressionFunction interface {
+	Compress(block []byte)
+	ChainingValue() []byte // текущее состояние, big-endian
+	Reset()                // возврат к начальному значению (IV)
+	Clone() CompressionFunction
+	GetBlockSize() int
+	GetLengthSize() int // размер поля длины в дополнении: 8 для SHA-256, 16 для SHA-512
+}
@@ -0,0 +1,90 @@
+package hash
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// MerkleDamgard - потоковая хеш-функция поверх произвольной функции сжатия.
+// Дополнение: бит 1, нули, длина сообщения в битах (big-endian).
+type MerkleDamgard struct {
+	f      CompressionFunction
+	size   int
+	buf    []byte
+	n      int    // заполнено байт в buf
+	length uint64 // обработано байт сообщения
+}
+
+var _ hash.Hash = (*MerkleDamgard)(nil)
+
+// NewMerkleDamgard: size - длина дайджеста; для усеченных вариантов
+// (SHA-224, SHA-384) меньше цепочечного значения
+func NewMerkleDamgard(f CompressionFunction, size int) *MerkleDamgard {
+	f.Reset()
+	return &MerkleDamgard{
+		f:    f,
+		size: size,
+		buf:  make([]byte, f.GetBlockSize()),
+	}
+}
+
+func (d *MerkleDamgard) Write(p []byte) (int, error) {
+	written := len(p)
+	d.length += uint64(written)
+
+	if d.n > 0 {
+		k := copy(d.buf[d.n:], p)
+		d.n += k
+		p = p[k:]
+		if d.n < len(d.buf) {
+			return written, nil
+		}
+		d.f.Compress(d.buf)
+		d.n = 0
+	}
+
+	blockSize := len(d.buf)
+	for len(p) >= blockSize {
+		d.f.Compress(p[:blockSize])
+		p = p[blockSize:]
+	}
+	d.n = copy(d.buf, p)
+	return written, nil
+}
+
+// Sum дописывает дайджест к b; состояние не меняется, запись можно продолжать
+func (d *MerkleDamgard) Sum(b []byte) []byte {
+	f := d.f.Clone()
+	blockSize := len(d.buf)
+	lengthSize := f.GetLengthSize()
+
+	// хвост + 0x80 + нули + длина, кратно размеру блока
+	padded := d.n + 1 + lengthSize
+	if r := padded % blockSize; r != 0 {
+		padded += blockSize - r
+	}
+	tail := make([]byte, padded)
+	copy(tail, d.buf[:d.n])
+	tail[d.n] = 0x80
+
+	// длина в битах; старшие биты сверх 64 нужны только для поля в 16 байт
+	bits := tail[padded-lengthSize:]
+	binary.BigEndian.PutUint64(bits[lengthSize-8:], d.length<<3)
+	if lengthSize > 8 {
+		binary.BigEndian.PutUint64(bits[lengthSize-16:], d.length>>61)
+	}
+
+	for i := 0; i < padded; i += blockSize {
+		f.Compress(tail[i : i+blockSize])
+	}
+	return append(b, f.ChainingValue()[:d.size]...)
+}
+
+func (d *MerkleDamgard) Reset() {
+	d.f.Reset()
+	d.n = 0
+	d.length = 0
+}
+
+func (d *MerkleDamgard) Size() int      { return d.size }
+func (d *MerkleDamgard) BlockSize() int { return len(d.buf) }
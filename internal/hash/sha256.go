@@ -0,0 +1,100 @@
+package hash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	SHA224Size      = 28
+	SHA256Size      = 32
+	SHA256BlockSize = 64
+)
+
+// sha256Compression - функция сжатия SHA-224/256 (FIPS 180-4, 6.2.2)
+type sha256Compression struct {
+	h  [8]uint32
+	iv [8]uint32
+}
+
+var _ CompressionFunction = (*sha256Compression)(nil)
+
+func NewSHA256Compression() CompressionFunction {
+	return &sha256Compression{iv: sha256IV}
+}
+
+func NewSHA224Compression() CompressionFunction {
+	return &sha256Compression{iv: sha224IV}
+}
+
+func NewSHA256() hash.Hash {
+	return NewMerkleDamgard(NewSHA256Compression(), SHA256Size)
+}
+
+func NewSHA224() hash.Hash {
+	return NewMerkleDamgard(NewSHA224Compression(), SHA224Size)
+}
+
+func Sum256(data []byte) []byte {
+	d := NewSHA256()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func Sum224(data []byte) []byte {
+	d := NewSHA224()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func (c *sha256Compression) Compress(block []byte) {
+	var w [64]uint32
+	for t := 0; t < 16; t++ {
+		w[t] = binary.BigEndian.Uint32(block[4*t:])
+	}
+	for t := 16; t < 64; t++ {
+		s0 := bits.RotateLeft32(w[t-15], -7) ^ bits.RotateLeft32(w[t-15], -18) ^ (w[t-15] >> 3)
+		s1 := bits.RotateLeft32(w[t-2], -17) ^ bits.RotateLeft32(w[t-2], -19) ^ (w[t-2] >> 10)
+		w[t] = w[t-16] + s0 + w[t-7] + s1
+	}
+
+	a, b, cc, d, e, f, g, h := c.h[0], c.h[1], c.h[2], c.h[3], c.h[4], c.h[5], c.h[6], c.h[7]
+	for t := 0; t < 64; t++ {
+		sum1 := bits.RotateLeft32(e, -6) ^ bits.RotateLeft32(e, -11) ^ bits.RotateLeft32(e, -25)
+		ch := (e & f) ^ (^e & g)
+		t1 := h + sum1 + ch + sha256K[t] + w[t]
+		sum0 := bits.RotateLeft32(a, -2) ^ bits.RotateLeft32(a, -13) ^ bits.RotateLeft32(a, -22)
+		maj := (a & b) ^ (a & cc) ^ (b & cc)
+		t2 := sum0 + maj
+
+		h, g, f, e, d, cc, b, a = g, f, e, d+t1, cc, b, a, t1+t2
+	}
+
+	c.h[0] += a
+	c.h[1] += b
+	c.h[2] += cc
+	c.h[3] += d
+	c.h[4] += e
+	c.h[5] += f
+	c.h[6] += g
+	c.h[7] += h
+}
+
+func (c *sha256Compression) ChainingValue() []byte {
+	out := make([]byte, 32)
+	for i, v := range c.h {
+		binary.BigEndian.PutUint32(out[4*i:], v)
+	}
+	return out
+}
+
+func (c *sha256Compression) Reset() { c.h = c.iv }
+
+func (c *sha256Compression) Clone() CompressionFunction {
+	clone := *c
+	return &clone
+}
+
+func (c *sha256Compression) GetBlockSize() int  { return SHA256BlockSize }
+func (c *sha256Compression) GetLengthSize() int { return 8 }
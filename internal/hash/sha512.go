@@ -0,0 +1,100 @@
+package hash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	SHA384Size      = 48
+	SHA512Size      = 64
+	SHA512BlockSize = 128
+)
+
+// sha512Compression - функция сжатия SHA-384/512 (FIPS 180-4, 6.4.2)
+type sha512Compression struct {
+	h  [8]uint64
+	iv [8]uint64
+}
+
+var _ CompressionFunction = (*sha512Compression)(nil)
+
+func NewSHA512Compression() CompressionFunction {
+	return &sha512Compression{iv: sha512IV}
+}
+
+func NewSHA384Compression() CompressionFunction {
+	return &sha512Compression{iv: sha384IV}
+}
+
+func NewSHA512() hash.Hash {
+	return NewMerkleDamgard(NewSHA512Compression(), SHA512Size)
+}
+
+func NewSHA384() hash.Hash {
+	return NewMerkleDamgard(NewSHA384Compression(), SHA384Size)
+}
+
+func Sum512(data []byte) []byte {
+	d := NewSHA512()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func Sum384(data []byte) []byte {
+	d := NewSHA384()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+func (c *sha512Compression) Compress(block []byte) {
+	var w [80]uint64
+	for t := 0; t < 16; t++ {
+		w[t] = binary.BigEndian.Uint64(block[8*t:])
+	}
+	for t := 16; t < 80; t++ {
+		s0 := bits.RotateLeft64(w[t-15], -1) ^ bits.RotateLeft64(w[t-15], -8) ^ (w[t-15] >> 7)
+		s1 := bits.RotateLeft64(w[t-2], -19) ^ bits.RotateLeft64(w[t-2], -61) ^ (w[t-2] >> 6)
+		w[t] = w[t-16] + s0 + w[t-7] + s1
+	}
+
+	a, b, cc, d, e, f, g, h := c.h[0], c.h[1], c.h[2], c.h[3], c.h[4], c.h[5], c.h[6], c.h[7]
+	for t := 0; t < 80; t++ {
+		sum1 := bits.RotateLeft64(e, -14) ^ bits.RotateLeft64(e, -18) ^ bits.RotateLeft64(e, -41)
+		ch := (e & f) ^ (^e & g)
+		t1 := h + sum1 + ch + sha512K[t] + w[t]
+		sum0 := bits.RotateLeft64(a, -28) ^ bits.RotateLeft64(a, -34) ^ bits.RotateLeft64(a, -39)
+		maj := (a & b) ^ (a & cc) ^ (b & cc)
+		t2 := sum0 + maj
+
+		h, g, f, e, d, cc, b, a = g, f, e, d+t1, cc, b, a, t1+t2
+	}
+
+	c.h[0] += a
+	c.h[1] += b
+	c.h[2] += cc
+	c.h[3] += d
+	c.h[4] += e
+	c.h[5] += f
+	c.h[6] += g
+	c.h[7] += h
+}
+
+func (c *sha512Compression) ChainingValue() []byte {
+	out := make([]byte, 64)
+	for i, v := range c.h {
+		binary.BigEndian.PutUint64(out[8*i:], v)
+	}
+	return out
+}
+
+func (c *sha512Compression) Reset() { c.h = c.iv }
+
+func (c *sha512Compression) Clone() CompressionFunction {
+	clone := *c
+	return &clone
+}
+
+func (c *sha512Compression) GetBlockSize() int  { return SHA512BlockSize }
+func (c *sha512Compression) GetLengthSize() int { return 16 }
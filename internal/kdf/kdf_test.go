@@ -0,0 +1,142 @@
+package kdf
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+)
+
+// RFC 6070: PBKDF2-HMAC-SHA1
+func TestPBKDF2RFC6070(t *testing.T) {
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		keyLen     int
+		expected   string
+	}{
+		{"password", "salt", 1, 20, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{"password", "salt", 2, 20, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{"password", "salt", 4096, 20, "4b007901b765489abead49d926f721d065a429c1"},
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 25,
+			"3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{"pass\x00word", "sa\x00lt", 4096, 16, "56fa6aa75548099dcc37d7f03425e0c3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			key, err := PBKDF2(sha1.New, []byte(tt.password), []byte(tt.salt), tt.iterations, tt.keyLen)
+			if err != nil {
+				t.Fatalf("PBKDF2 failed: %v", err)
+			}
+			if hex.EncodeToString(key) != tt.expected {
+				t.Errorf("expected %s, got %x", tt.expected, key)
+			}
+		})
+	}
+}
+
+// RFC 7914, раздел 12
+func TestScryptRFC7914(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		n, r, p        int
+		expected       string
+	}{
+		{"", "", 16, 1, 1,
+			"77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16,
+			"fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+	}
+
+	for _, tt := range tests {
+		key, err := Scrypt([]byte(tt.password), []byte(tt.salt), tt.n, tt.r, tt.p, 64)
+		if err != nil {
+			t.Fatalf("Scrypt failed: %v", err)
+		}
+		if hex.EncodeToString(key) != tt.expected {
+			t.Errorf("N=%d: expected %s\ngot %x", tt.n, tt.expected, key)
+		}
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	if _, err := PBKDF2(sha256.New, []byte("p"), []byte("s"), 0, 16); err == nil {
+		t.Errorf("expected error for 0 iterations")
+	}
+	if _, err := PBKDF2(sha256.New, []byte("p"), []byte("s"), 1, 0); err == nil {
+		t.Errorf("expected error for zero key length")
+	}
+	if _, err := Scrypt([]byte("p"), []byte("s"), 1000, 1, 1, 16); err == nil {
+		t.Errorf("expected error for N not a power of two")
+	}
+	if _, err := DeriveDEALKey(NewPBKDF2Deriver(sha256.New, 1), []byte("p"), []byte("s"), 20); err == nil {
+		t.Errorf("expected error for DEAL key size 20")
+	}
+}
+
+func TestDeriveKeysForCiphers(t *testing.T) {
+	derivers := map[string]KeyDeriver{
+		"PBKDF2": NewPBKDF2Deriver(sha256.New, 1000),
+		"scrypt": NewScryptDeriver(16, 1, 1),
+	}
+	password := []byte("correct horse battery staple")
+	salt := []byte("0123456789abcdef")
+
+	for name, d := range derivers {
+		t.Run(name, func(t *testing.T) {
+			desKey, err := DeriveDESKey(d, password, salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(desKey) != 8 || !HasDESParity(desKey) {
+				t.Errorf("bad DES key %x", desKey)
+			}
+			if err := des.NewDES().SetSymmetricKey(desKey); err != nil {
+				t.Errorf("DES rejected derived key: %v", err)
+			}
+
+			tdesKey, err := DeriveTripleDESKey(d, password, salt, 24)
+			if err != nil || !HasDESParity(tdesKey) {
+				t.Errorf("bad 3DES key %x: %v", tdesKey, err)
+			}
+
+			for _, size := range []int{16, 24, 32} {
+				key, err := DeriveDEALKey(d, password, salt, size)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cipher, _ := deal.NewDEALCipher(size)
+				if err := cipher.SetSymmetricKey(key); err != nil {
+					t.Errorf("DEAL-%d rejected derived key: %v", size*8, err)
+				}
+			}
+
+			// детерминированность и зависимость от соли
+			again, _ := DeriveDESKey(d, password, salt)
+			other, _ := DeriveDESKey(d, password, []byte("another salt"))
+			if !bytes.Equal(desKey, again) || bytes.Equal(desKey, other) {
+				t.Errorf("derivation must be deterministic and salt-dependent")
+			}
+		})
+	}
+}
+
+func TestSetDESParity(t *testing.T) {
+	key, _ := hex.DecodeString("0000000000000000")
+	SetDESParity(key)
+	if hex.EncodeToString(key) != "0101010101010101" {
+		t.Errorf("expected 0101010101010101, got %x", key)
+	}
+
+	key, _ = hex.DecodeString("133457799BBCDFF1") // уже с нечетностью
+	SetDESParity(key)
+	if hex.EncodeToString(key) != "133457799bbcdff1" {
+		t.Errorf("parity must not change a valid key, got %x", key)
+	}
+}
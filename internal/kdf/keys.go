@@ -0,0 +1,66 @@
+package kdf
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// KeyDeriver - общий интерфейс для PBKDF2 и scrypt
+type KeyDeriver interface {
+	DeriveKey(password, salt []byte, keyLen int) ([]byte, error)
+}
+
+// ключ DES: 8 байт с выставленными битами нечетности
+func DeriveDESKey(d KeyDeriver, password, salt []byte) ([]byte, error) {
+	key, err := d.DeriveKey(password, salt, 8)
+	if err != nil {
+		return nil, fmt.Errorf("derive DES key: %w", err)
+	}
+	SetDESParity(key)
+	return key, nil
+}
+
+// ключ 3DES: 16 (EDE2) или 24 (EDE3) байта, биты нечетности в каждом байте
+func DeriveTripleDESKey(d KeyDeriver, password, salt []byte, keySize int) ([]byte, error) {
+	if keySize != 16 && keySize != 24 {
+		return nil, fmt.Errorf("invalid 3DES key size (%d)", keySize)
+	}
+	key, err := d.DeriveKey(password, salt, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive 3DES key: %w", err)
+	}
+	SetDESParity(key)
+	return key, nil
+}
+
+// ключ DEAL: 16, 24 или 32 байта
+func DeriveDEALKey(d KeyDeriver, password, salt []byte, keySize int) ([]byte, error) {
+	if keySize != 16 && keySize != 24 && keySize != 32 {
+		return nil, fmt.Errorf("invalid deal key size (%d)", keySize)
+	}
+	key, err := d.DeriveKey(password, salt, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive DEAL key: %w", err)
+	}
+	return key, nil
+}
+
+// младший бит каждого байта дополняет число единиц до нечетного (FIPS 46-3)
+func SetDESParity(key []byte) {
+	for i, b := range key {
+		b &= 0xFE
+		if bits.OnesCount8(b)%2 == 0 {
+			b |= 1
+		}
+		key[i] = b
+	}
+}
+
+func HasDESParity(key []byte) bool {
+	for _, b := range key {
+		if bits.OnesCount8(b)%2 == 0 {
+			return false
+		}
+	}
+	return true
+}
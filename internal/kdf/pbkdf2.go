@@ -0,0 +1,72 @@
+package kdf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"crypto-lab/internal/mac"
+)
+
+// PBKDF2 (RFC 8018, 5.2) с HMAC над произвольным хешем:
+// T_i = U_1 XOR U_2 XOR ... XOR U_c, U_1 = PRF(P, S || INT(i)), U_j = PRF(P, U_{j-1})
+func PBKDF2(hashFunc func() hash.Hash, password, salt []byte, iterations, keyLen int) ([]byte, error) {
+	if hashFunc == nil {
+		return nil, fmt.Errorf("pbkdf2: hash function is nil")
+	}
+	if iterations < 1 {
+		return nil, fmt.Errorf("pbkdf2: iterations must be >= 1, got %d", iterations)
+	}
+	if keyLen < 1 {
+		return nil, fmt.Errorf("pbkdf2: key length must be >= 1, got %d", keyLen)
+	}
+
+	prf, err := mac.NewHMAC(hashFunc, password)
+	if err != nil {
+		return nil, fmt.Errorf("pbkdf2: %w", err)
+	}
+	hLen := prf.Size()
+	blocks := (keyLen + hLen - 1) / hLen
+
+	key := make([]byte, 0, blocks*hLen)
+	var counter [4]byte
+	u := make([]byte, hLen)
+	t := make([]byte, hLen)
+
+	for i := 1; i <= blocks; i++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(i))
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+
+		for j := 1; j < iterations; j++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for k := range t {
+				t[k] ^= u[k]
+			}
+		}
+		key = append(key, t...)
+	}
+
+	return key[:keyLen], nil
+}
+
+type PBKDF2Deriver struct {
+	Hash       func() hash.Hash
+	Iterations int
+}
+
+func NewPBKDF2Deriver(hashFunc func() hash.Hash, iterations int) *PBKDF2Deriver {
+	return &PBKDF2Deriver{Hash: hashFunc, Iterations: iterations}
+}
+
+func (d *PBKDF2Deriver) DeriveKey(password, salt []byte, keyLen int) ([]byte, error) {
+	return PBKDF2(d.Hash, password, salt, d.Iterations, keyLen)
+}
+
+var _ KeyDeriver = (*PBKDF2Deriver)(nil)
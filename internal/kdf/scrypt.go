@@ -0,0 +1,139 @@
+package kdf
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// scrypt (RFC 7914): память ~ 128*r*N байт, N - степень двойки
+func Scrypt(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("scrypt: N must be a power of two > 1, got %d", n)
+	}
+	if r < 1 || p < 1 {
+		return nil, fmt.Errorf("scrypt: r and p must be >= 1, got r=%d p=%d", r, p)
+	}
+	if uint64(r)*uint64(p) >= 1<<30 || r > (1<<31-1)/128/p || n > (1<<31-1)/128/r {
+		return nil, fmt.Errorf("scrypt: parameters too large (N=%d r=%d p=%d)", n, r, p)
+	}
+
+	blockLen := 128 * r
+	b, err := PBKDF2(sha256.New, password, salt, 1, p*blockLen)
+	if err != nil {
+		return nil, err
+	}
+
+	x := make([]uint32, 32*r)
+	v := make([]uint32, 32*r*n)
+	y := make([]uint32, 32*r)
+
+	for i := 0; i < p; i++ {
+		roMix(b[i*blockLen:(i+1)*blockLen], r, n, x, y, v)
+	}
+
+	return PBKDF2(sha256.New, password, b, 1, keyLen)
+}
+
+// ROMix: последовательно заполняем V, затем читаем V в псевдослучайном порядке
+func roMix(block []byte, r, n int, x, y, v []uint32) {
+	words := 32 * r
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+
+	for i := 0; i < n; i++ {
+		copy(v[i*words:], x)
+		blockMix(x, y, r)
+	}
+	for i := 0; i < n; i++ {
+		// Integerify: первое слово последнего 64-байтного подблока
+		j := int(x[(2*r-1)*16] & uint32(n-1))
+		for k := range x {
+			x[k] ^= v[j*words+k]
+		}
+		blockMix(x, y, r)
+	}
+
+	for i := range x {
+		binary.LittleEndian.PutUint32(block[4*i:], x[i])
+	}
+}
+
+// BlockMix на Salsa20/8; выход: четные подблоки, затем нечетные
+func blockMix(b, y []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], b[(2*r-1)*16:])
+
+	for i := 0; i < 2*r; i++ {
+		for j := 0; j < 16; j++ {
+			x[j] ^= b[i*16+j]
+		}
+		salsa208(&x)
+		// Y_i -> позиция (i/2) или (r + i/2)
+		dst := (i / 2) * 16
+		if i%2 == 1 {
+			dst = (r + i/2) * 16
+		}
+		copy(y[dst:], x[:])
+	}
+	copy(b, y)
+}
+
+func salsa208(b *[16]uint32) {
+	x := *b
+	for i := 0; i < 8; i += 2 {
+		// столбцы
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+		// строки
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
+
+type ScryptDeriver struct {
+	N, R, P int
+}
+
+func NewScryptDeriver(n, r, p int) *ScryptDeriver {
+	return &ScryptDeriver{N: n, R: r, P: p}
+}
+
+func (d *ScryptDeriver) DeriveKey(password, salt []byte, keyLen int) ([]byte, error) {
+	return Scrypt(password, salt, d.N, d.R, d.P, keyLen)
+}
+
+var _ KeyDeriver = (*ScryptDeriver)(nil)
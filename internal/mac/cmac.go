@@ -0,0 +1,146 @@
+package mac
+
+import (
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// UnsupportedBlockSizeError - для блока этой длины не задан многочлен удвоения подключей
+type UnsupportedBlockSizeError struct {
+	BlockSize int
+}
+
+func (e UnsupportedBlockSizeError) Error() string {
+	return fmt.Sprintf("cmac: unsupported block size %d bytes (supported: 8, 16)", e.BlockSize)
+}
+
+// младший байт многочлена при удвоении в GF(2^n), NIST SP 800-38B, 5.3
+var cmacRb = map[int]byte{
+	8:  0x1B, // x^64 + x^4 + x^3 + x + 1
+	16: 0x87, // x^128 + x^7 + x^2 + x + 1
+}
+
+// CMAC (OMAC1, NIST SP 800-38B) над произвольным блочным шифром с уже
+// установленным ключом. Последний блок сообщения держится в буфере до Sum:
+// только тогда известно, полный он или требует дополнения.
+type CMAC struct {
+	cipher ciphers.SymmetricCipher
+	k1, k2 []byte
+	state  []byte // C_i - результат цепочки CBC
+	buf    []byte
+	n      int
+}
+
+var _ MAC = (*CMAC)(nil)
+
+func NewCMAC(cipher ciphers.SymmetricCipher) (*CMAC, error) {
+	if cipher == nil {
+		return nil, fmt.Errorf("cmac: cipher is nil")
+	}
+	blockSize := cipher.GetBlockSize()
+	rb, ok := cmacRb[blockSize]
+	if !ok {
+		return nil, UnsupportedBlockSizeError{BlockSize: blockSize}
+	}
+
+	// L = E_K(0^n); заодно проверяет, что ключ установлен
+	l, err := cipher.Encrypt(make([]byte, blockSize))
+	if err != nil {
+		return nil, fmt.Errorf("cmac: subkey generation failed: %w", err)
+	}
+	k1 := double(l, rb)
+	k2 := double(k1, rb)
+
+	return &CMAC{
+		cipher: cipher,
+		k1:     k1,
+		k2:     k2,
+		state:  make([]byte, blockSize),
+		buf:    make([]byte, blockSize),
+	}, nil
+}
+
+// double - умножение на x в GF(2^n) в big-endian представлении
+func double(in []byte, rb byte) []byte {
+	out := make([]byte, len(in))
+	var carry byte
+	for i := len(in) - 1; i >= 0; i-- {
+		out[i] = in[i]<<1 | carry
+		carry = in[i] >> 7
+	}
+	if carry != 0 {
+		out[len(out)-1] ^= rb
+	}
+	return out
+}
+
+func (c *CMAC) Write(p []byte) (int, error) {
+	written := len(p)
+	blockSize := len(c.buf)
+
+	for len(p) > 0 {
+		// полный буфер сжимаем, только когда пришли новые данные
+		if c.n == blockSize {
+			if err := c.chain(c.buf); err != nil {
+				return written - len(p), err
+			}
+			c.n = 0
+		}
+		k := copy(c.buf[c.n:], p)
+		c.n += k
+		p = p[k:]
+	}
+	return written, nil
+}
+
+func (c *CMAC) chain(block []byte) error {
+	for i := range c.state {
+		c.state[i] ^= block[i]
+	}
+	out, err := c.cipher.Encrypt(c.state)
+	if err != nil {
+		return fmt.Errorf("cmac: %w", err)
+	}
+	copy(c.state, out)
+	return nil
+}
+
+// Sum дописывает тег полной длины блока; состояние не меняется
+func (c *CMAC) Sum(b []byte) []byte {
+	blockSize := len(c.buf)
+	last := make([]byte, blockSize)
+	copy(last, c.buf[:c.n])
+
+	subkey := c.k1
+	if c.n < blockSize {
+		last[c.n] = 0x80
+		subkey = c.k2
+	}
+	for i := range last {
+		last[i] ^= subkey[i] ^ c.state[i]
+	}
+
+	tag, err := c.cipher.Encrypt(last)
+	if err != nil {
+		// шифр уже отработал на этом ключе в NewCMAC
+		panic(fmt.Sprintf("cmac: cipher failed after successful subkey generation: %v", err))
+	}
+	return append(b, tag...)
+}
+
+func (c *CMAC) Reset() {
+	clear(c.state)
+	c.n = 0
+}
+
+// Verify допускает усеченный тег (не короче 4 байт), SP 800-38B, 6.3
+func (c *CMAC) Verify(tag []byte) bool {
+	if len(tag) < 4 || len(tag) > len(c.buf) {
+		return false
+	}
+	return Equal(c.Sum(nil)[:len(tag)], tag)
+}
+
+func (c *CMAC) Size() int      { return len(c.buf) }
+func (c *CMAC) BlockSize() int { return len(c.buf) }
@@ -0,0 +1,72 @@
+package mac
+
+import (
+	"fmt"
+	"hash"
+)
+
+const (
+	ipad = 0x36
+	opad = 0x5c
+)
+
+// HMAC (RFC 2104): H((K ^ opad) || H((K ^ ipad) || m))
+type HMAC struct {
+	inner, outer hash.Hash
+	ipadKey      []byte
+	opadKey      []byte
+}
+
+var _ MAC = (*HMAC)(nil)
+
+// NewHMAC: ключ длиннее блока хеша предварительно хешируется
+func NewHMAC(newHash func() hash.Hash, key []byte) (*HMAC, error) {
+	if newHash == nil {
+		return nil, fmt.Errorf("hmac: hash function is nil")
+	}
+
+	h := &HMAC{inner: newHash(), outer: newHash()}
+	blockSize := h.inner.BlockSize()
+
+	if len(key) > blockSize {
+		h.outer.Write(key)
+		key = h.outer.Sum(nil)
+		h.outer.Reset()
+	}
+
+	h.ipadKey = make([]byte, blockSize)
+	h.opadKey = make([]byte, blockSize)
+	copy(h.ipadKey, key)
+	copy(h.opadKey, key)
+	for i := range h.ipadKey {
+		h.ipadKey[i] ^= ipad
+		h.opadKey[i] ^= opad
+	}
+
+	h.inner.Write(h.ipadKey)
+	return h, nil
+}
+
+func (h *HMAC) Write(p []byte) (int, error) {
+	return h.inner.Write(p)
+}
+
+func (h *HMAC) Sum(b []byte) []byte {
+	innerSum := h.inner.Sum(nil)
+	h.outer.Reset()
+	h.outer.Write(h.opadKey)
+	h.outer.Write(innerSum)
+	return h.outer.Sum(b)
+}
+
+func (h *HMAC) Reset() {
+	h.inner.Reset()
+	h.inner.Write(h.ipadKey)
+}
+
+func (h *HMAC) Verify(tag []byte) bool {
+	return Equal(h.Sum(nil), tag)
+}
+
+func (h *HMAC) Size() int      { return h.outer.Size() }
+func (h *HMAC) BlockSize() int { return h.inner.BlockSize() }
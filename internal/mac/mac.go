@@ -0,0 +1,18 @@
+package mac
+
+import (
+	"crypto/subtle"
+	"hash"
+)
+
+// MAC - потоковый код аутентификации: Write накапливает сообщение,
+// Sum дописывает тег, Verify сравнивает тег за постоянное время
+type MAC interface {
+	hash.Hash
+	Verify(tag []byte) bool
+}
+
+// Equal сравнивает теги за время, не зависящее от позиции первого расхождения
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
@@ -0,0 +1,203 @@
+package mac_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	stdhash "hash"
+	"strings"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/ciphers/rijndael"
+	"crypto-lab/internal/hash"
+	"crypto-lab/internal/mac"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+// RFC 4231, тесты 1, 2 и 6
+func TestHMACRFC4231(t *testing.T) {
+	largeKey := strings.Repeat("aa", 131)
+	tests := []struct {
+		name     string
+		newHash  func() stdhash.Hash
+		key      string
+		data     string
+		expected string
+	}{
+		{"SHA-224 case 1", hash.NewSHA224, strings.Repeat("0b", 20), "Hi There",
+			"896fb1128abbdf196832107cd49df33f47b4b1169912ba4f53684b22"},
+		{"SHA-256 case 1", hash.NewSHA256, strings.Repeat("0b", 20), "Hi There",
+			"b0344c61d8db38535ca8afceaf0bf12b881dc200c9833da726e9376c2e32cff7"},
+		{"SHA-384 case 1", hash.NewSHA384, strings.Repeat("0b", 20), "Hi There",
+			"afd03944d84895626b0825f4ab46907f15f9dadbe4101ec682aa034c7cebc59c" +
+				"faea9ea9076ede7f4af152e8b2fa9cb6"},
+		{"SHA-512 case 1", hash.NewSHA512, strings.Repeat("0b", 20), "Hi There",
+			"87aa7cdea5ef619d4ff0b4241a1d6cb02379f4e2ce4ec2787ad0b30545e17cde" +
+				"daa833b7d6b8a702038b274eaea3f4e4be9d914eeb61f1702e696c203a126854"},
+		{"SHA-256 case 2", hash.NewSHA256, hex.EncodeToString([]byte("Jefe")), "what do ya want for nothing?",
+			"5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"SHA-256 case 6", hash.NewSHA256, largeKey, "Test Using Larger Than Block-Size Key - Hash Key First",
+			"60e431591ee0b67f0d8a26aacbf5b77f8e0bc6213728c5140546040f0ee37f54"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := mac.NewHMAC(tt.newHash, unhex(t, tt.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.Write([]byte(tt.data))
+			if got := hex.EncodeToString(h.Sum(nil)); got != tt.expected {
+				t.Errorf("expected %s\ngot      %s", tt.expected, got)
+			}
+			if !h.Verify(unhex(t, tt.expected)) {
+				t.Errorf("Verify rejected a valid tag")
+			}
+		})
+	}
+}
+
+type cmacVector struct {
+	message  string
+	expected string
+}
+
+func checkCMAC(t *testing.T, cipher ciphers.SymmetricCipher, vectors []cmacVector) {
+	t.Helper()
+	c, err := mac.NewCMAC(cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		c.Reset()
+		c.Write(unhex(t, v.message))
+		if got := hex.EncodeToString(c.Sum(nil)); got != v.expected {
+			t.Errorf("message length %d: expected %s, got %s", len(v.message)/2, v.expected, got)
+		}
+	}
+}
+
+// RFC 4493, раздел 4 (AES-128)
+func TestCMACRFC4493(t *testing.T) {
+	aes, err := rijndael.NewAES(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := aes.SetSymmetricKey(unhex(t, "2b7e151628aed2a6abf7158809cf4f3c")); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCMAC(t, aes, []cmacVector{
+		{"", "bb1d6929e95937287fa37d129b756746"},
+		{"6bc1bee22e409f96e93d7e117393172a", "070a16b46b4d4144f79bdd9dd04a287c"},
+		{"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411",
+			"dfa66747de9ae63030ca32611497c827"},
+		{"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" +
+			"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710",
+			"51f0bebf7e3b9d92fc49741779363cfe"},
+	})
+}
+
+// NIST SP 800-38B, приложение D.4 (TDEA, три ключа)
+func TestCMACTripleDES(t *testing.T) {
+	tdes := des.NewTripleDES()
+	key := unhex(t, "8aa83bf8cbda10620bc1bf19fbb6cd58bc313d4a371ca8b5")
+	if err := tdes.SetSymmetricKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCMAC(t, tdes, []cmacVector{
+		{"", "b7a688e122ffaf95"},
+		{"6bc1bee22e409f96", "8e8f293136283797"},
+		{"6bc1bee22e409f96e93d7e117393172aae2d8a57", "743ddbe0ce2dc2ed"},
+		{"6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51", "33e6b1092400eae5"},
+	})
+}
+
+// DES и DEAL: векторов нет, проверяем потоковую запись и чувствительность тега
+func TestCMACStreaming(t *testing.T) {
+	desCipher := des.NewDES()
+	desCipher.SetSymmetricKey(unhex(t, "133457799BBCDFF1"))
+	dealCipher, _ := deal.NewDEALCipher(24)
+	dealCipher.SetSymmetricKey(bytes.Repeat([]byte{0x5A}, 24))
+
+	message := make([]byte, 100)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	for _, cipher := range []ciphers.SymmetricCipher{desCipher, dealCipher} {
+		c, err := mac.NewCMAC(cipher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for length := 0; length <= len(message); length++ {
+			c.Reset()
+			c.Write(message[:length])
+			expected := c.Sum(nil)
+
+			c.Reset()
+			for i := 0; i < length; i++ {
+				c.Write(message[i : i+1])
+			}
+			if !c.Verify(expected) {
+				t.Fatalf("block %d, length %d: byte-wise stream differs", cipher.GetBlockSize(), length)
+			}
+
+			// дополненное и полное сообщения не должны совпадать
+			padded := append(append([]byte{}, message[:length]...), 0x80)
+			c.Reset()
+			c.Write(padded)
+			if c.Verify(expected) {
+				t.Fatalf("length %d: tag collides with 10* padded message", length)
+			}
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	h, _ := mac.NewHMAC(hash.NewSHA256, []byte("key"))
+	h.Write([]byte("message"))
+	tag := h.Sum(nil)
+
+	tampered := append([]byte{}, tag...)
+	tampered[len(tampered)-1] ^= 1
+	if h.Verify(tampered) || h.Verify(tag[:16]) || h.Verify(nil) {
+		t.Errorf("HMAC Verify accepted a wrong tag")
+	}
+
+	aes, _ := rijndael.NewAES(16)
+	aes.SetSymmetricKey(make([]byte, 16))
+	c, _ := mac.NewCMAC(aes)
+	c.Write([]byte("message"))
+	full := c.Sum(nil)
+	if !c.Verify(full[:8]) {
+		t.Errorf("CMAC Verify must accept a truncated tag")
+	}
+	if c.Verify(full[:3]) {
+		t.Errorf("CMAC Verify must reject tags shorter than 4 bytes")
+	}
+}
+
+func TestCMACInvalidCipher(t *testing.T) {
+	if _, err := mac.NewCMAC(des.NewDES()); err == nil {
+		t.Errorf("expected error for cipher without key")
+	}
+
+	r, _ := rijndael.NewRijndaelCipher(16, 24)
+	r.SetSymmetricKey(make([]byte, 16))
+	_, err := mac.NewCMAC(r)
+	if _, ok := err.(mac.UnsupportedBlockSizeError); !ok {
+		t.Errorf("expected UnsupportedBlockSizeError, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package gf256
+
+import "fmt"
+
+// Элементы GF(2^8) - байты, бит i = коэффициент при x^i.
+// Модуль - многочлен степени 8, старший член x^8 подразумевается:
+// modulus 0x1B означает x^8 + x^4 + x^3 + x + 1 (модуль AES).
+
+// ReducibleModulusError - модуль приводим, GF(2^8) по нему не построить
+type ReducibleModulusError struct {
+	Modulus byte
+}
+
+func (e *ReducibleModulusError) Error() string {
+	return fmt.Sprintf("gf256: modulus 0x1%02X is reducible", e.Modulus)
+}
+
+// ZeroInverseError - у нуля нет обратного
+type ZeroInverseError struct{}
+
+func (e *ZeroInverseError) Error() string {
+	return "gf256: zero has no multiplicative inverse"
+}
+
+var irreducible [256]bool
+
+func init() {
+	for m := 0; m < 256; m++ {
+		irreducible[m] = checkIrreducible(0x100 | uint16(m))
+	}
+}
+
+func Add(a, b byte) byte {
+	return a ^ b
+}
+
+func Mul(a, b, modulus byte) (byte, error) {
+	if !irreducible[modulus] {
+		return 0, &ReducibleModulusError{Modulus: modulus}
+	}
+	return mul(a, b, modulus), nil
+}
+
+// a^n по модулю, быстрое возведение в степень
+func Pow(a byte, n uint, modulus byte) (byte, error) {
+	if !irreducible[modulus] {
+		return 0, &ReducibleModulusError{Modulus: modulus}
+	}
+	return pow(a, n, modulus), nil
+}
+
+// a^-1 = a^254, т.к. мультипликативная группа имеет порядок 255
+func Inverse(a, modulus byte) (byte, error) {
+	if !irreducible[modulus] {
+		return 0, &ReducibleModulusError{Modulus: modulus}
+	}
+	if a == 0 {
+		return 0, &ZeroInverseError{}
+	}
+	return pow(a, 254, modulus), nil
+}
+
+func IsIrreducible(modulus byte) bool {
+	return irreducible[modulus]
+}
+
+// все 30 неприводимых многочленов степени 8 (младшие 8 бит, x^8 подразумевается)
+func IrreduciblePolynomials() []byte {
+	result := make([]byte, 0, 30)
+	for m := 0; m < 256; m++ {
+		if irreducible[m] {
+			result = append(result, byte(m))
+		}
+	}
+	return result
+}
+
+func mul(a, b, modulus byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= modulus
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func pow(a byte, n uint, modulus byte) byte {
+	result := byte(1)
+	for n > 0 {
+		if n&1 != 0 {
+			result = mul(result, a, modulus)
+		}
+		a = mul(a, a, modulus)
+		n >>= 1
+	}
+	return result
+}
+
+// степень многочлена над GF(2), -1 для нуля
+func degree(p uint16) int {
+	d := -1
+	for p != 0 {
+		p >>= 1
+		d++
+	}
+	return d
+}
+
+// остаток от деления многочленов над GF(2)
+func polyMod(a, b uint16) uint16 {
+	db := degree(b)
+	for degree(a) >= db {
+		a ^= b << uint(degree(a)-db)
+	}
+	return a
+}
+
+// многочлен степени 8 приводим, если делится на какой-то многочлен степени 1..4
+func checkIrreducible(p uint16) bool {
+	for d := uint16(2); d < 32; d++ {
+		if polyMod(p, d) == 0 {
+			return false
+		}
+	}
+	return true
+}
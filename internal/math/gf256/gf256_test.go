@@ -0,0 +1,111 @@
+package gf256
+
+import (
+	"errors"
+	"testing"
+)
+
+const aesModulus = 0x1B
+
+func TestIrreduciblePolynomials(t *testing.T) {
+	polys := IrreduciblePolynomials()
+	if len(polys) != 30 {
+		t.Fatalf("Expected 30 irreducible polynomials, got %d", len(polys))
+	}
+
+	// модули AES (0x11B) и Twofish (0x169) должны быть в списке
+	for _, want := range []byte{0x1B, 0x69} {
+		if !IsIrreducible(want) {
+			t.Errorf("0x1%02X must be irreducible", want)
+		}
+	}
+
+	// x^8 + 1 = (x + 1)^8
+	if IsIrreducible(0x01) {
+		t.Errorf("0x101 must be reducible")
+	}
+}
+
+// FIPS-197, 4.2: {57} * {83} = {c1}, {57} * {13} = {fe}
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a, b, want byte
+	}{
+		{0x57, 0x83, 0xc1},
+		{0x57, 0x13, 0xfe},
+		{0x57, 0x01, 0x57},
+		{0x00, 0x83, 0x00},
+	}
+
+	for _, tt := range tests {
+		got, err := Mul(tt.a, tt.b, aesModulus)
+		if err != nil {
+			t.Fatalf("Mul failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("0x%02x * 0x%02x = 0x%02x, expected 0x%02x", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if Add(0x57, 0x83) != 0xd4 {
+		t.Errorf("0x57 + 0x83 must be 0xd4")
+	}
+}
+
+// a * a^-1 = 1 для всех ненулевых a по всем неприводимым модулям
+func TestInverse(t *testing.T) {
+	for _, m := range IrreduciblePolynomials() {
+		for a := 1; a < 256; a++ {
+			inv, err := Inverse(byte(a), m)
+			if err != nil {
+				t.Fatalf("Inverse(0x%02x, 0x1%02X) failed: %v", a, m, err)
+			}
+			if p := mul(byte(a), inv, m); p != 1 {
+				t.Fatalf("0x%02x * 0x%02x = 0x%02x mod 0x1%02X, expected 1", a, inv, p, m)
+			}
+		}
+	}
+
+	var zeroErr *ZeroInverseError
+	if _, err := Inverse(0, aesModulus); !errors.As(err, &zeroErr) {
+		t.Errorf("Expected ZeroInverseError, got %v", err)
+	}
+}
+
+func TestPow(t *testing.T) {
+	// 0x03 - порождающий элемент для модуля AES: порядок 255
+	got, err := Pow(0x03, 255, aesModulus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("0x03^255 = 0x%02x, expected 1", got)
+	}
+
+	got, _ = Pow(0x57, 2, aesModulus)
+	want := mul(0x57, 0x57, aesModulus)
+	if got != want {
+		t.Errorf("0x57^2 = 0x%02x, expected 0x%02x", got, want)
+	}
+
+	got, _ = Pow(0x57, 0, aesModulus)
+	if got != 1 {
+		t.Errorf("a^0 must be 1")
+	}
+}
+
+func TestReducibleModulus(t *testing.T) {
+	var redErr *ReducibleModulusError
+
+	if _, err := Mul(0x02, 0x03, 0x01); !errors.As(err, &redErr) {
+		t.Errorf("Mul: expected ReducibleModulusError, got %v", err)
+	} else if redErr.Modulus != 0x01 {
+		t.Errorf("Expected modulus 0x01 in error, got 0x%02x", redErr.Modulus)
+	}
+	if _, err := Inverse(0x02, 0x01); !errors.As(err, &redErr) {
+		t.Errorf("Inverse: expected ReducibleModulusError, got %v", err)
+	}
+	if _, err := Pow(0x02, 3, 0x01); !errors.As(err, &redErr) {
+		t.Errorf("Pow: expected ReducibleModulusError, got %v", err)
+	}
+}
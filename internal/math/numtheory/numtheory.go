@@ -0,0 +1,148 @@
+package numtheory
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Функции без состояния над math/big; аргументы не изменяются,
+// результат всегда в новом big.Int.
+
+var (
+	bigOne = big.NewInt(1)
+	bigTwo = big.NewInt(2)
+)
+
+// ErrNegativeExponent - отрицательная степень в ModExp
+var ErrNegativeExponent = errors.New("numtheory: negative exponent")
+
+// InvalidModulusError - модуль не подходит для операции
+type InvalidModulusError struct {
+	Modulus *big.Int
+	Reason  string
+}
+
+func (e *InvalidModulusError) Error() string {
+	return fmt.Sprintf("numtheory: invalid modulus %v: %s", e.Modulus, e.Reason)
+}
+
+// GCD - алгоритм Евклида, результат неотрицателен
+func GCD(a, b *big.Int) *big.Int {
+	x := new(big.Int).Abs(a)
+	y := new(big.Int).Abs(b)
+	for y.Sign() != 0 {
+		x.Mod(x, y)
+		x, y = y, x
+	}
+	return x
+}
+
+// ExtendedGCD возвращает d = gcd(a, b) и x, y: a*x + b*y = d
+func ExtendedGCD(a, b *big.Int) (d, x, y *big.Int) {
+	oldR, r := new(big.Int).Set(a), new(big.Int).Set(b)
+	oldX, x := big.NewInt(1), big.NewInt(0)
+	oldY, y := big.NewInt(0), big.NewInt(1)
+	q := new(big.Int)
+
+	for r.Sign() != 0 {
+		q.Quo(oldR, r)
+		// (oldR, r) = (r, oldR - q*r) и так же для коэффициентов
+		oldR, r = r, new(big.Int).Sub(oldR, new(big.Int).Mul(q, r))
+		oldX, x = x, new(big.Int).Sub(oldX, new(big.Int).Mul(q, x))
+		oldY, y = y, new(big.Int).Sub(oldY, new(big.Int).Mul(q, y))
+	}
+
+	if oldR.Sign() < 0 {
+		oldR.Neg(oldR)
+		oldX.Neg(oldX)
+		oldY.Neg(oldY)
+	}
+	return oldR, oldX, oldY
+}
+
+// ModInverse - обратный к a по модулю m через ExtendedGCD
+func ModInverse(a, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, &InvalidModulusError{Modulus: m, Reason: "must be positive"}
+	}
+	d, x, _ := ExtendedGCD(new(big.Int).Mod(a, m), m)
+	if d.Cmp(bigOne) != 0 {
+		return nil, fmt.Errorf("numtheory: %v is not invertible modulo %v", a, m)
+	}
+	return x.Mod(x, m), nil
+}
+
+// ModExp - возведение в степень по модулю, бинарный метод справа налево
+func ModExp(base, exp, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, &InvalidModulusError{Modulus: m, Reason: "must be positive"}
+	}
+	if exp.Sign() < 0 {
+		return nil, ErrNegativeExponent
+	}
+
+	result := big.NewInt(1)
+	result.Mod(result, m) // m = 1
+	b := new(big.Int).Mod(base, m)
+	for i := 0; i < exp.BitLen(); i++ {
+		if exp.Bit(i) == 1 {
+			result.Mul(result, b).Mod(result, m)
+		}
+		b.Mul(b, b).Mod(b, m)
+	}
+	return result, nil
+}
+
+// Legendre - символ Лежандра (a/p) по критерию Эйлера: a^((p-1)/2) mod p;
+// p - нечетное простое (простота не проверяется)
+func Legendre(a, p *big.Int) (int, error) {
+	if p.Cmp(bigTwo) <= 0 || p.Bit(0) == 0 {
+		return 0, &InvalidModulusError{Modulus: p, Reason: "must be an odd prime"}
+	}
+	exp := new(big.Int).Rsh(p, 1) // (p-1)/2 для нечетного p
+	r, err := ModExp(a, exp, p)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case r.Sign() == 0:
+		return 0, nil
+	case r.Cmp(bigOne) == 0:
+		return 1, nil
+	default:
+		return -1, nil
+	}
+}
+
+// Jacobi - символ Якоби (a/n) для нечетного n > 0, через квадратичный закон взаимности
+func Jacobi(a, n *big.Int) (int, error) {
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		return 0, &InvalidModulusError{Modulus: n, Reason: "must be odd and positive"}
+	}
+
+	x := new(big.Int).Mod(a, n)
+	y := new(big.Int).Set(n)
+	result := 1
+
+	for x.Sign() != 0 {
+		// выносим двойки: (2/y) = -1 при y = 3, 5 mod 8
+		twos := x.TrailingZeroBits()
+		x.Rsh(x, twos)
+		if twos%2 == 1 {
+			if r := y.Bits()[0] & 7; r == 3 || r == 5 {
+				result = -result
+			}
+		}
+		// взаимность: знак меняется, если x = y = 3 mod 4
+		if x.Bits()[0]&3 == 3 && y.Bits()[0]&3 == 3 {
+			result = -result
+		}
+		x, y = y.Mod(y, x), x
+	}
+
+	if y.Cmp(bigOne) == 0 {
+		return result, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,117 @@
+package numtheory
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestGCDAndExtendedGCD(t *testing.T) {
+	tests := []struct{ a, b, gcd int64 }{
+		{240, 46, 2}, {17, 5, 1}, {0, 7, 7}, {7, 0, 7}, {-12, 18, 6}, {1071, 462, 21},
+	}
+	for _, tt := range tests {
+		a, b := big.NewInt(tt.a), big.NewInt(tt.b)
+		if g := GCD(a, b); g.Int64() != tt.gcd {
+			t.Errorf("GCD(%d, %d) = %v, expected %d", tt.a, tt.b, g, tt.gcd)
+		}
+
+		d, x, y := ExtendedGCD(a, b)
+		if d.Int64() != tt.gcd {
+			t.Errorf("ExtendedGCD(%d, %d): d = %v, expected %d", tt.a, tt.b, d, tt.gcd)
+		}
+		// a*x + b*y = d
+		sum := new(big.Int).Add(new(big.Int).Mul(a, x), new(big.Int).Mul(b, y))
+		if sum.Cmp(d) != 0 {
+			t.Errorf("ExtendedGCD(%d, %d): %d*%v + %d*%v = %v, expected %v", tt.a, tt.b, tt.a, x, tt.b, y, sum, d)
+		}
+	}
+
+	a := big.NewInt(240)
+	GCD(a, big.NewInt(46))
+	if a.Int64() != 240 {
+		t.Errorf("GCD must not modify its arguments")
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	inv, err := ModInverse(big.NewInt(3), big.NewInt(11))
+	if err != nil || inv.Int64() != 4 {
+		t.Errorf("expected 4, got %v (%v)", inv, err)
+	}
+	if _, err := ModInverse(big.NewInt(6), big.NewInt(9)); err == nil {
+		t.Errorf("expected error for non-invertible element")
+	}
+}
+
+func TestModExpMatchesBigExp(t *testing.T) {
+	m := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	bases := []int64{0, 1, 2, 3, 12345678901, -5}
+	exps := []int64{0, 1, 2, 65537, 1 << 40}
+
+	for _, b := range bases {
+		for _, e := range exps {
+			base, exp := big.NewInt(b), big.NewInt(e)
+			got, err := ModExp(base, exp, m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := new(big.Int).Exp(new(big.Int).Mod(base, m), exp, m)
+			if got.Cmp(expected) != 0 {
+				t.Errorf("%d^%d: expected %v, got %v", b, e, expected, got)
+			}
+		}
+	}
+
+	if r, _ := ModExp(big.NewInt(5), big.NewInt(3), big.NewInt(1)); r.Sign() != 0 {
+		t.Errorf("anything mod 1 must be 0, got %v", r)
+	}
+	if _, err := ModExp(big.NewInt(2), big.NewInt(-1), big.NewInt(7)); err != ErrNegativeExponent {
+		t.Errorf("expected ErrNegativeExponent, got %v", err)
+	}
+	if _, err := ModExp(big.NewInt(2), big.NewInt(3), big.NewInt(0)); err == nil {
+		t.Errorf("expected error for zero modulus")
+	}
+}
+
+func TestLegendre(t *testing.T) {
+	// квадратичные вычеты по модулю 11: 1, 3, 4, 5, 9
+	residues := map[int64]bool{1: true, 3: true, 4: true, 5: true, 9: true}
+	p := big.NewInt(11)
+	for a := int64(0); a < 22; a++ {
+		got, err := Legendre(big.NewInt(a), p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := -1
+		if a%11 == 0 {
+			expected = 0
+		} else if residues[a%11] {
+			expected = 1
+		}
+		if got != expected {
+			t.Errorf("(%d/11) = %d, expected %d", a, got, expected)
+		}
+	}
+
+	if _, err := Legendre(big.NewInt(3), big.NewInt(2)); err == nil {
+		t.Errorf("expected error for p = 2")
+	}
+}
+
+func TestJacobiMatchesBigJacobi(t *testing.T) {
+	for n := int64(1); n < 200; n += 2 {
+		for a := int64(-30); a < 230; a++ {
+			got, err := Jacobi(big.NewInt(a), big.NewInt(n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := big.Jacobi(big.NewInt(a), big.NewInt(n)); got != expected {
+				t.Fatalf("(%d/%d) = %d, expected %d", a, n, got, expected)
+			}
+		}
+	}
+
+	if _, err := Jacobi(big.NewInt(3), big.NewInt(8)); err == nil {
+		t.Errorf("expected error for even n")
+	}
+}
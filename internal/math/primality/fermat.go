@@ -0,0 +1,25 @@
+package primality
+
+import (
+	"math/big"
+
+	"crypto-lab/internal/math/numtheory"
+)
+
+// Fermat: a^(n-1) = 1 mod n. Числа Кармайкла проходят для всех a, взаимно
+// простых с n, поэтому оценка 1/2 на раунд верна только для прочих составных.
+func NewFermatTest() PrimalityTest {
+	return probabilisticTest{rt: fermatRound{}}
+}
+
+type fermatRound struct{}
+
+func (fermatRound) roundError() float64 { return 0.5 }
+
+func (fermatRound) round(n, a *big.Int) bool {
+	if numtheory.GCD(a, n).Cmp(bigOne) != 0 {
+		return false
+	}
+	r, _ := numtheory.ModExp(a, new(big.Int).Sub(n, bigOne), n)
+	return r.Cmp(bigOne) == 0
+}
@@ -0,0 +1,38 @@
+package primality
+
+import (
+	"math/big"
+
+	"crypto-lab/internal/math/numtheory"
+)
+
+// Miller–Rabin: n-1 = 2^s * d; a^d = 1 или a^(2^r * d) = -1 при некотором r < s;
+// ошибка раунда <= 1/4
+func NewMillerRabinTest() PrimalityTest {
+	return probabilisticTest{rt: millerRabinRound{}}
+}
+
+type millerRabinRound struct{}
+
+func (millerRabinRound) roundError() float64 { return 0.25 }
+
+func (millerRabinRound) round(n, a *big.Int) bool {
+	nm1 := new(big.Int).Sub(n, bigOne)
+	s := nm1.TrailingZeroBits()
+	d := new(big.Int).Rsh(nm1, s)
+
+	x, _ := numtheory.ModExp(a, d, n)
+	if x.Cmp(bigOne) == 0 || x.Cmp(nm1) == 0 {
+		return true
+	}
+	for r := uint(1); r < s; r++ {
+		x.Mul(x, x).Mod(x, n)
+		if x.Cmp(nm1) == 0 {
+			return true
+		}
+		if x.Cmp(bigOne) == 0 {
+			return false
+		}
+	}
+	return false
+}
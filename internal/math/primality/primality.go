@@ -0,0 +1,68 @@
+package primality
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+)
+
+// PrimalityTest - вероятностный тест простоты: составное n признается простым
+// с вероятностью не больше 1 - minProbability. minProbability берется из [0.5, 1),
+// значения вне диапазона прижимаются к его границам.
+type PrimalityTest interface {
+	IsProbablyPrime(n *big.Int, minProbability float64) bool
+}
+
+var (
+	bigOne   = big.NewInt(1)
+	bigTwo   = big.NewInt(2)
+	bigThree = big.NewInt(3)
+)
+
+// нижняя граница допустимой ошибки: 1 - minProbability в float64 при
+// minProbability -> 1 обращается в ноль
+const minFailure = 0x1p-128
+
+// roundTest - один раунд конкретного теста со свидетелем a из [2, n-2]
+type roundTest interface {
+	round(n, a *big.Int) bool
+	roundError() float64 // верхняя оценка вероятности ошибки одного раунда
+}
+
+// probabilisticTest - общий шаблон: тривиальные случаи, число раундов
+// по требуемой вероятности, случайные свидетели
+type probabilisticTest struct {
+	rt roundTest
+}
+
+func (t probabilisticTest) IsProbablyPrime(n *big.Int, minProbability float64) bool {
+	switch {
+	case n.Cmp(bigTwo) < 0:
+		return false
+	case n.Cmp(bigThree) <= 0:
+		return true
+	case n.Bit(0) == 0:
+		return false
+	}
+
+	rounds := Rounds(minProbability, t.rt.roundError())
+	upper := new(big.Int).Sub(n, bigThree) // a = 2 + rand[0, n-3)
+	for i := 0; i < rounds; i++ {
+		a, err := rand.Int(rand.Reader, upper)
+		if err != nil {
+			panic("primality: crypto/rand failed: " + err.Error())
+		}
+		a.Add(a, bigTwo)
+		if !t.rt.round(n, a) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rounds - наименьшее k, при котором roundError^k <= 1 - minProbability
+func Rounds(minProbability, roundError float64) int {
+	failure := math.Max(minFailure, math.Min(1-minProbability, 0.5))
+	k := math.Ceil(math.Log(failure) / math.Log(roundError))
+	return max(int(k), 1)
+}
@@ -0,0 +1,82 @@
+package primality
+
+import (
+	"math/big"
+	"testing"
+)
+
+var allTests = map[string]PrimalityTest{
+	"Fermat":           NewFermatTest(),
+	"Solovay-Strassen": NewSolovayStrassenTest(),
+	"Miller-Rabin":     NewMillerRabinTest(),
+}
+
+func TestSmallNumbersMatchProbablyPrime(t *testing.T) {
+	for name, test := range allTests {
+		t.Run(name, func(t *testing.T) {
+			for n := int64(-5); n < 3000; n++ {
+				x := big.NewInt(n)
+				expected := n > 1 && x.ProbablyPrime(20)
+				if carmichael[n] && name == "Fermat" {
+					continue
+				}
+				if got := test.IsProbablyPrime(x, 0.999999); got != expected {
+					t.Fatalf("%d: expected %v, got %v", n, expected, got)
+				}
+			}
+		})
+	}
+}
+
+var carmichael = map[int64]bool{561: true, 1105: true, 1729: true, 2465: true, 2821: true}
+
+// числа Кармайкла обманывают тест Ферма, но не Соловея–Штрассена и Миллера–Рабина
+func TestCarmichaelNumbers(t *testing.T) {
+	numbers := []int64{561, 1105, 1729, 2465, 2821, 6601, 8911, 41041, 825265, 321197185}
+	for _, name := range []string{"Solovay-Strassen", "Miller-Rabin"} {
+		for _, n := range numbers {
+			if allTests[name].IsProbablyPrime(big.NewInt(n), 0.999999) {
+				t.Errorf("%s: Carmichael number %d reported prime", name, n)
+			}
+		}
+	}
+}
+
+func TestLargePrimes(t *testing.T) {
+	one := big.NewInt(1)
+	m127 := new(big.Int).Sub(new(big.Int).Lsh(one, 127), one) // простое Мерсенна
+	m128 := new(big.Int).Sub(new(big.Int).Lsh(one, 128), one) // составное
+	product := new(big.Int).Mul(m127, big.NewInt(1000003))
+
+	for name, test := range allTests {
+		if !test.IsProbablyPrime(m127, 0.99) {
+			t.Errorf("%s: 2^127-1 must be prime", name)
+		}
+		if test.IsProbablyPrime(m128, 0.99) {
+			t.Errorf("%s: 2^128-1 must be composite", name)
+		}
+		if test.IsProbablyPrime(product, 0.99) {
+			t.Errorf("%s: product of primes must be composite", name)
+		}
+	}
+}
+
+func TestRounds(t *testing.T) {
+	tests := []struct {
+		probability, roundError float64
+		expected                int
+	}{
+		{0.5, 0.5, 1},
+		{0.75, 0.5, 2},
+		{0.75, 0.25, 1},
+		{0.999, 0.5, 10},
+		{0.999, 0.25, 5},
+		{0.1, 0.25, 1}, // прижимается к 0.5
+		{1, 0.25, 64},  // прижимается к 1 - 2^-128
+	}
+	for _, tt := range tests {
+		if got := Rounds(tt.probability, tt.roundError); got != tt.expected {
+			t.Errorf("Rounds(%v, %v) = %d, expected %d", tt.probability, tt.roundError, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package primality
+
+import (
+	"math/big"
+
+	"crypto-lab/internal/math/numtheory"
+)
+
+// Solovay–Strassen: (a/n) = a^((n-1)/2) mod n, ошибка раунда <= 1/2
+func NewSolovayStrassenTest() PrimalityTest {
+	return probabilisticTest{rt: solovayStrassenRound{}}
+}
+
+type solovayStrassenRound struct{}
+
+func (solovayStrassenRound) roundError() float64 { return 0.5 }
+
+func (solovayStrassenRound) round(n, a *big.Int) bool {
+	j, _ := numtheory.Jacobi(a, n)
+	if j == 0 {
+		return false
+	}
+	r, _ := numtheory.ModExp(a, new(big.Int).Rsh(n, 1), n)
+
+	expected := big.NewInt(int64(j))
+	expected.Mod(expected, n) // -1 -> n-1
+	return r.Cmp(expected) == 0
+}
@@ -0,0 +1,164 @@
+package modes
+
+import (
+	"bytes"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/hash"
+	"crypto-lab/internal/mac"
+)
+
+// MinMACKeySize - минимальная длина ключа MAC в NewAuthenticatedContext
+const MinMACKeySize = 16
+
+// AuthenticatedContext - Encrypt-then-MAC поверх SymmetricContext.
+// Сообщение: заголовок (как у файлов, см. container.go) || шифртекст || тег,
+// тег считается по заголовку и шифртексту, т.е. покрывает режим, паддинг,
+// IV/nonce/seed. При расшифровке тег проверяется до любой работы шифра.
+type AuthenticatedContext struct {
+	ctx    *SymmetricContext
+	newMAC func() (mac.MAC, error)
+}
+
+// NewAuthenticatedContext использует HMAC-SHA-256; macKey не должен совпадать
+// с ключом шифра
+func NewAuthenticatedContext(ctx *SymmetricContext, macKey []byte) (*AuthenticatedContext, error) {
+	if len(macKey) < MinMACKeySize {
+		return nil, fmt.Errorf("MAC key too short: %d bytes, need at least %d", len(macKey), MinMACKeySize)
+	}
+	key := append([]byte(nil), macKey...)
+	return NewAuthenticatedContextWithMAC(ctx, func() (mac.MAC, error) {
+		return mac.NewHMAC(hash.NewSHA256, key)
+	})
+}
+
+// NewAuthenticatedContextWithMAC: newMAC возвращает новый MAC с уже заданным
+// ключом на каждое сообщение (например, CMAC над отдельным экземпляром шифра)
+func NewAuthenticatedContextWithMAC(ctx *SymmetricContext, newMAC func() (mac.MAC, error)) (*AuthenticatedContext, error) {
+	if ctx == nil || newMAC == nil {
+		return nil, fmt.Errorf("authenticated context requires a symmetric context and a MAC")
+	}
+	if _, err := newMAC(); err != nil {
+		return nil, fmt.Errorf("failed to create MAC: %w", err)
+	}
+	return &AuthenticatedContext{ctx: ctx, newMAC: newMAC}, nil
+}
+
+func (a *AuthenticatedContext) Encrypt(message []byte) ([]byte, error) {
+	iv, nonce, err := a.ctx.messageIVAndNonce()
+	if err != nil {
+		return nil, err
+	}
+	msgCtx, err := a.ctx.withIVAndNonce(iv, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := a.ctx.headerFor(iv, nonce).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := msgCtx.Encrypt(message)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := a.newMAC()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(ciphertext)+m.Size())
+	out = append(out, header...)
+	out = append(out, ciphertext...)
+	m.Write(out)
+	return m.Sum(out), nil
+}
+
+// Decrypt: сначала тег (за постоянное время), потом заголовок и шифртекст
+func (a *AuthenticatedContext) Decrypt(data []byte) ([]byte, error) {
+	m, err := a.newMAC()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < m.Size() {
+		return nil, ErrAuthenticationFailed
+	}
+	body, tag := data[:len(data)-m.Size()], data[len(data)-m.Size():]
+	m.Write(body)
+	if !m.Verify(tag) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	r := bytes.NewReader(body)
+	h, err := ReadFileHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if h.Mode != a.ctx.cipherMode || h.Padding != a.ctx.paddingMode {
+		return nil, fmt.Errorf("message uses %v/%v, context is %v/%v",
+			h.Mode, h.Padding, a.ctx.cipherMode, a.ctx.paddingMode)
+	}
+	if h.HasSeed && h.Seed != a.ctx.getRandomDeltaSeed() {
+		return nil, fmt.Errorf("message RandomDelta seed %d differs from context", h.Seed)
+	}
+
+	msgCtx, err := a.ctx.withIVAndNonce(h.IV, h.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return msgCtx.Decrypt(body[len(body)-r.Len():])
+}
+
+func (a *AuthenticatedContext) GetBlockSize() int {
+	return a.ctx.GetBlockSize()
+}
+
+// IV и nonce очередного сообщения: заданные в контексте используются как есть,
+// иначе каждый раз новые случайные (не запоминаются, в отличие от resolveIV)
+func (ctx *SymmetricContext) messageIVAndNonce() (iv, nonce []byte, err error) {
+	switch ctx.cipherMode {
+	case ciphers.CBC, ciphers.PCBC, ciphers.CFB, ciphers.OFB,
+		ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3, ciphers.XTS:
+		ctx.mu.RLock()
+		iv = append([]byte(nil), ctx.iv...)
+		ctx.mu.RUnlock()
+		if len(iv) == 0 {
+			iv, err = GenerateRandomBytes(ctx.blockSize)
+		}
+	case ciphers.CTR, ciphers.RandomDelta, ciphers.GCM:
+		if len(ctx.params) > 0 {
+			nonce, _ = ctx.params[0].([]byte)
+		}
+		if len(nonce) == 0 {
+			size := ctx.blockSize / 2
+			if ctx.cipherMode == ciphers.GCM {
+				size = gcmStandardNonce
+			}
+			nonce, err = GenerateRandomBytes(size)
+		}
+	}
+	return iv, nonce, err
+}
+
+// копия контекста с явными IV и nonce; остальные параметры (seed, GCMParams,
+// XTSParams) сохраняются
+func (ctx *SymmetricContext) withIVAndNonce(iv, nonce []byte) (*SymmetricContext, error) {
+	params := append([]interface{}(nil), ctx.params...)
+	switch {
+	case len(params) == 0:
+		params = append(params, nonce)
+	case params[0] == nil:
+		params[0] = nonce
+	default:
+		if _, ok := params[0].([]byte); ok {
+			params[0] = nonce
+		} else {
+			params = append([]interface{}{nonce}, params...)
+		}
+	}
+	if len(iv) == 0 {
+		iv = nil
+	}
+	return NewSymmetricContext(ctx.cipher, ctx.cipherMode, ctx.paddingMode, iv, params...)
+}
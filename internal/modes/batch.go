@@ -0,0 +1,33 @@
+package modes
+
+import "crypto-lab/internal/ciphers"
+
+// блоков на одну горутину при пакетной обработке
+const batchChunkBlocks = 1024
+
+// cryptBatch шифрует (расшифровывает) независимые блоки src в dst через
+// ciphers.BatchCipher, куски по batchChunkBlocks блоков - параллельно
+func (ctx *SymmetricContext) cryptBatch(bc ciphers.BatchCipher, dst, src []byte, isEncrypt bool) error {
+	bs := ctx.blockSize
+	return parallelChunks(len(src)/bs, batchChunkBlocks, func(first, last int) error {
+		if isEncrypt {
+			return bc.EncryptBatch(dst[first*bs:last*bs], src[first*bs:last*bs])
+		}
+		return bc.DecryptBatch(dst[first*bs:last*bs], src[first*bs:last*bs])
+	})
+}
+
+// batchKeystream: блоки счетчика для всех blockCount блоков одним буфером,
+// шифруются пакетно и накладываются на data
+func (ctx *SymmetricContext) batchKeystream(bc ciphers.BatchCipher, data []byte, blockCount int,
+	counter func(dst []byte, i int)) ([]byte, error) {
+	keystream := make([]byte, blockCount*ctx.blockSize)
+	for i := 0; i < blockCount; i++ {
+		counter(keystream[i*ctx.blockSize:(i+1)*ctx.blockSize], i)
+	}
+	if err := ctx.cryptBatch(bc, keystream, keystream, true); err != nil {
+		return nil, err
+	}
+	xorInto(keystream[:len(data)], keystream, data)
+	return keystream[:len(data)], nil
+}
@@ -1,76 +1,42 @@
 package modes
 
-import "sync"
-
 func (ctx *SymmetricContext) processCBC(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
 	result := make([]byte, len(data))
-	currentIV := make([]byte, ctx.blockSize)
-	copy(currentIV, iv)
+	bs := ctx.blockSize
 
 	if isEncrypt {
-		for i := 0; i < len(data); i += ctx.blockSize {
-			block := make([]byte, ctx.blockSize)
-			copy(block, data[i:i+ctx.blockSize])
-
+		currentIV := iv[:bs]
+		for i := 0; i < len(data); i += bs {
+			block := result[i : i+bs]
 			// block=Pi XOR IV
-			for j := 0; j < ctx.blockSize; j++ {
-				block[j] ^= currentIV[j]
-			}
+			xorInto(block, data[i:i+bs], currentIV)
 			//Ci=E(K,Pi xor IVi)
-			encrypted, err := ctx.cipher.Encrypt(block)
-			if err != nil {
+			if err := ctx.encryptBlock(block, block); err != nil {
 				return nil, err
 			}
-
-			copy(result[i:], encrypted)
-			copy(currentIV, encrypted) // IV i+1=Ci
-		}
-	} else {
-		// параллельный дешифр блоков, последовательно XOR
-		var wg sync.WaitGroup
-		decryptedBlocks := make([][]byte, len(data)/ctx.blockSize)
-		errCh := make(chan error, 1)
-
-		for i := 0; i < len(data); i += ctx.blockSize {
-			wg.Add(1)
-			go func(blockIndex int) {
-				defer wg.Done()
-
-				block := data[blockIndex : blockIndex+ctx.blockSize]
-				decrypted, err := ctx.cipher.Decrypt(block)
-				if err != nil {
-					select {
-					case errCh <- err:
-					default:
-					}
-					return
-				}
-				decryptedBlocks[blockIndex/ctx.blockSize] = decrypted
-			}(i)
-		}
-		wg.Wait()
-
-		select {
-		case err := <-errCh:
-			return nil, err
-		default:
+			currentIV = block // IV i+1=Ci
 		}
+		return result, nil
+	}
 
-		// XOR 
-		prevBlock := iv
-		for i := 0; i < len(decryptedBlocks); i++ {
-			//Pi=D(K,Ci) XOR IVi
-			plainBlock, err := XOR(decryptedBlocks[i], prevBlock)
-			if err != nil {
-				return nil, err
+	// параллельный дешифр кусков блоков: Pi = D(K,Ci) XOR Ci-1, C0 = IV
+	err := parallelChunks(len(data)/bs, parallelChunkBlocks, func(first, last int) error {
+		for i := first; i < last; i++ {
+			offset := i * bs
+			block := result[offset : offset+bs]
+			if err := ctx.decryptBlock(block, data[offset:offset+bs]); err != nil {
+				return err
 			}
-			copy(result[i*ctx.blockSize:], plainBlock)
-			//IVi+1=Ci
-			if i < len(data)/ctx.blockSize-1 {
-				prevBlock = data[i*ctx.blockSize : (i+1)*ctx.blockSize]
+			prev := iv[:bs]
+			if i > 0 {
+				prev = data[offset-bs : offset]
 			}
+			xorInto(block, block, prev)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return result, nil
 }
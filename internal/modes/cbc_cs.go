@@ -0,0 +1,90 @@
+// CBC с кражей шифртекста (NIST SP 800-38A Addendum): длина выхода = длина входа
+
+package modes
+
+import (
+	"errors"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+var ErrMessageTooShort = errors.New("modes: ciphertext stealing requires at least one full block")
+
+// CS1: C1..Cn-2 || C*n-1 || Cn
+// CS2: как CS1, если последний блок полный, иначе как CS3
+// CS3: C1..Cn-2 || Cn || C*n-1 (всегда, как в Kerberos)
+func (ctx *SymmetricContext) processCBCCS(data []byte, iv []byte, variant ciphers.CipherMode, isEncrypt bool) ([]byte, error) {
+	bs := ctx.blockSize
+	if len(data) < bs {
+		return nil, fmt.Errorf("%w: got %d bytes, block size %d", ErrMessageTooShort, len(data), bs)
+	}
+
+	n := (len(data) + bs - 1) / bs
+	d := len(data) - (n-1)*bs // длина последнего (возможно неполного) блока, 1..bs
+
+	if n == 1 {
+		return ctx.processCBC(data, iv, isEncrypt)
+	}
+
+	swap := variant == ciphers.CBCCS3 || (variant == ciphers.CBCCS2 && d != bs)
+	headLen := (n - 2) * bs
+
+	if isEncrypt {
+		// обычный CBC над P1..Pn-1 || Pn || 0*
+		padded := make([]byte, n*bs)
+		copy(padded, data)
+		full, err := ctx.processCBC(padded, iv, true)
+		if err != nil {
+			return nil, err
+		}
+
+		cPrev := full[headLen : headLen+bs]
+		cLast := full[headLen+bs:]
+
+		result := make([]byte, 0, len(data))
+		result = append(result, full[:headLen]...)
+		if swap {
+			result = append(result, cLast...)
+			result = append(result, cPrev[:d]...)
+		} else {
+			result = append(result, cPrev[:d]...)
+			result = append(result, cLast...)
+		}
+		return result, nil
+	}
+
+	var cStar, cLast []byte
+	if swap {
+		cLast = data[headLen : headLen+bs]
+		cStar = data[headLen+bs:]
+	} else {
+		cStar = data[headLen : headLen+d]
+		cLast = data[headLen+d:]
+	}
+
+	// D(Cn) = (Pn || 0*) XOR Cn-1: хвост Cn-1 восстанавливается из хвоста D(Cn)
+	z := make([]byte, bs)
+	if err := ctx.decryptBlock(z, cLast); err != nil {
+		return nil, err
+	}
+	cPrev := make([]byte, bs)
+	copy(cPrev, cStar)
+	copy(cPrev[d:], z[d:])
+
+	pLast, err := XOR(z[:d], cPrev[:d])
+	if err != nil {
+		return nil, err
+	}
+
+	// P1..Pn-1 - обычный CBC над C1..Cn-2 || Cn-1
+	head := make([]byte, 0, headLen+bs)
+	head = append(head, data[:headLen]...)
+	head = append(head, cPrev...)
+	plain, err := ctx.processCBC(head, iv, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(plain, pLast...), nil
+}
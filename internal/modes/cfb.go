@@ -2,38 +2,24 @@ package modes
 
 func (ctx *SymmetricContext) processCFB(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
 	result := make([]byte, len(data))
-	currentIV := make([]byte, ctx.blockSize)
-	copy(currentIV, iv)
+	bs := ctx.blockSize
+	keystream := make([]byte, bs)
+	currentIV := iv[:bs]
 
-	for i := 0; i < len(data); i += ctx.blockSize {
-		if isEncrypt {
-			// Ki=E(K,IVi)
-			keystream, err := ctx.cipher.Encrypt(currentIV)
-			if err != nil {
-				return nil, err
-			}
+	for i := 0; i < len(data); i += bs {
+		// Ki=E(K,IVi)
+		if err := ctx.encryptBlock(keystream, currentIV); err != nil {
+			return nil, err
+		}
 
-			// Pi xor Ki =Ci
-			for j := 0; j < ctx.blockSize; j++ {
-				result[i+j] = data[i+j] ^ keystream[j]
-			}
+		// Pi xor Ki =Ci, Ci xor Ki =Pi
+		xorInto(result[i:i+bs], data[i:i+bs], keystream)
 
-			// IVi+1=Ci
-			copy(currentIV, result[i:i+ctx.blockSize])
+		// IVi+1=Ci
+		if isEncrypt {
+			currentIV = result[i : i+bs]
 		} else {
-			// Ki=E(K,IVi)
-			keystream, err := ctx.cipher.Encrypt(currentIV)
-			if err != nil {
-				return nil, err
-			}
-
-			// Ci xor Ki =Pi
-			for j := 0; j < ctx.blockSize; j++ {
-				result[i+j] = data[i+j] ^ keystream[j]
-			}
-
-			// IVi+1=Ci
-			copy(currentIV, data[i:i+ctx.blockSize])
+			currentIV = data[i : i+bs]
 		}
 	}
 
@@ -0,0 +1,344 @@
+package modes
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// Формат заголовка зашифрованного файла (версия 1), big-endian:
+//
+//	magic      [4]byte  "CLAB"
+//	version    uint8
+//	cipherID   uint8    ciphers.CipherID
+//	keySize    uint8    байты, 0 - неизвестно
+//	blockSize  uint8
+//	mode       uint8    ciphers.CipherMode
+//	padding    uint8    ciphers.PaddingMode
+//	flags      uint8    бит 0 - есть seed, бит 1 - есть длина тега GCM
+//	ivLen      uint8,   iv
+//	nonceLen   uint8,   nonce
+//	seed       int64    только если флаг
+//	gcmTagLen  uint8    только если флаг; тег GCM идет в конце шифртекста
+//	saltLen    uint8,   salt (KDF)
+//	tagLen     uint8,   tag (MAC)
+//
+// Сразу за заголовком идет шифртекст.
+
+var FileMagic = [4]byte{'C', 'L', 'A', 'B'}
+
+const FileFormatVersion = 1
+
+const (
+	flagHasSeed       = 1 << 0
+	flagHasGCMTagSize = 1 << 1
+)
+
+var (
+	ErrBadMagic        = errors.New("container: not an encrypted file (bad magic)")
+	ErrTruncatedHeader = errors.New("container: truncated header")
+)
+
+type UnsupportedVersionError struct {
+	Version byte
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("container: unsupported format version %d (supported: %d)", e.Version, FileFormatVersion)
+}
+
+type FileHeader struct {
+	Version    byte
+	CipherID   ciphers.CipherID
+	KeySize    int
+	BlockSize  int
+	Mode       ciphers.CipherMode
+	Padding    ciphers.PaddingMode
+	IV         []byte
+	Nonce      []byte
+	HasSeed    bool
+	Seed       int64
+	GCMTagSize int // 0 - нет в заголовке
+	Salt       []byte
+	MACTag     []byte
+}
+
+func (h *FileHeader) MarshalBinary() ([]byte, error) {
+	for name, field := range map[string][]byte{"IV": h.IV, "nonce": h.Nonce, "salt": h.Salt, "MAC tag": h.MACTag} {
+		if len(field) > 255 {
+			return nil, fmt.Errorf("container: %s too long (%d bytes, max 255)", name, len(field))
+		}
+	}
+	if h.KeySize < 0 || h.KeySize > 255 || h.BlockSize <= 0 || h.BlockSize > 255 {
+		return nil, fmt.Errorf("container: invalid key/block size %d/%d", h.KeySize, h.BlockSize)
+	}
+	if h.GCMTagSize < 0 || h.GCMTagSize > 255 {
+		return nil, fmt.Errorf("container: invalid GCM tag size %d", h.GCMTagSize)
+	}
+
+	buf := make([]byte, 0, 32+len(h.IV)+len(h.Nonce)+len(h.Salt)+len(h.MACTag))
+	buf = append(buf, FileMagic[:]...)
+	buf = append(buf, FileFormatVersion, byte(h.CipherID), byte(h.KeySize), byte(h.BlockSize),
+		byte(h.Mode), byte(h.Padding))
+
+	var flags byte
+	if h.HasSeed {
+		flags |= flagHasSeed
+	}
+	if h.GCMTagSize != 0 {
+		flags |= flagHasGCMTagSize
+	}
+	buf = append(buf, flags)
+
+	buf = append(buf, byte(len(h.IV)))
+	buf = append(buf, h.IV...)
+	buf = append(buf, byte(len(h.Nonce)))
+	buf = append(buf, h.Nonce...)
+	if h.HasSeed {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(h.Seed))
+	}
+	if h.GCMTagSize != 0 {
+		buf = append(buf, byte(h.GCMTagSize))
+	}
+	buf = append(buf, byte(len(h.Salt)))
+	buf = append(buf, h.Salt...)
+	buf = append(buf, byte(len(h.MACTag)))
+	buf = append(buf, h.MACTag...)
+
+	return buf, nil
+}
+
+func (h *FileHeader) WriteTo(w io.Writer) (int64, error) {
+	data, err := h.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadFileHeader читает ровно заголовок, r остается на начале шифртекста
+func ReadFileHeader(r io.Reader) (*FileHeader, error) {
+	hr := &headerReader{r: r}
+
+	var magic [4]byte
+	hr.read(magic[:])
+	if hr.err == nil && magic != FileMagic {
+		return nil, ErrBadMagic
+	}
+
+	version := hr.byte()
+	if hr.err == nil && version != FileFormatVersion {
+		return nil, &UnsupportedVersionError{Version: version}
+	}
+
+	h := &FileHeader{Version: version}
+	h.CipherID = ciphers.CipherID(hr.byte())
+	h.KeySize = int(hr.byte())
+	h.BlockSize = int(hr.byte())
+	h.Mode = ciphers.CipherMode(hr.byte())
+	h.Padding = ciphers.PaddingMode(hr.byte())
+	flags := hr.byte()
+	h.IV = hr.field()
+	h.Nonce = hr.field()
+	if flags&flagHasSeed != 0 {
+		var seed [8]byte
+		hr.read(seed[:])
+		h.HasSeed = true
+		h.Seed = int64(binary.BigEndian.Uint64(seed[:]))
+	}
+	if flags&flagHasGCMTagSize != 0 {
+		h.GCMTagSize = int(hr.byte())
+	}
+	h.Salt = hr.field()
+	h.MACTag = hr.field()
+
+	if hr.err != nil {
+		return nil, hr.err
+	}
+	if h.BlockSize == 0 {
+		return nil, fmt.Errorf("container: invalid block size 0")
+	}
+	return h, nil
+}
+
+// читает поля подряд, запоминая первую ошибку
+type headerReader struct {
+	r   io.Reader
+	err error
+}
+
+func (hr *headerReader) read(p []byte) {
+	if hr.err != nil {
+		return
+	}
+	if _, err := io.ReadFull(hr.r, p); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrTruncatedHeader
+		}
+		hr.err = err
+	}
+}
+
+func (hr *headerReader) byte() byte {
+	var b [1]byte
+	hr.read(b[:])
+	return b[0]
+}
+
+func (hr *headerReader) field() []byte {
+	n := hr.byte()
+	if hr.err != nil || n == 0 {
+		return nil
+	}
+	p := make([]byte, n)
+	hr.read(p)
+	return p
+}
+
+// заголовок по параметрам контекста и состоянию потока (IV и nonce, реально использованные)
+func (ctx *SymmetricContext) fileHeader(state *streamState) *FileHeader {
+	return ctx.headerFor(state.iv, state.nonce)
+}
+
+func (ctx *SymmetricContext) headerFor(iv, nonce []byte) *FileHeader {
+	h := &FileHeader{
+		Version:   FileFormatVersion,
+		BlockSize: ctx.blockSize,
+		Mode:      ctx.cipherMode,
+		Padding:   ctx.paddingMode,
+	}
+	if info, ok := ctx.cipher.(ciphers.CipherInfo); ok {
+		h.CipherID = info.GetCipherID()
+		h.KeySize = info.GetKeySize()
+	}
+
+	switch ctx.cipherMode {
+	case ciphers.CBC, ciphers.PCBC, ciphers.CFB, ciphers.OFB,
+		ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3:
+		h.IV = iv
+	case ciphers.XTS:
+		// только сообщения AuthenticatedContext: файлов XTS нет (ErrXTSNotStreamable)
+		h.IV = iv
+	case ciphers.CTR:
+		h.Nonce = nonce
+	case ciphers.GCM:
+		h.Nonce = nonce
+		h.GCMTagSize = ctx.getGCMParams().TagSize
+		if h.GCMTagSize == 0 {
+			h.GCMTagSize = gcmDefaultTagSize
+		}
+	case ciphers.RandomDelta:
+		h.Nonce = nonce
+		h.HasSeed = true
+		h.Seed = ctx.getRandomDeltaSeed()
+	}
+	return h
+}
+
+// контекст для расшифровки по заголовку: режим, паддинг, IV, nonce и seed берутся из файла,
+// шифр, ключ и AAD GCM - из ctx
+func (ctx *SymmetricContext) contextForHeader(h *FileHeader) (*SymmetricContext, error) {
+	return NewSymmetricContextFromHeader(ctx.cipher, h, ctx.getGCMParams())
+}
+
+// NewSymmetricContextFromHeader строит контекст для расшифровки файла с заголовком h;
+// cipher должен быть уже с ключом (например, выведенным из пароля и h.Salt).
+// params - то, чего нет в заголовке, например GCMParams с AAD (длина тега берется из файла)
+func NewSymmetricContextFromHeader(cipher ciphers.SymmetricCipher, h *FileHeader, params ...interface{}) (*SymmetricContext, error) {
+	if h.Mode == ciphers.XTS {
+		return nil, ErrXTSNotStreamable
+	}
+	if h.BlockSize != cipher.GetBlockSize() {
+		return nil, fmt.Errorf("container: block size mismatch: file %d, cipher %d", h.BlockSize, cipher.GetBlockSize())
+	}
+	if info, ok := cipher.(ciphers.CipherInfo); ok && h.CipherID != ciphers.CipherUnknown {
+		if info.GetCipherID() != h.CipherID {
+			return nil, fmt.Errorf("container: cipher mismatch: file %v, context %v", h.CipherID, info.GetCipherID())
+		}
+		if h.KeySize != 0 && info.GetKeySize() != h.KeySize {
+			return nil, fmt.Errorf("container: key size mismatch: file %d, cipher %d", h.KeySize, info.GetKeySize())
+		}
+	}
+
+	var iv []byte
+	if len(h.IV) > 0 {
+		iv = h.IV
+	}
+	ctxParams := []interface{}{h.Nonce}
+	if h.HasSeed {
+		ctxParams = append(ctxParams, h.Seed)
+	}
+	hasGCMParams := false
+	for _, p := range params {
+		if gp, ok := p.(GCMParams); ok {
+			gp.TagSize = h.GCMTagSize
+			p, hasGCMParams = gp, true
+		}
+		ctxParams = append(ctxParams, p)
+	}
+	if h.Mode == ciphers.GCM && !hasGCMParams {
+		ctxParams = append(ctxParams, GCMParams{TagSize: h.GCMTagSize})
+	}
+	return NewSymmetricContext(cipher, h.Mode, h.Padding, iv, ctxParams...)
+}
+
+// HeaderOption дополняет заголовок перед записью (соль KDF, тег MAC)
+type HeaderOption func(h *FileHeader)
+
+func WithKDFSalt(salt []byte) HeaderOption {
+	return func(h *FileHeader) { h.Salt = salt }
+}
+
+func WithMACTag(tag []byte) HeaderOption {
+	return func(h *FileHeader) { h.MACTag = tag }
+}
+
+// EncryptStream пишет заголовок и шифртекст in в out
+func (ctx *SymmetricContext) EncryptStream(out io.Writer, in io.Reader, opts ...HeaderOption) error {
+	strategy, state, err := ctx.newStream()
+	if err != nil {
+		return err
+	}
+	h := ctx.fileHeader(state)
+	for _, opt := range opts {
+		opt(h)
+	}
+	if _, err := h.WriteTo(out); err != nil {
+		return err
+	}
+
+	writer := ctx.newEncryptWriter(out, DefaultStreamChunkSize, strategy, state)
+	if _, err := io.Copy(writer, in); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// DecryptStream читает заголовок из in и пишет открытый текст в out
+func (ctx *SymmetricContext) DecryptStream(out io.Writer, in io.Reader) error {
+	reader, err := ctx.newContainerReader(in)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, reader)
+	return err
+}
+
+// заголовок читается и проверяется сразу, до первого байта открытого текста
+func (ctx *SymmetricContext) newContainerReader(in io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(in)
+	h, err := ReadFileHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	fileCtx, err := ctx.contextForHeader(h)
+	if err != nil {
+		return nil, err
+	}
+	return fileCtx.NewDecryptReader(br)
+}
@@ -4,7 +4,9 @@ import (
 	//	"errors"
 		"fmt"
 	"crypto-lab/internal/ciphers"
+	"io"
 	"os"
+	"path/filepath"
 	"sync"
 )
 
@@ -43,7 +45,24 @@ func NewSymmetricContext(
     return ctx, nil
 }
 
+// NewSymmetricContextFromSpec: шифр из реестра ciphers по строке вида
+// "DEAL-256/CBC/PKCS7" (см. ciphers.ParseSpec), ключ ставится сразу
+func NewSymmetricContextFromSpec(spec string, key, iv []byte, params ...interface{}) (*SymmetricContext, error) {
+	s, err := ciphers.ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := ciphers.Lookup(s.Cipher, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewSymmetricContext(cipher, s.Mode, s.Padding, iv, params...)
+}
+
 func (ctx *SymmetricContext) Encrypt(message []byte) ([]byte, error) {
+	if _, ok := ctx.processor.(unpaddedStrategy); ok {
+		return ctx.processUnpadded(message, true)
+	}
 	padded, err := ctx.applyPadding(message) //+паддинг(доп байты), чтобы кратно блоку
 	if err != nil {
 		return nil, err
@@ -52,6 +71,9 @@ func (ctx *SymmetricContext) Encrypt(message []byte) ([]byte, error) {
 }
 
 func (ctx *SymmetricContext) Decrypt(ciphertext []byte) ([]byte, error) {
+	if _, ok := ctx.processor.(unpaddedStrategy); ok {
+		return ctx.processUnpadded(ciphertext, false)
+	}
 	decrypted, err := ctx.processBlocks(ciphertext, false) //фолз=дешифрование
 	if err != nil {
 		return nil, err
@@ -59,32 +81,69 @@ func (ctx *SymmetricContext) Decrypt(ciphertext []byte) ([]byte, error) {
 	return ctx.removePadding(decrypted) //удаляет паддинг-возвращает исходные данные
 }
 
+// файл шифруется потоково, чанками по DefaultStreamChunkSize;
+// в начало пишется заголовок (см. container.go) с режимом, паддингом, IV/nonce/seed
 func (ctx *SymmetricContext) EncryptFile(inputPath, outputPath string) error {
-	data, err := os.ReadFile(inputPath)
+	in, err := os.Open(inputPath)
 	if err != nil {
 		return err
 	}
-	//шифруем содержимое прочитанного файла
-	encrypted, err := ctx.Encrypt(data)
+	defer in.Close()
+
+	out, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
+	defer out.Close()
 
-	return os.WriteFile(outputPath, encrypted, 0644) //записываем в новый файл
+	if err := ctx.EncryptStream(out, in); err != nil {
+		return err
+	}
+	return out.Close()
 }
 
+// режим, паддинг и IV/nonce/seed берутся из заголовка файла, ключ - из ctx.
+// Выход создается только после проверки заголовка и пишется во временный файл рядом,
+// который переименовывается при успехе: плохой файл или неверный ключ не портят
+// существующий outputPath и не оставляют частичный открытый текст
 func (ctx *SymmetricContext) DecryptFile(inputPath, outputPath string) error {
-	data, err := os.ReadFile(inputPath)
+	in, err := os.Open(inputPath)
 	if err != nil {
 		return err
 	}
+	defer in.Close()
 
-	decrypted, err := ctx.Decrypt(data)
+	reader, err := ctx.newContainerReader(in)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(outputPath, decrypted, 0644)
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".tmp*")
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, reader); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), outputPath); err != nil {
+		return err
+	}
+	committed = true
+	return nil
 }
 
 func (ctx *SymmetricContext) GetBlockSize() int {
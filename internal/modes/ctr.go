@@ -2,16 +2,29 @@ package modes
 
 import (
 	"fmt"
-	"sync"
+
+	"crypto-lab/internal/ciphers"
 )
 
-func (ctx *SymmetricContext) processCTR(data []byte, isEncrypt bool) ([]byte, error) {
+// XORKeyStream - CTR без паддинга: результат той же длины, что data (записи,
+// поля фиксированной длины); шифрование и расшифровка совпадают. Nonce берется
+// только из params[0], случайный не генерируется - иначе расшифровать нечем
+func (ctx *SymmetricContext) XORKeyStream(data []byte) ([]byte, error) {
+	if ctx.cipherMode != ciphers.CTR {
+		return nil, fmt.Errorf("XORKeyStream requires CTR mode, got %v", ctx.cipherMode)
+	}
+	var nonce []byte
+	if len(ctx.params) > 0 {
+		nonce, _ = ctx.params[0].([]byte)
+	}
+	return ctx.processCTR(data, nonce, 0)
+}
+
+// startBlock - номер первого блока data в общем потоке (для чанков)
+func (ctx *SymmetricContext) processCTR(data []byte, nonce []byte, startBlock int) ([]byte, error) {
 	blockCount := (len(data) + ctx.blockSize - 1) / ctx.blockSize
 	result := make([]byte, len(data))
-	var wg sync.WaitGroup
-	errs := make([]error, blockCount)
 
-	nonce := ctx.getNonce()
 	if len(nonce) == 0 {
 		return nil, fmt.Errorf("CTR mode requires nonce parameter")
 	}
@@ -19,40 +32,42 @@ func (ctx *SymmetricContext) processCTR(data []byte, isEncrypt bool) ([]byte, er
 		return nil, fmt.Errorf("nonce too long")
 	}
 
-	// для каждого блока свой counter: nonce + индекс
-	for i := 0; i < blockCount; i++ {
-		wg.Add(1)
-		go func(i int) { //горутиииины
-			defer wg.Done()
-			counter := make([]byte, ctx.blockSize)
-			copy(counter, nonce)
-			//счетчик - big-endian
-			for j := len(nonce); j < ctx.blockSize; j++ {
-				counter[j] = byte((i >> (8 * (ctx.blockSize - j - 1))) & 0xFF)
-			}
-			keystream, err := ctx.cipher.Encrypt(counter)
-			if err != nil {
-				errs[i] = err
-				return
-			}
-			offset := i * ctx.blockSize
-			blockSize := ctx.blockSize
-			if offset+blockSize > len(data) {
-				blockSize = len(data) - offset
+	if bc, ok := ctx.cipher.(ciphers.BatchCipher); ok {
+		return ctx.batchKeystream(bc, data, blockCount, func(counter []byte, i int) {
+			ctx.ctrCounter(counter, nonce, startBlock+i)
+		})
+	}
+
+	// для каждого блока свой counter: nonce + индекс; куски блоков параллельно,
+	// буферы счетчика и гаммы - на кусок
+	bs := ctx.blockSize
+	err := parallelChunks(blockCount, parallelChunkBlocks, func(first, last int) error {
+		counter := make([]byte, bs)
+		keystream := make([]byte, bs)
+		for i := first; i < last; i++ {
+			ctx.ctrCounter(counter, nonce, startBlock+i)
+			if err := ctx.encryptBlock(keystream, counter); err != nil {
+				return err
 			}
-			xorResult, err := XOR(data[offset:offset+blockSize], keystream[:blockSize])
-			if err != nil {
-				errs[i] = err
-				return
+			offset := i * bs
+			end := offset + bs
+			if end > len(data) {
+				end = len(data)
 			}
-			copy(result[offset:], xorResult)
-		}(i)
-	}
-	wg.Wait()
-	for _, err := range errs {
-		if err != nil {
-			return nil, err
+			xorInto(result[offset:end], data[offset:end], keystream)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
+
+// counter = nonce || номер блока (big-endian)
+func (ctx *SymmetricContext) ctrCounter(counter, nonce []byte, index int) {
+	copy(counter, nonce)
+	for j := len(nonce); j < ctx.blockSize; j++ {
+		counter[j] = byte((index >> (8 * (ctx.blockSize - j - 1))) & 0xFF)
+	}
+}
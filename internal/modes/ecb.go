@@ -1,44 +1,36 @@
 package modes
 
-import (
-	"runtime"
-	"sync"
-)
+import "crypto-lab/internal/ciphers"
 
 func (ctx *SymmetricContext) processECB(data []byte, isEncrypt bool) ([]byte, error) {
 	blockCount := len(data) / ctx.blockSize //уже чекали убрать
 	result := make([]byte, len(data))
-	var wg sync.WaitGroup
-	errs := make([]error, blockCount)
-	maxWorkers := runtime.NumCPU() //лимит на горутины, а то печаль может быть
-	sem := make(chan struct{}, maxWorkers)
-	for i := 0; i < blockCount; i++ {
-		sem <- struct{}{} 
-		wg.Add(1)
-		go func(i int) { //горутина на каждый блок
-			defer wg.Done()
-			defer func() { <-sem }() 
-			offset := i * ctx.blockSize
-			block := data[offset : offset+ctx.blockSize]
-			var out []byte
+	if bc, ok := ctx.cipher.(ciphers.BatchCipher); ok {
+		if err := ctx.cryptBatch(bc, result, data[:blockCount*ctx.blockSize], isEncrypt); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	// куски блоков параллельно, внутри куска - по блоку сразу в result
+	bs := ctx.blockSize
+	err := parallelChunks(blockCount, parallelChunkBlocks, func(first, last int) error {
+		for i := first; i < last; i++ {
+			offset := i * bs
 			var err error
 			if isEncrypt {
-				out, err = ctx.cipher.Encrypt(block)
+				err = ctx.encryptBlock(result[offset:offset+bs], data[offset:offset+bs])
 			} else {
-				out, err = ctx.cipher.Decrypt(block)
+				err = ctx.decryptBlock(result[offset:offset+bs], data[offset:offset+bs])
 			}
-			if err == nil {
-				copy(result[offset:], out)
-			} else {
-				errs[i] = err
+			if err != nil {
+				return err
 			}
-		}(i)
-	}
-	wg.Wait()
-	for _, err := range errs {
-		if err != nil {
-			return nil, err
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
@@ -0,0 +1,225 @@
+// Galois/Counter Mode (NIST SP 800-38D), только для 16-байтных блоков
+
+package modes
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	gcmBlockSize      = 16
+	gcmStandardNonce  = 12
+	gcmDefaultTagSize = 16
+)
+
+// ErrAuthenticationFailed - тег не сошелся, открытый текст не возвращается
+var ErrAuthenticationFailed = errors.New("modes: message authentication failed")
+
+// GCMParams передается в NewSymmetricContext среди params (в любой позиции после nonce)
+type GCMParams struct {
+	AAD     []byte // дополнительные аутентифицируемые данные
+	TagSize int    // 4, 8, 12..16 байт; 0 - 16
+}
+
+func (ctx *SymmetricContext) getGCMParams() GCMParams {
+	for _, p := range ctx.params {
+		if gp, ok := p.(GCMParams); ok {
+			return gp
+		}
+	}
+	return GCMParams{}
+}
+
+func validGCMTagSize(size int) bool {
+	return size == 4 || size == 8 || (size >= 12 && size <= 16)
+}
+
+// шифрование: C || T, расшифровка: проверка T до выдачи открытого текста
+func (ctx *SymmetricContext) processGCM(data []byte, isEncrypt bool) ([]byte, error) {
+	if ctx.blockSize != gcmBlockSize {
+		return nil, fmt.Errorf("GCM requires 16-byte block cipher, got %d", ctx.blockSize)
+	}
+
+	nonce := ctx.getGCMNonce()
+	if len(nonce) == 0 {
+		return nil, fmt.Errorf("GCM mode requires nonce parameter")
+	}
+
+	gp := ctx.getGCMParams()
+	tagSize := gp.TagSize
+	if tagSize == 0 {
+		tagSize = gcmDefaultTagSize
+	}
+	if !validGCMTagSize(tagSize) {
+		return nil, fmt.Errorf("GCM: invalid tag size %d", tagSize)
+	}
+
+	// H = E(K, 0^128)
+	hBlock := make([]byte, gcmBlockSize)
+	if err := ctx.encryptBlock(hBlock, hBlock); err != nil {
+		return nil, err
+	}
+	h := newGHash(hBlock)
+
+	j0 := h.counterBlock(nonce)
+
+	if isEncrypt {
+		ciphertext, err := ctx.gctr(incCounter32(j0), data)
+		if err != nil {
+			return nil, err
+		}
+		tag, err := ctx.gcmTag(h, j0, gp.AAD, ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		return append(ciphertext, tag[:tagSize]...), nil
+	}
+
+	if len(data) < tagSize {
+		return nil, ErrAuthenticationFailed
+	}
+	ciphertext := data[:len(data)-tagSize]
+	receivedTag := data[len(data)-tagSize:]
+
+	expectedTag, err := ctx.gcmTag(h, j0, gp.AAD, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expectedTag[:tagSize], receivedTag) != 1 {
+		return nil, ErrAuthenticationFailed
+	}
+
+	return ctx.gctr(incCounter32(j0), ciphertext)
+}
+
+// nonce GCM берется только из params[0], случайный не генерируется:
+// повтор или потеря nonce ломает GCM
+func (ctx *SymmetricContext) getGCMNonce() []byte {
+	if len(ctx.params) > 0 {
+		if nonce, ok := ctx.params[0].([]byte); ok {
+			return nonce
+		}
+	}
+	return nil
+}
+
+// T = E(K, J0) XOR GHASH(A || 0* || C || 0* || len(A) || len(C))
+func (ctx *SymmetricContext) gcmTag(h *gHash, j0, aad, ciphertext []byte) ([]byte, error) {
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(aad))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+
+	s := h.sum(aad, ciphertext, lengths[:])
+
+	tag := make([]byte, gcmBlockSize)
+	if err := ctx.encryptBlock(tag, j0); err != nil {
+		return nil, err
+	}
+	xorInto(tag, tag, s)
+	return tag, nil
+}
+
+// GCTR: CTR с инкрементом младших 32 бит счетчика
+func (ctx *SymmetricContext) gctr(icb, data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	var counter, keystream [gcmBlockSize]byte
+	copy(counter[:], icb)
+
+	for offset := 0; offset < len(data); offset += gcmBlockSize {
+		if err := ctx.encryptBlock(keystream[:], counter[:]); err != nil {
+			return nil, err
+		}
+		end := offset + gcmBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		xorInto(result[offset:end], data[offset:end], keystream[:])
+		ctr := binary.BigEndian.Uint32(counter[gcmBlockSize-4:])
+		binary.BigEndian.PutUint32(counter[gcmBlockSize-4:], ctr+1)
+	}
+	return result, nil
+}
+
+func incCounter32(block []byte) []byte {
+	out := make([]byte, len(block))
+	copy(out, block)
+	ctr := binary.BigEndian.Uint32(out[len(out)-4:])
+	binary.BigEndian.PutUint32(out[len(out)-4:], ctr+1)
+	return out
+}
+
+// GHASH над GF(2^128) с многочленом x^128 + x^7 + x^2 + x + 1 (биты в порядке GCM)
+type gHash struct {
+	hHigh, hLow uint64
+}
+
+func newGHash(h []byte) *gHash {
+	return &gHash{
+		hHigh: binary.BigEndian.Uint64(h[:8]),
+		hLow:  binary.BigEndian.Uint64(h[8:]),
+	}
+}
+
+// J0 = IV || 0^31 || 1 для 96-битного IV, иначе GHASH(IV || 0* || 0^64 || len(IV))
+func (g *gHash) counterBlock(iv []byte) []byte {
+	if len(iv) == gcmStandardNonce {
+		j0 := make([]byte, gcmBlockSize)
+		copy(j0, iv)
+		j0[gcmBlockSize-1] = 1
+		return j0
+	}
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(iv))*8)
+	return g.sum(iv, lengths[:])
+}
+
+// каждая часть дополняется нулями до 16 байт
+func (g *gHash) sum(parts ...[]byte) []byte {
+	var yHigh, yLow uint64
+	var block [16]byte
+
+	for _, part := range parts {
+		for offset := 0; offset < len(part); offset += gcmBlockSize {
+			block = [16]byte{}
+			copy(block[:], part[offset:])
+			yHigh ^= binary.BigEndian.Uint64(block[:8])
+			yLow ^= binary.BigEndian.Uint64(block[8:])
+			yHigh, yLow = g.mul(yHigh, yLow)
+		}
+	}
+
+	out := make([]byte, gcmBlockSize)
+	binary.BigEndian.PutUint64(out[:8], yHigh)
+	binary.BigEndian.PutUint64(out[8:], yLow)
+	return out
+}
+
+// Y * H, алгоритм 1 из SP 800-38D
+func (g *gHash) mul(xHigh, xLow uint64) (uint64, uint64) {
+	var zHigh, zLow uint64
+	vHigh, vLow := g.hHigh, g.hLow
+
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = (xHigh >> (63 - i)) & 1
+		} else {
+			bit = (xLow >> (127 - i)) & 1
+		}
+		if bit == 1 {
+			zHigh ^= vHigh
+			zLow ^= vLow
+		}
+
+		lsb := vLow & 1
+		vLow = (vLow >> 1) | (vHigh << 63)
+		vHigh >>= 1
+		if lsb == 1 {
+			vHigh ^= 0xE100000000000000 // R = 11100001 || 0^120
+		}
+	}
+	return zHigh, zLow
+}
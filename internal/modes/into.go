@@ -0,0 +1,86 @@
+package modes
+
+import (
+	"runtime"
+	"sync"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// блоков на одну горутину в параллельных режимах (ECB, расшифровка CBC, CTR, RandomDelta):
+// горутина и буферы - на кусок, а не на блок
+const parallelChunkBlocks = 256
+
+// encryptBlock пишет E(K, src) в dst: через ciphers.BlockCipherInto без аллокаций,
+// иначе через Encrypt с копированием; dst и src могут совпадать
+func encryptBlock(cipher ciphers.SymmetricCipher, dst, src []byte) error {
+	if bc, ok := cipher.(ciphers.BlockCipherInto); ok {
+		return bc.EncryptInto(dst, src)
+	}
+	out, err := cipher.Encrypt(src)
+	if err != nil {
+		return err
+	}
+	copy(dst, out)
+	return nil
+}
+
+func decryptBlock(cipher ciphers.SymmetricCipher, dst, src []byte) error {
+	if bc, ok := cipher.(ciphers.BlockCipherInto); ok {
+		return bc.DecryptInto(dst, src)
+	}
+	out, err := cipher.Decrypt(src)
+	if err != nil {
+		return err
+	}
+	copy(dst, out)
+	return nil
+}
+
+func (ctx *SymmetricContext) encryptBlock(dst, src []byte) error {
+	return encryptBlock(ctx.cipher, dst, src)
+}
+
+func (ctx *SymmetricContext) decryptBlock(dst, src []byte) error {
+	return decryptBlock(ctx.cipher, dst, src)
+}
+
+// xorInto: dst[i] = a[i] ^ b[i] для i < len(dst)
+func xorInto(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// parallelChunks вызывает fn(first, last) для кусков по chunkBlocks блоков из
+// blockCount, не больше NumCPU горутин; возвращает первую ошибку
+func parallelChunks(blockCount, chunkBlocks int, fn func(first, last int) error) error {
+	chunks := (blockCount + chunkBlocks - 1) / chunkBlocks
+	if chunks <= 1 {
+		return fn(0, blockCount)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, chunks)
+	sem := make(chan struct{}, runtime.NumCPU())
+	for i := 0; i < chunks; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			last := (i + 1) * chunkBlocks
+			if last > blockCount {
+				last = blockCount
+			}
+			errs[i] = fn(i*chunkBlocks, last)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package modes
 
 import (
     "crypto-lab/internal/ciphers"
+    "errors"
     "fmt"
 )
 
@@ -38,16 +39,49 @@ func (s *ofbStrategy) NeedsIV() bool { return true }
 
 type ctrStrategy struct{ ctx *SymmetricContext }
 func (s *ctrStrategy) Process(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
-    return s.ctx.processCTR(data, isEncrypt)
+    return s.ctx.processCTR(data, s.ctx.getNonce(), 0)
 }
 func (s *ctrStrategy) NeedsIV() bool { return true }
 
 type randomDeltaStrategy struct{ ctx *SymmetricContext }
 func (s *randomDeltaStrategy) Process(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
-    return s.ctx.processRandomDelta(data, isEncrypt)
+    return s.ctx.processRandomDelta(data, s.ctx.getNonce(), 0)
 }
 func (s *randomDeltaStrategy) NeedsIV() bool { return false }
 
+type gcmStrategy struct{ ctx *SymmetricContext }
+func (s *gcmStrategy) Process(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
+    return s.ctx.processGCM(data, isEncrypt)
+}
+func (s *gcmStrategy) NeedsIV() bool { return false }
+func (s *gcmStrategy) unpadded() {}
+
+type cbcCSStrategy struct {
+    ctx     *SymmetricContext
+    variant ciphers.CipherMode
+}
+func (s *cbcCSStrategy) Process(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
+    return s.ctx.processCBCCS(data, iv, s.variant, isEncrypt)
+}
+func (s *cbcCSStrategy) NeedsIV() bool { return true }
+func (s *cbcCSStrategy) unpadded() {}
+
+type xtsStrategy struct {
+    ctx   *SymmetricContext
+    tweak ciphers.SymmetricCipher
+}
+func (s *xtsStrategy) Process(data []byte, iv []byte, isEncrypt bool) ([]byte, error) {
+    return s.ctx.processXTS(data, iv, s.tweak, isEncrypt)
+}
+func (s *xtsStrategy) NeedsIV() bool { return true }
+func (s *xtsStrategy) unpadded() {}
+
+// стратегия сама работает с данными произвольной длины: паддинг не применяется,
+// а длина результата может отличаться от входа (например, тег GCM)
+type unpaddedStrategy interface {
+    unpadded()
+}
+
 
 func (ctx *SymmetricContext) createModeStrategy() (ciphers.CipherModeStrategy, error) {
     switch ctx.cipherMode {
@@ -65,7 +99,160 @@ func (ctx *SymmetricContext) createModeStrategy() (ciphers.CipherModeStrategy, e
         return &ctrStrategy{ctx: ctx}, nil
     case ciphers.RandomDelta:
         return &randomDeltaStrategy{ctx: ctx}, nil
+    case ciphers.GCM:
+        if ctx.blockSize != gcmBlockSize {
+            return nil, fmt.Errorf("GCM requires 16-byte block cipher, got %d", ctx.blockSize)
+        }
+        return &gcmStrategy{ctx: ctx}, nil
+    case ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3:
+        return &cbcCSStrategy{ctx: ctx, variant: ctx.cipherMode}, nil
+    case ciphers.XTS:
+        return ctx.newXTSStrategy()
     default:
         return nil, fmt.Errorf("unsupported cipher mode: %v", ctx.cipherMode)
     }
 }
+
+
+// состояние потока между чанками (см. stream.go)
+type streamState struct {
+    iv          []byte // обратная связь CBC/PCBC/CFB/OFB
+    nonce       []byte // CTR/RandomDelta
+    blockOffset int    // номер первого блока следующего чанка
+}
+
+// стратегия, умеющая обрабатывать данные по чанкам с переносом состояния
+type streamStrategy interface {
+    processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error)
+}
+
+// стратегия без паддинга: последние holdBack байт потока придерживаются и
+// обрабатываются в finishStream при Close / EOF, остальное идет через processStream.
+// holdBack = -1 - весь поток одним куском (GCM: тег по всему шифртексту)
+type streamFinisher interface {
+    holdBack() int
+    finishStream(st *streamState, tail []byte, isEncrypt bool) ([]byte, error)
+}
+
+// последний блок шифртекста: для encrypt - из out, для decrypt - из in
+func lastCipherBlock(in, out []byte, blockSize int, isEncrypt bool) []byte {
+    if isEncrypt {
+        return out[len(out)-blockSize:]
+    }
+    return in[len(in)-blockSize:]
+}
+
+func (s *ecbStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    return s.ctx.processECB(data, isEncrypt)
+}
+
+func (s *cbcStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    return s.ctx.processCBCStream(st, data, isEncrypt)
+}
+
+func (ctx *SymmetricContext) processCBCStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    out, err := ctx.processCBC(data, st.iv, isEncrypt)
+    if err != nil {
+        return nil, err
+    }
+    // IV = последний Ci
+    copy(st.iv, lastCipherBlock(data, out, ctx.blockSize, isEncrypt))
+    return out, nil
+}
+
+func (s *pcbcStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    out, err := s.ctx.processPCBC(data, st.iv, isEncrypt)
+    if err != nil {
+        return nil, err
+    }
+    // IV = последний Pi XOR Ci, при любом направлении это in XOR out
+    bs := s.ctx.blockSize
+    for j := 0; j < bs; j++ {
+        st.iv[j] = data[len(data)-bs+j] ^ out[len(out)-bs+j]
+    }
+    return out, nil
+}
+
+func (s *cfbStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    out, err := s.ctx.processCFB(data, st.iv, isEncrypt)
+    if err != nil {
+        return nil, err
+    }
+    copy(st.iv, lastCipherBlock(data, out, s.ctx.blockSize, isEncrypt))
+    return out, nil
+}
+
+func (s *ofbStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    out, err := s.ctx.processOFB(data, st.iv, isEncrypt)
+    if err != nil {
+        return nil, err
+    }
+    // IV = последний Ki = in XOR out
+    bs := s.ctx.blockSize
+    for j := 0; j < bs; j++ {
+        st.iv[j] = data[len(data)-bs+j] ^ out[len(out)-bs+j]
+    }
+    return out, nil
+}
+
+func (s *ctrStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    out, err := s.ctx.processCTR(data, st.nonce, st.blockOffset)
+    if err != nil {
+        return nil, err
+    }
+    st.blockOffset += len(data) / s.ctx.blockSize
+    return out, nil
+}
+
+func (s *randomDeltaStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    out, err := s.ctx.processRandomDelta(data, st.nonce, st.blockOffset)
+    if err != nil {
+        return nil, err
+    }
+    st.blockOffset += len(data) / s.ctx.blockSize
+    return out, nil
+}
+
+// GCM: поток целиком в памяти, шифртекст и тег пишутся в Close,
+// при расшифровке тег проверяется до выдачи открытого текста
+func (s *gcmStrategy) holdBack() int { return -1 }
+
+func (s *gcmStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    return nil, errors.New("GCM stream is processed as a whole in finishStream")
+}
+
+func (s *gcmStrategy) finishStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    msgCtx, err := s.ctx.withIVAndNonce(nil, st.nonce)
+    if err != nil {
+        return nil, err
+    }
+    return msgCtx.processGCM(data, isEncrypt)
+}
+
+// CBC-CS: все блоки, кроме двух последних, - обычный CBC; хвост (последний полный
+// и неполный блоки) - кража шифртекста в finishStream
+func (s *cbcCSStrategy) holdBack() int { return 2 * s.ctx.blockSize }
+
+func (s *cbcCSStrategy) processStream(st *streamState, data []byte, isEncrypt bool) ([]byte, error) {
+    return s.ctx.processCBCStream(st, data, isEncrypt)
+}
+
+func (s *cbcCSStrategy) finishStream(st *streamState, tail []byte, isEncrypt bool) ([]byte, error) {
+    bs := s.ctx.blockSize
+    headLen := 0
+    if n := (len(tail) + bs - 1) / bs; n > 2 {
+        headLen = (n - 2) * bs
+    }
+    var head []byte
+    if headLen > 0 {
+        var err error
+        if head, err = s.ctx.processCBCStream(st, tail[:headLen], isEncrypt); err != nil {
+            return nil, err
+        }
+    }
+    last, err := s.ctx.processCBCCS(tail[headLen:], st.iv, s.variant, isEncrypt)
+    if err != nil {
+        return nil, err
+    }
+    return append(head, last...), nil
+}
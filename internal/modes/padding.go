@@ -4,8 +4,22 @@ import (
 	"crypto-lab/internal/ciphers"
 	"errors"
 	"fmt"
+	"io"
 )
 
+// неверный паддинг после расшифровки - обычно неверный ключ или поврежденные данные
+var ErrInvalidPadding = errors.New("invalid padding")
+
+// DebugOutput - куда пишутся отладочные сообщения о паддинге; по умолчанию nil (выключено),
+// CLI включает их флагом -v
+var DebugOutput io.Writer
+
+func debugf(format string, args ...interface{}) {
+	if DebugOutput != nil {
+		fmt.Fprintf(DebugOutput, format, args...)
+	}
+}
+
 func (ctx *SymmetricContext) applyPadding(data []byte) ([]byte, error) {
 	padSize := ctx.blockSize - (len(data) % ctx.blockSize) 
 
@@ -14,7 +28,7 @@ func (ctx *SymmetricContext) applyPadding(data []byte) ([]byte, error) {
 		case ciphers.PKCS7, ciphers.ANSIX923, ciphers.ISO10126:
 			padSize = ctx.blockSize // +1 блок падинга
 		case ciphers.Zeros:
-			fmt.Printf("Zeros padding: data is already block-aligned, no padding added\n")
+			debugf("Zeros padding: data is already block-aligned, no padding added\n")
 			return data, nil // тут ниче не надо
 		}
 	}
@@ -49,7 +63,7 @@ func (ctx *SymmetricContext) applyPadding(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("unknown padding mode: %v", ctx.paddingMode)
 	}
 
-	fmt.Printf("Applied %s padding: %d bytes added\n", ctx.paddingMode, padSize)
+	debugf("Applied %s padding: %d bytes added\n", ctx.paddingMode, padSize)
 	return padded, nil
 }
 
@@ -68,7 +82,7 @@ func (ctx *SymmetricContext) removePadding(data []byte) ([]byte, error) {
 		}
 		if lastNonZero < len(data)-1 { //нашли? - ура паддинг
 			padSize = len(data) - lastNonZero - 1
-			fmt.Printf("Padding removed: %s mode, %d bytes\n", ctx.paddingMode, padSize)
+			debugf("Padding removed: %s mode, %d bytes\n", ctx.paddingMode, padSize)
 			return data[:lastNonZero+1], nil
 		}
 
@@ -77,19 +91,19 @@ func (ctx *SymmetricContext) removePadding(data []byte) ([]byte, error) {
 	case ciphers.PKCS7, ciphers.ANSIX923, ciphers.ISO10126: //тут последний байт - размер паддинга
 		padSize = int(data[len(data)-1])
 		if padSize == 0 || padSize > ctx.blockSize || len(data) < padSize {
-			return nil, fmt.Errorf("invalid padding size: %d", padSize)
+			return nil, fmt.Errorf("%w: size %d", ErrInvalidPadding, padSize)
 		}
 
 		if ctx.paddingMode == ciphers.PKCS7 {
 			for i := len(data) - padSize; i < len(data); i++ {
 				if data[i] != byte(padSize) {
-					return nil, errors.New("invalid PKCS7 padding")
+					return nil, fmt.Errorf("%w: PKCS7", ErrInvalidPadding)
 				}
 			}
 		} else if ctx.paddingMode == ciphers.ANSIX923 {
 			for i := len(data) - padSize; i < len(data)-1; i++ {
 				if data[i] != 0x00 {
-					return nil, errors.New("invalid ANSI X.923 padding")
+					return nil, fmt.Errorf("%w: ANSI X.923", ErrInvalidPadding)
 				}
 			}
 			// последний байт не чекаем, выше чекнули пупупу
@@ -99,6 +113,6 @@ func (ctx *SymmetricContext) removePadding(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("unknown padding mode: %v", ctx.paddingMode)
 	}
 
-	fmt.Printf("Padding removed: %s mode, %d bytes\n", ctx.paddingMode, padSize)
+	debugf("Padding removed: %s mode, %d bytes\n", ctx.paddingMode, padSize)
 	return data[:len(data)-padSize], nil
 }
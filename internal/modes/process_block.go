@@ -13,6 +13,24 @@ func (ctx *SymmetricContext) processBlocks(data []byte, isEncrypt bool) ([]byte,
 		return nil, errors.New("data length must be a multiple of block size")
 	} //длина кратна размеру блока
 
+	currentIV, err := ctx.resolveIV()
+	if err != nil {
+		return nil, err
+	}
+	return ctx.processor.Process(data, currentIV, isEncrypt)
+}
+
+// для режимов без паддинга: длина данных не обязана быть кратной блоку
+func (ctx *SymmetricContext) processUnpadded(data []byte, isEncrypt bool) ([]byte, error) {
+	currentIV, err := ctx.resolveIV()
+	if err != nil {
+		return nil, err
+	}
+	return ctx.processor.Process(data, currentIV, isEncrypt)
+}
+
+// копия IV контекста; если IV не задан - генерим случайный и запоминаем
+func (ctx *SymmetricContext) resolveIV() ([]byte, error) {
 	ctx.mu.RLock() //блок для чтения - много горутин  одноврм читают
 	currentIV := make([]byte, ctx.blockSize)
 	if ctx.iv != nil {
@@ -34,5 +52,5 @@ func (ctx *SymmetricContext) processBlocks(data []byte, isEncrypt bool) ([]byte,
 		}
 		ctx.mu.Unlock()
 	}
-return ctx.processor.Process(data, currentIV, isEncrypt)
+	return currentIV, nil
 }
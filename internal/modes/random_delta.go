@@ -5,16 +5,16 @@ package modes
 import (
 	"encoding/binary"
 	"fmt"
-	"runtime"
-	"sync"
+
+	"crypto-lab/internal/ciphers"
 )
 
-func (ctx *SymmetricContext) processRandomDelta(data []byte, isEncrypt bool) ([]byte, error) {
+// startBlock - номер первого блока data в общем потоке (для чанков)
+func (ctx *SymmetricContext) processRandomDelta(data []byte, nonce []byte, startBlock int) ([]byte, error) {
 	if ctx.blockSize <= 0 {
 		return nil, fmt.Errorf("RandomDelta: invalid block size %d", ctx.blockSize)
 	}
 
-	nonce := ctx.getNonce()
 	if len(nonce) >= ctx.blockSize {
 		return nil, fmt.Errorf("RandomDelta: nonce length (%d) >= block size (%d)", len(nonce), ctx.blockSize)
 	}
@@ -28,65 +28,48 @@ func (ctx *SymmetricContext) processRandomDelta(data []byte, isEncrypt bool) ([]
 	blockCount := (len(data) + ctx.blockSize - 1) / ctx.blockSize
 	seed := uint64(ctx.getRandomDeltaSeed())
 
-	// ограничение на количество горутин
-	maxWorkers := runtime.GOMAXPROCS(0) * 2
-	if maxWorkers < 1 {
-		maxWorkers = 1
+	if bc, ok := ctx.cipher.(ciphers.BatchCipher); ok {
+		return ctx.batchKeystream(bc, data, blockCount, func(counterBlock []byte, i int) {
+			randomDeltaCounter(counterBlock, nonce, seed+uint64(startBlock+i), counterBytes)
+		})
 	}
-	sem := make(chan struct{}, maxWorkers)
-
-	var wg sync.WaitGroup
-	errCh := make(chan error, 1)
-
-	//параллельно с блоками
-	for blockIndex := 0; blockIndex < blockCount; blockIndex++ {
-		offset := blockIndex * ctx.blockSize
-		wg.Add(1)
-
-		sem <- struct{}{}
-
-		go func(blockIndex, offset int) {
-			defer wg.Done()
-			defer func() { <-sem }()
 
+	// куски блоков параллельно (не больше NumCPU горутин), буферы - на кусок
+	bs := ctx.blockSize
+	err := parallelChunks(blockCount, parallelChunkBlocks, func(first, last int) error {
+		counterBlock := make([]byte, bs)
+		keystream := make([]byte, bs)
+		for blockIndex := first; blockIndex < last; blockIndex++ {
 			// cnt_val = seed + i
-			counterValue := seed + uint64(blockIndex)
-
-			// cnt_block = nonce || cnt_val (в big-endian)
-			counterBlock := make([]byte, ctx.blockSize)
-			copy(counterBlock, nonce)
-
-			var tmp [8]byte
-			binary.BigEndian.PutUint64(tmp[:], counterValue)
-			copy(counterBlock[len(counterBlock)-counterBytes:], tmp[8-counterBytes:])
+			counterValue := seed + uint64(startBlock+blockIndex)
+			randomDeltaCounter(counterBlock, nonce, counterValue, counterBytes)
 
 			//Ki = E(K, cnt_block)
-			keystream, err := ctx.cipher.Encrypt(counterBlock)
-			if err != nil {
-				select {
-				case errCh <- fmt.Errorf("RandomDelta block %d: %w", blockIndex, err):
-				default:
-				}
-				return
+			if err := ctx.encryptBlock(keystream, counterBlock); err != nil {
+				return fmt.Errorf("RandomDelta block %d: %w", blockIndex, err)
 			}
 
-			// Ci = Pi XOR Ki
-			blockSize := ctx.blockSize
-			if offset+blockSize > len(data) {
-				blockSize = len(data) - offset // последний блок может быть неполный
-			}
-			for j := 0; j < blockSize; j++ {
-				result[offset+j] = data[offset+j] ^ keystream[j]
+			// Ci = Pi XOR Ki, последний блок может быть неполный
+			offset := blockIndex * bs
+			end := offset + bs
+			if end > len(data) {
+				end = len(data)
 			}
-		}(blockIndex, offset)
+			xorInto(result[offset:end], data[offset:end], keystream)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	wg.Wait()
+// cnt_block = nonce || cnt_val (в big-endian)
+func randomDeltaCounter(counterBlock, nonce []byte, counterValue uint64, counterBytes int) {
+	copy(counterBlock, nonce)
 
-	select {
-	case err := <-errCh:
-		return nil, err
-	default:
-		return result, nil
-	}
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], counterValue)
+	copy(counterBlock[len(counterBlock)-counterBytes:], tmp[8-counterBytes:])
 }
@@ -0,0 +1,302 @@
+package modes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"crypto-lab/internal/ciphers"
+)
+
+// размер чанка по умолчанию; память потока ограничена ~2 чанками независимо от размера входа
+const DefaultStreamChunkSize = 64 * 1024
+
+// encryptWriter шифрует данные чанками; паддинг добавляется только в Close.
+// Для режимов без паддинга (streamFinisher) хвост потока придерживается до Close
+type encryptWriter struct {
+	ctx      *SymmetricContext
+	w        io.Writer
+	strategy streamStrategy
+	finisher streamFinisher // nil - режим с паддингом
+	hold     int            // байт конца потока, которые не шифруются до Close; -1 - весь поток
+	state    *streamState
+	buf      []byte
+	closed   bool
+	err      error
+}
+
+// decryptReader расшифровывает поток чанками; последний блок придерживается до EOF,
+// чтобы снять с него паддинг. Для режимов без паддинга придерживается хвост шифртекста
+type decryptReader struct {
+	ctx      *SymmetricContext
+	r        io.Reader
+	strategy streamStrategy
+	finisher streamFinisher
+	hold     int
+	state    *streamState
+	in       []byte
+	out      []byte // готовый к выдаче открытый текст
+	held     []byte // последний расшифрованный блок (с паддингом) или хвост шифртекста
+	done     bool
+	err      error
+}
+
+func (ctx *SymmetricContext) NewEncryptWriter(w io.Writer) (io.WriteCloser, error) {
+	return ctx.NewEncryptWriterSize(w, DefaultStreamChunkSize)
+}
+
+// chunkSize округляется вниз до кратного размеру блока
+func (ctx *SymmetricContext) NewEncryptWriterSize(w io.Writer, chunkSize int) (io.WriteCloser, error) {
+	strategy, state, err := ctx.newStream()
+	if err != nil {
+		return nil, err
+	}
+	return ctx.newEncryptWriter(w, chunkSize, strategy, state), nil
+}
+
+func (ctx *SymmetricContext) newEncryptWriter(w io.Writer, chunkSize int, strategy streamStrategy, state *streamState) *encryptWriter {
+	finisher, hold := streamHold(strategy)
+	return &encryptWriter{
+		ctx:      ctx,
+		w:        w,
+		strategy: strategy,
+		finisher: finisher,
+		hold:     hold,
+		state:    state,
+		buf:      make([]byte, 0, ctx.alignChunkSize(chunkSize)+max(hold, 0)),
+	}
+}
+
+func (ctx *SymmetricContext) NewDecryptReader(r io.Reader) (io.Reader, error) {
+	return ctx.NewDecryptReaderSize(r, DefaultStreamChunkSize)
+}
+
+func (ctx *SymmetricContext) NewDecryptReaderSize(r io.Reader, chunkSize int) (io.Reader, error) {
+	strategy, state, err := ctx.newStream()
+	if err != nil {
+		return nil, err
+	}
+	finisher, hold := streamHold(strategy)
+	return &decryptReader{
+		ctx:      ctx,
+		r:        r,
+		strategy: strategy,
+		finisher: finisher,
+		hold:     hold,
+		state:    state,
+		in:       make([]byte, ctx.alignChunkSize(chunkSize)),
+	}, nil
+}
+
+// IV и nonce фиксируются один раз на весь поток
+func (ctx *SymmetricContext) newStream() (streamStrategy, *streamState, error) {
+	if ctx.cipherMode == ciphers.XTS {
+		return nil, nil, ErrXTSNotStreamable
+	}
+	strategy, ok := ctx.processor.(streamStrategy)
+	if !ok {
+		return nil, nil, fmt.Errorf("streaming is not supported for cipher mode %v", ctx.cipherMode)
+	}
+
+	iv, err := ctx.resolveIV()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := ctx.getNonce()
+	if ctx.cipherMode == ciphers.GCM {
+		// как в Encrypt: nonce из params[0], иначе случайный стандартной длины
+		if nonce = ctx.getGCMNonce(); len(nonce) == 0 {
+			if nonce, err = GenerateRandomBytes(gcmStandardNonce); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return strategy, &streamState{iv: iv, nonce: nonce}, nil
+}
+
+func streamHold(strategy streamStrategy) (streamFinisher, int) {
+	if f, ok := strategy.(streamFinisher); ok {
+		return f, f.holdBack()
+	}
+	return nil, 0
+}
+
+func (ctx *SymmetricContext) alignChunkSize(chunkSize int) int {
+	chunkSize -= chunkSize % ctx.blockSize
+	if chunkSize < ctx.blockSize {
+		chunkSize = ctx.blockSize
+	}
+	return chunkSize
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encrypt writer")
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+
+		// полный чанк шифруем сразу; хвост (< чанка) ждет следующий Write или Close
+		if len(e.buf) == cap(e.buf) {
+			if err := e.flushFull(); err != nil {
+				e.err = err
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// шифрует буфер, кроме придерживаемого хвоста; при hold = -1 только растит буфер
+func (e *encryptWriter) flushFull() error {
+	if e.hold < 0 {
+		e.buf = slices.Grow(e.buf, len(e.buf))
+		return nil
+	}
+	n := len(e.buf) - e.hold
+	if err := e.flush(e.buf[:n]); err != nil {
+		return err
+	}
+	e.buf = e.buf[:copy(e.buf, e.buf[n:])]
+	return nil
+}
+
+// Close дописывает паддинг и последний чанк; нижележащий writer не закрывается
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.err != nil {
+		return e.err
+	}
+
+	if e.finisher != nil {
+		last, err := e.finisher.finishStream(e.state, e.buf, true)
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(last)
+		return err
+	}
+
+	padded, err := e.ctx.applyPadding(e.buf)
+	if err != nil {
+		return err
+	}
+	return e.flush(padded)
+}
+
+func (e *encryptWriter) flush(chunk []byte) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+	encrypted, err := e.strategy.processStream(e.state, chunk, true)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(encrypted)
+	return err
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.fill()
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptReader) fill() error {
+	n, err := io.ReadFull(d.r, d.in)
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		return err
+	}
+
+	chunk := d.in[:n]
+	if d.finisher != nil {
+		return d.fillUnpadded(chunk, final)
+	}
+	if len(chunk)%d.ctx.blockSize != 0 {
+		return errors.New("data length must be a multiple of block size")
+	}
+
+	var plain []byte
+	if len(chunk) > 0 {
+		plain, err = d.strategy.processStream(d.state, chunk, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	pending := append(d.held, plain...)
+	if !final {
+		split := len(pending) - d.ctx.blockSize
+		d.out = pending[:split]
+		d.held = append([]byte(nil), pending[split:]...)
+		return nil
+	}
+
+	d.done = true
+	d.held = nil
+	if len(pending) == 0 {
+		return errors.New("data is empty")
+	}
+	// паддинг не длиннее блока - снимаем только с последнего
+	split := len(pending) - d.ctx.blockSize
+	last, err := d.ctx.removePadding(pending[split:])
+	if err != nil {
+		return err
+	}
+	d.out = append(pending[:split], last...)
+	return nil
+}
+
+// режим без паддинга: held - сырой шифртекст; все, кроме последних hold байт,
+// расшифровывается сразу, хвост - в finishStream по EOF
+func (d *decryptReader) fillUnpadded(chunk []byte, final bool) error {
+	d.held = append(d.held, chunk...)
+	if !final {
+		if n := len(d.held) - d.hold; d.hold >= 0 && n > 0 {
+			plain, err := d.strategy.processStream(d.state, d.held[:n], false)
+			if err != nil {
+				return err
+			}
+			d.out = plain
+			d.held = d.held[:copy(d.held, d.held[n:])]
+		}
+		return nil
+	}
+
+	d.done = true
+	plain, err := d.finisher.finishStream(d.state, d.held, false)
+	d.held = nil
+	if err != nil {
+		return err
+	}
+	d.out = plain
+	return nil
+}
@@ -0,0 +1,172 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/mac"
+	"crypto-lab/internal/modes"
+)
+
+var everyMode = []ciphers.CipherMode{
+	ciphers.ECB, ciphers.CBC, ciphers.PCBC, ciphers.CFB, ciphers.OFB, ciphers.CTR,
+	ciphers.RandomDelta, ciphers.GCM, ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3, ciphers.XTS,
+}
+
+var macKey = []byte("0123456789abcdef-mac-key")
+
+// DEAL-128: блок 16 байт, подходит для GCM и XTS; IV и nonce не заданы -
+// AuthenticatedContext генерирует их на каждое сообщение
+func newDEALAuthContext(t *testing.T, mode ciphers.CipherMode) *modes.AuthenticatedContext {
+	t.Helper()
+
+	cipher, _ := deal.NewDEALCipher(16)
+	if err := cipher.SetSymmetricKey(bytes.Repeat([]byte{0x42}, 16)); err != nil {
+		t.Fatal(err)
+	}
+	var params []interface{}
+	if mode == ciphers.XTS {
+		tweak, _ := deal.NewDEALCipher(16)
+		tweak.SetSymmetricKey(bytes.Repeat([]byte{0x24}, 16))
+		params = append(params, modes.XTSParams{TweakCipher: tweak})
+	}
+
+	ctx, err := modes.NewSymmetricContext(cipher, mode, ciphers.PKCS7, nil, params...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actx, err := modes.NewAuthenticatedContext(ctx, macKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return actx
+}
+
+func TestAuthenticatedRoundTripAllModes(t *testing.T) {
+	messages := [][]byte{
+		[]byte("exactly sixteen!"),
+		[]byte("Encrypt-then-MAC over every cipher mode of the lab"),
+	}
+
+	for _, mode := range everyMode {
+		t.Run(mode.String(), func(t *testing.T) {
+			actx := newDEALAuthContext(t, mode)
+			for _, msg := range messages {
+				sealed, err := actx.Encrypt(msg)
+				if err != nil {
+					t.Fatalf("encrypt: %v", err)
+				}
+				opened, err := actx.Decrypt(sealed)
+				if err != nil {
+					t.Fatalf("decrypt: %v", err)
+				}
+				if !bytes.Equal(opened, msg) {
+					t.Errorf("expected %q, got %q", msg, opened)
+				}
+
+				if mode != ciphers.ECB {
+					again, _ := actx.Encrypt(msg)
+					if bytes.Equal(again, sealed) {
+						t.Errorf("two messages share IV/nonce")
+					}
+				}
+			}
+		})
+	}
+}
+
+// любой измененный бит - в заголовке, шифртексте или теге - отвергается
+// до расшифровки, поэтому ошибок паддинга снаружи не видно
+func TestAuthenticatedRejectsTampering(t *testing.T) {
+	for _, mode := range everyMode {
+		t.Run(mode.String(), func(t *testing.T) {
+			actx := newDEALAuthContext(t, mode)
+			sealed, err := actx.Encrypt([]byte("attack at dawn, bring the padding oracle"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range sealed {
+				tampered := append([]byte(nil), sealed...)
+				tampered[i] ^= 0x01
+				_, err := actx.Decrypt(tampered)
+				if !errors.Is(err, modes.ErrAuthenticationFailed) {
+					t.Fatalf("byte %d: expected ErrAuthenticationFailed, got %v", i, err)
+				}
+			}
+
+			for _, cut := range []int{1, 16, len(sealed)} {
+				if _, err := actx.Decrypt(sealed[:len(sealed)-cut]); !errors.Is(err, modes.ErrAuthenticationFailed) {
+					t.Errorf("truncated by %d: expected ErrAuthenticationFailed, got %v", cut, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticatedWrongMACKey(t *testing.T) {
+	actx := newDEALAuthContext(t, ciphers.CBC)
+	sealed, _ := actx.Encrypt([]byte("secret"))
+
+	cipher, _ := deal.NewDEALCipher(16)
+	cipher.SetSymmetricKey(bytes.Repeat([]byte{0x42}, 16))
+	ctx, _ := modes.NewSymmetricContext(cipher, ciphers.CBC, ciphers.PKCS7, nil)
+	other, _ := modes.NewAuthenticatedContext(ctx, []byte("another mac key, 16+ bytes"))
+	if _, err := other.Decrypt(sealed); !errors.Is(err, modes.ErrAuthenticationFailed) {
+		t.Errorf("expected ErrAuthenticationFailed, got %v", err)
+	}
+}
+
+// CMAC над DES с отдельным ключом вместо HMAC по умолчанию
+func TestAuthenticatedWithCMAC(t *testing.T) {
+	for _, mode := range allModes {
+		ctx := newDESContext(t, mode, ciphers.PKCS7)
+		actx, err := modes.NewAuthenticatedContextWithMAC(ctx, func() (mac.MAC, error) {
+			macCipher := des.NewDES()
+			if err := macCipher.SetSymmetricKey([]byte{0x0E, 0x32, 0x92, 0x32, 0xEA, 0x6D, 0x0D, 0x73}); err != nil {
+				return nil, err
+			}
+			return mac.NewCMAC(macCipher)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg := []byte("DES in every mode with CMAC")
+		sealed, err := actx.Encrypt(msg)
+		if err != nil {
+			t.Fatalf("%v: %v", mode, err)
+		}
+		opened, err := actx.Decrypt(sealed)
+		if err != nil || !bytes.Equal(opened, msg) {
+			t.Errorf("%v: round trip failed: %q, %v", mode, opened, err)
+		}
+
+		sealed[len(sealed)/2] ^= 0x80
+		if _, err := actx.Decrypt(sealed); !errors.Is(err, modes.ErrAuthenticationFailed) {
+			t.Errorf("%v: expected ErrAuthenticationFailed, got %v", mode, err)
+		}
+	}
+}
+
+func TestAuthenticatedContextErrors(t *testing.T) {
+	ctx := newDESContext(t, ciphers.CBC, ciphers.PKCS7)
+	if _, err := modes.NewAuthenticatedContext(ctx, []byte("short")); err == nil {
+		t.Errorf("expected error for short MAC key")
+	}
+	if _, err := modes.NewAuthenticatedContext(nil, macKey); err == nil {
+		t.Errorf("expected error for nil context")
+	}
+
+	// сообщение другого режима с верным тегом не расшифровывается молча
+	ecb, _ := modes.NewAuthenticatedContext(newDESContext(t, ciphers.ECB, ciphers.PKCS7), macKey)
+	cbc, _ := modes.NewAuthenticatedContext(ctx, macKey)
+	sealed, _ := ecb.Encrypt([]byte("mode confusion"))
+	if _, err := cbc.Decrypt(sealed); err == nil {
+		t.Errorf("expected mode mismatch error")
+	}
+}
@@ -0,0 +1,128 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/rijndael"
+	"crypto-lab/internal/modes"
+)
+
+// RFC 3962, Appendix B: AES-128 CTS (вариант CBC-CS3), IV = 0
+func TestCBCCS3KnownVectors(t *testing.T) {
+	key := unhex("636869636b656e207465726979616b69")
+	tests := []struct {
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			plaintext:  "4920776f756c64206c696b652074686520",
+			ciphertext: "c6353568f2bf8cb4d8a580362da7ff7f97",
+		},
+		{
+			plaintext:  "4920776f756c64206c696b65207468652047656e6572616c20476175277320",
+			ciphertext: "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5",
+		},
+		{
+			plaintext:  "4920776f756c64206c696b65207468652047656e6572616c2047617527732043",
+			ciphertext: "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584",
+		},
+	}
+
+	aes, _ := rijndael.NewAES(16)
+	if err := aes.SetSymmetricKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		plaintext := unhex(tt.plaintext)
+		t.Run(fmt.Sprintf("len%d", len(plaintext)), func(t *testing.T) {
+			ctx, err := modes.NewSymmetricContext(aes, ciphers.CBCCS3, ciphers.PKCS7, make([]byte, 16))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, err := ctx.Encrypt(plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if !bytes.Equal(ciphertext, unhex(tt.ciphertext)) {
+				t.Errorf("expected %s\ngot      %x", tt.ciphertext, ciphertext)
+			}
+
+			decrypted, err := ctx.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("round-trip failed: got %x", decrypted)
+			}
+		})
+	}
+}
+
+// длина сохраняется; CS1/CS2/CS3 отличаются только порядком двух последних блоков
+func TestCBCCSVariants(t *testing.T) {
+	aes, _ := rijndael.NewAES(16)
+	aes.SetSymmetricKey(unhex("000102030405060708090a0b0c0d0e0f"))
+	iv := unhex("0f0e0d0c0b0a09080706050403020100")
+
+	for _, size := range []int{16, 17, 31, 32, 33, 47, 48, 100} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i)
+		}
+
+		results := map[ciphers.CipherMode][]byte{}
+		for _, mode := range []ciphers.CipherMode{ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3} {
+			ctx, err := modes.NewSymmetricContext(aes, mode, ciphers.PKCS7, iv)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext, err := ctx.Encrypt(data)
+			if err != nil {
+				t.Fatalf("%s/%d: Encrypt failed: %v", mode, size, err)
+			}
+			if len(ciphertext) != size {
+				t.Errorf("%s/%d: ciphertext length %d", mode, size, len(ciphertext))
+			}
+
+			decrypted, err := ctx.Decrypt(ciphertext)
+			if err != nil {
+				t.Fatalf("%s/%d: Decrypt failed: %v", mode, size, err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Errorf("%s/%d: round-trip failed", mode, size)
+			}
+			results[mode] = ciphertext
+		}
+
+		// при кратной длине CS1 и CS2 совпадают с обычным CBC без паддинга
+		if size%16 == 0 {
+			if !bytes.Equal(results[ciphers.CBCCS1], results[ciphers.CBCCS2]) {
+				t.Errorf("%d: CS1 and CS2 must match for aligned input", size)
+			}
+		} else if !bytes.Equal(results[ciphers.CBCCS2], results[ciphers.CBCCS3]) {
+			t.Errorf("%d: CS2 and CS3 must match for unaligned input", size)
+		}
+	}
+}
+
+func TestCBCCSTooShort(t *testing.T) {
+	aes, _ := rijndael.NewAES(16)
+	aes.SetSymmetricKey(make([]byte, 16))
+	ctx, _ := modes.NewSymmetricContext(aes, ciphers.CBCCS1, ciphers.PKCS7, make([]byte, 16))
+
+	for _, size := range []int{0, 1, 15} {
+		if _, err := ctx.Encrypt(make([]byte, size)); !errors.Is(err, modes.ErrMessageTooShort) {
+			t.Errorf("Encrypt %d bytes: expected ErrMessageTooShort, got %v", size, err)
+		}
+		if _, err := ctx.Decrypt(make([]byte, size)); !errors.Is(err, modes.ErrMessageTooShort) {
+			t.Errorf("Decrypt %d bytes: expected ErrMessageTooShort, got %v", size, err)
+		}
+	}
+}
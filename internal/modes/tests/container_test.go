@@ -0,0 +1,289 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/modes"
+)
+
+func TestFileHeaderRoundTrip(t *testing.T) {
+	h := &modes.FileHeader{
+		CipherID:  ciphers.CipherDEAL,
+		KeySize:   24,
+		BlockSize: 16,
+		Mode:      ciphers.RandomDelta,
+		Padding:   ciphers.ISO10126,
+		Nonce:     []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		HasSeed:   true,
+		Seed:      -42,
+		Salt:      bytes.Repeat([]byte{0xAA}, 16),
+		MACTag:    bytes.Repeat([]byte{0xBB}, 32),
+	}
+
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	// за заголовком идет шифртекст - он не должен быть съеден
+	r := bytes.NewReader(append(data, 0xCC))
+	parsed, err := modes.ReadFileHeader(r)
+	if err != nil {
+		t.Fatalf("ReadFileHeader failed: %v", err)
+	}
+	if r.Len() != 1 {
+		t.Errorf("Expected 1 byte left after header, got %d", r.Len())
+	}
+
+	if parsed.Version != modes.FileFormatVersion || parsed.CipherID != h.CipherID ||
+		parsed.KeySize != h.KeySize || parsed.BlockSize != h.BlockSize ||
+		parsed.Mode != h.Mode || parsed.Padding != h.Padding ||
+		!parsed.HasSeed || parsed.Seed != h.Seed || parsed.IV != nil ||
+		!bytes.Equal(parsed.Nonce, h.Nonce) || !bytes.Equal(parsed.Salt, h.Salt) ||
+		!bytes.Equal(parsed.MACTag, h.MACTag) {
+		t.Errorf("header mismatch:\nwrote: %+v\nread:  %+v", h, parsed)
+	}
+}
+
+func TestFileHeaderErrors(t *testing.T) {
+	h := &modes.FileHeader{BlockSize: 8, Mode: ciphers.CBC, IV: make([]byte, 8)}
+	data, _ := h.MarshalBinary()
+
+	// обрезанный на любой длине
+	for n := 0; n < len(data); n++ {
+		if _, err := modes.ReadFileHeader(bytes.NewReader(data[:n])); !errors.Is(err, modes.ErrTruncatedHeader) {
+			t.Errorf("length %d: expected ErrTruncatedHeader, got %v", n, err)
+		}
+	}
+
+	bad := append([]byte(nil), data...)
+	bad[0] = 'X'
+	if _, err := modes.ReadFileHeader(bytes.NewReader(bad)); !errors.Is(err, modes.ErrBadMagic) {
+		t.Errorf("expected ErrBadMagic, got %v", err)
+	}
+
+	bad = append([]byte(nil), data...)
+	bad[4] = 99
+	var versionErr *modes.UnsupportedVersionError
+	if _, err := modes.ReadFileHeader(bytes.NewReader(bad)); !errors.As(err, &versionErr) {
+		t.Errorf("expected UnsupportedVersionError, got %v", err)
+	} else if versionErr.Version != 99 {
+		t.Errorf("expected version 99 in error, got %d", versionErr.Version)
+	}
+}
+
+// режимы, которые пишутся в файл; GCM - только с 16-байтным блоком
+var fileModes = append(append([]ciphers.CipherMode(nil), allModes...),
+	ciphers.GCM, ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3)
+
+// для GCM DEAL-128, для остальных режимов DES
+func newFileCipher(t *testing.T, mode ciphers.CipherMode) ciphers.SymmetricCipher {
+	t.Helper()
+	if mode == ciphers.GCM {
+		d, _ := deal.NewDEALCipher(16)
+		if err := d.SetSymmetricKey(bytes.Repeat([]byte{0x5A}, 16)); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	c := des.NewDES()
+	if err := c.SetSymmetricKey([]byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// для расшифровки достаточно ключа: режим, паддинг, IV и nonce берутся из файла
+func TestDecryptFileSelfDescribing(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plain.txt")
+	encryptedPath := filepath.Join(dir, "plain.txt.enc")
+	decryptedPath := filepath.Join(dir, "plain.txt.dec")
+
+	data := []byte("self-describing container: no out-of-band IV needed")
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range fileModes {
+		t.Run(mode.String(), func(t *testing.T) {
+			// IV и nonce не заданы - генерируются случайно
+			encCtx, err := modes.NewSymmetricContext(newFileCipher(t, mode), mode, ciphers.ANSIX923, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := encCtx.EncryptFile(inputPath, encryptedPath); err != nil {
+				t.Fatalf("EncryptFile failed: %v", err)
+			}
+			if mode == ciphers.CBCCS1 || mode == ciphers.CBCCS2 || mode == ciphers.CBCCS3 {
+				// кража шифртекста: за заголовком ровно len(data) байт
+				encrypted, _ := os.ReadFile(encryptedPath)
+				r := bytes.NewReader(encrypted)
+				if _, err := modes.ReadFileHeader(r); err != nil || r.Len() != len(data) {
+					t.Errorf("ciphertext %d bytes, expected %d (%v)", r.Len(), len(data), err)
+				}
+			}
+
+			decCtx, err := modes.NewSymmetricContext(newFileCipher(t, mode), ciphers.ECB, ciphers.PKCS7, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := decCtx.DecryptFile(encryptedPath, decryptedPath); err != nil {
+				t.Fatalf("DecryptFile failed: %v", err)
+			}
+
+			decrypted, _ := os.ReadFile(decryptedPath)
+			if !bytes.Equal(decrypted, data) {
+				t.Errorf("expected %q, got %q", data, decrypted)
+			}
+		})
+	}
+}
+
+// длина тега GCM берется из заголовка, AAD - из контекста расшифровки;
+// тег проверяется до записи выхода
+func TestEncryptFileGCM(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plain.txt")
+	encryptedPath := filepath.Join(dir, "plain.txt.enc")
+	tamperedPath := filepath.Join(dir, "tampered.enc")
+	outputPath := filepath.Join(dir, "out.txt")
+
+	data := bytes.Repeat([]byte("authenticated file "), 20)
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	aad := modes.GCMParams{AAD: []byte("file v1")}
+	encCtx, _ := modes.NewSymmetricContext(newFileCipher(t, ciphers.GCM), ciphers.GCM, ciphers.PKCS7, nil,
+		nil, modes.GCMParams{AAD: aad.AAD, TagSize: 12})
+	if err := encCtx.EncryptFile(inputPath, encryptedPath); err != nil {
+		t.Fatalf("EncryptFile failed: %v", err)
+	}
+
+	encrypted, _ := os.ReadFile(encryptedPath)
+	r := bytes.NewReader(encrypted)
+	h, err := modes.ReadFileHeader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.GCMTagSize != 12 || len(h.Nonce) != 12 || r.Len() != len(data)+12 {
+		t.Errorf("header tag %d, nonce %d bytes, body %d bytes", h.GCMTagSize, len(h.Nonce), r.Len())
+	}
+
+	decCtx, _ := modes.NewSymmetricContext(newFileCipher(t, ciphers.GCM), ciphers.ECB, ciphers.PKCS7, nil, nil, aad)
+	if err := decCtx.DecryptFile(encryptedPath, outputPath); err != nil {
+		t.Fatalf("DecryptFile failed: %v", err)
+	}
+	if got, _ := os.ReadFile(outputPath); !bytes.Equal(got, data) {
+		t.Error("GCM file round trip failed")
+	}
+
+	noAAD, _ := modes.NewSymmetricContext(newFileCipher(t, ciphers.GCM), ciphers.ECB, ciphers.PKCS7, nil)
+	if err := noAAD.DecryptFile(encryptedPath, filepath.Join(dir, "no-aad")); !errors.Is(err, modes.ErrAuthenticationFailed) {
+		t.Errorf("missing AAD: expected ErrAuthenticationFailed, got %v", err)
+	}
+
+	encrypted[len(encrypted)-1] ^= 1
+	os.WriteFile(tamperedPath, encrypted, 0644)
+	if err := decCtx.DecryptFile(tamperedPath, filepath.Join(dir, "tampered")); !errors.Is(err, modes.ErrAuthenticationFailed) {
+		t.Errorf("tampered tag: expected ErrAuthenticationFailed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tampered")); !os.IsNotExist(err) {
+		t.Error("output created for tampered file")
+	}
+}
+
+func TestDecryptFileCipherMismatch(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plain.txt")
+	encryptedPath := filepath.Join(dir, "plain.txt.enc")
+	if err := os.WriteFile(inputPath, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newDESContext(t, ciphers.CBC, ciphers.PKCS7)
+	if err := ctx.EncryptFile(inputPath, encryptedPath); err != nil {
+		t.Fatal(err)
+	}
+
+	tdes := des.NewTripleDES()
+	tdes.SetSymmetricKey(make([]byte, 24))
+	tdesCtx, _ := modes.NewSymmetricContext(tdes, ciphers.CBC, ciphers.PKCS7, nil)
+	if err := tdesCtx.DecryptFile(encryptedPath, filepath.Join(dir, "out")); err == nil {
+		t.Errorf("expected cipher mismatch error")
+	}
+
+	// файл без заголовка
+	if err := ctx.DecryptFile(inputPath, filepath.Join(dir, "out")); !errors.Is(err, modes.ErrBadMagic) {
+		t.Errorf("expected ErrBadMagic for raw file, got %v", err)
+	}
+}
+
+// плохой, обрезанный файл или неверный ключ не трогают существующий выход
+// и не оставляют временных файлов
+func TestDecryptFileKeepsOutputOnError(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plain.txt")
+	encryptedPath := filepath.Join(dir, "plain.txt.enc")
+	truncatedPath := filepath.Join(dir, "truncated.enc")
+	outputPath := filepath.Join(dir, "out.txt")
+	existing := []byte("existing output must survive")
+
+	if err := os.WriteFile(inputPath, bytes.Repeat([]byte("secret data "), 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := newDESContext(t, ciphers.CBC, ciphers.PKCS7)
+	if err := ctx.EncryptFile(inputPath, encryptedPath); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, _ := os.ReadFile(encryptedPath)
+	os.WriteFile(truncatedPath, encrypted[:10], 0644)
+
+	wrongKey := des.NewDES()
+	wrongKey.SetSymmetricKey([]byte{0x0E, 0x32, 0x92, 0x32, 0xEA, 0x6D, 0x0D, 0x73})
+	wrongCtx, _ := modes.NewSymmetricContext(wrongKey, ciphers.ECB, ciphers.PKCS7, nil)
+
+	cases := []struct {
+		name  string
+		ctx   *modes.SymmetricContext
+		input string
+	}{
+		{"bad magic", ctx, inputPath},
+		{"truncated header", ctx, truncatedPath},
+		{"wrong key", wrongCtx, encryptedPath},
+	}
+	for _, tc := range cases {
+		if err := os.WriteFile(outputPath, existing, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := tc.ctx.DecryptFile(tc.input, outputPath); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+		if got, _ := os.ReadFile(outputPath); !bytes.Equal(got, existing) {
+			t.Errorf("%s: output file overwritten", tc.name)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		for _, e := range entries {
+			t.Log(e.Name())
+		}
+		t.Errorf("expected 4 files in dir, got %d", len(entries))
+	}
+
+	if err := ctx.DecryptFile(encryptedPath, outputPath); err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := os.ReadFile(inputPath)
+	if got, _ := os.ReadFile(outputPath); !bytes.Equal(got, plain) {
+		t.Error("successful decryption did not replace output")
+	}
+}
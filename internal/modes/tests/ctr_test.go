@@ -0,0 +1,49 @@
+package modes
+
+import (
+	"bytes"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/modes"
+)
+
+// гамма та же, что у Encrypt в CTR, но без паддинга: длина сохраняется
+func TestXORKeyStream(t *testing.T) {
+	d, _ := deal.NewDEALCipher(32)
+	d.SetSymmetricKey(bytes.Repeat([]byte{0x42}, 32))
+	nonce := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	ctx, err := modes.NewSymmetricContext(d, ciphers.CTR, ciphers.PKCS7, nil, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, 16, 37} {
+		data := bytes.Repeat([]byte{0xA5}, size)
+		got, err := ctx.XORKeyStream(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != size {
+			t.Errorf("size %d: got %d bytes", size, len(got))
+		}
+		padded, _ := ctx.Encrypt(data)
+		if !bytes.Equal(got, padded[:size]) {
+			t.Errorf("size %d: keystream differs from CTR Encrypt", size)
+		}
+		back, _ := ctx.XORKeyStream(got)
+		if !bytes.Equal(back, data) {
+			t.Errorf("size %d: round trip failed", size)
+		}
+	}
+
+	noNonce, _ := modes.NewSymmetricContext(d, ciphers.CTR, ciphers.PKCS7, nil)
+	if _, err := noNonce.XORKeyStream([]byte("data")); err == nil {
+		t.Error("XORKeyStream without nonce accepted")
+	}
+	cbc, _ := modes.NewSymmetricContext(d, ciphers.CBC, ciphers.PKCS7, make([]byte, 16))
+	if _, err := cbc.XORKeyStream([]byte("data")); err == nil {
+		t.Error("XORKeyStream in CBC accepted")
+	}
+}
@@ -0,0 +1,164 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/ciphers/rijndael"
+	"crypto-lab/internal/modes"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// тестовые примеры из спецификации GCM (McGrew, Viega), AES-128
+func TestGCMKnownVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		nonce      string
+		plaintext  string
+		aad        string
+		ciphertext string
+		tag        string
+	}{
+		{
+			name:       "Test Case 2",
+			key:        "00000000000000000000000000000000",
+			nonce:      "000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "0388dace60b6a392f328c2b971b2fe78",
+			tag:        "ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			name:       "Test Case 4",
+			key:        "feffe9928665731c6d6a8f9467308308",
+			nonce:      "cafebabefacedbaddecaf888",
+			plaintext:  "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			aad:        "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+			tag:        "5bc94fbc3221a5db94fae95ae7121a47",
+		},
+		{
+			name:       "Test Case 6 (60-byte IV)",
+			key:        "feffe9928665731c6d6a8f9467308308",
+			nonce:      "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+			plaintext:  "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			aad:        "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca701e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5",
+			tag:        "619cc5aefffe0bfa462af43c1699d050",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aes, _ := rijndael.NewAES(16)
+			if err := aes.SetSymmetricKey(unhex(tt.key)); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, err := modes.NewSymmetricContext(aes, ciphers.GCM, ciphers.PKCS7, nil,
+				unhex(tt.nonce), modes.GCMParams{AAD: unhex(tt.aad)})
+			if err != nil {
+				t.Fatalf("Failed to create context: %v", err)
+			}
+
+			plaintext := unhex(tt.plaintext)
+			expected := append(unhex(tt.ciphertext), unhex(tt.tag)...)
+
+			sealed, err := ctx.Encrypt(plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if !bytes.Equal(sealed, expected) {
+				t.Fatalf("expected %x\ngot      %x", expected, sealed)
+			}
+
+			opened, err := ctx.Decrypt(sealed)
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("round-trip failed")
+			}
+		})
+	}
+}
+
+func TestGCMAuthenticationFailure(t *testing.T) {
+	dealCipher, _ := deal.NewDEALCipher(24)
+	key := make([]byte, 24)
+	rand.Read(key)
+	dealCipher.SetSymmetricKey(key)
+
+	nonce, _ := modes.GenerateRandomBytes(12)
+	message := []byte("integrity as well as confidentiality")
+
+	for _, tagSize := range []int{16, 12, 8} {
+		ctx, err := modes.NewSymmetricContext(dealCipher, ciphers.GCM, ciphers.PKCS7, nil,
+			nonce, modes.GCMParams{AAD: []byte("header"), TagSize: tagSize})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sealed, err := ctx.Encrypt(message)
+		if err != nil {
+			t.Fatalf("Encrypt failed: %v", err)
+		}
+		if len(sealed) != len(message)+tagSize {
+			t.Errorf("tag %d: expected length %d, got %d", tagSize, len(message)+tagSize, len(sealed))
+		}
+
+		opened, err := ctx.Decrypt(sealed)
+		if err != nil || !bytes.Equal(opened, message) {
+			t.Fatalf("tag %d: round-trip failed: %v", tagSize, err)
+		}
+
+		// портим по одному биту в шифртексте и в теге
+		for _, pos := range []int{0, len(message) - 1, len(sealed) - 1} {
+			tampered := append([]byte(nil), sealed...)
+			tampered[pos] ^= 0x01
+			if _, err := ctx.Decrypt(tampered); !errors.Is(err, modes.ErrAuthenticationFailed) {
+				t.Errorf("tag %d, pos %d: expected ErrAuthenticationFailed, got %v", tagSize, pos, err)
+			}
+		}
+
+		// другие AAD
+		otherCtx, _ := modes.NewSymmetricContext(dealCipher, ciphers.GCM, ciphers.PKCS7, nil,
+			nonce, modes.GCMParams{AAD: []byte("HEADER"), TagSize: tagSize})
+		if _, err := otherCtx.Decrypt(sealed); !errors.Is(err, modes.ErrAuthenticationFailed) {
+			t.Errorf("tag %d: expected ErrAuthenticationFailed for wrong AAD, got %v", tagSize, err)
+		}
+	}
+}
+
+func TestGCMRejectsInvalidSetup(t *testing.T) {
+	// 8-байтный блок DES не подходит для GCM
+	if _, err := modes.NewSymmetricContext(des.NewDES(), ciphers.GCM, ciphers.PKCS7, nil, make([]byte, 12)); err == nil {
+		t.Errorf("expected error for 8-byte block cipher")
+	}
+
+	aes, _ := rijndael.NewAES(16)
+	aes.SetSymmetricKey(make([]byte, 16))
+
+	ctx, _ := modes.NewSymmetricContext(aes, ciphers.GCM, ciphers.PKCS7, nil, make([]byte, 12), modes.GCMParams{TagSize: 5})
+	if _, err := ctx.Encrypt([]byte("data")); err == nil {
+		t.Errorf("expected error for tag size 5")
+	}
+
+	ctx, _ = modes.NewSymmetricContext(aes, ciphers.GCM, ciphers.PKCS7, nil)
+	if _, err := ctx.Encrypt([]byte("data")); err == nil {
+		t.Errorf("expected error without nonce")
+	}
+}
@@ -0,0 +1,110 @@
+package modes
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/modes"
+)
+
+const allocBlocks = 512
+
+// intoOnly скрывает EncryptBatch у DES, но оставляет EncryptInto,
+// чтобы мерить именно поблочный путь
+type intoOnly struct {
+	ciphers.SymmetricCipher
+	ciphers.BlockCipherInto
+}
+
+func intoCiphers(t testing.TB) map[string]intoOnly {
+	desCipher := des.NewDES()
+	if err := desCipher.SetSymmetricKey([]byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}); err != nil {
+		t.Fatal(err)
+	}
+	dealCipher, err := deal.NewDEALCipher(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dealCipher.SetSymmetricKey(bytes.Repeat([]byte{0x5A}, 16)); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]intoOnly{
+		"DES":  {desCipher, desCipher},
+		"DEAL": {dealCipher, dealCipher},
+	}
+}
+
+func intoContext(t testing.TB, cipher ciphers.SymmetricCipher, mode ciphers.CipherMode) *modes.SymmetricContext {
+	params := []any{make([]byte, 12), int64(7)}
+	if mode == ciphers.CTR || mode == ciphers.RandomDelta {
+		params[0] = make([]byte, cipher.GetBlockSize()/2)
+	}
+	if mode == ciphers.XTS {
+		tweak, _ := deal.NewDEALCipher(16)
+		tweak.SetSymmetricKey(bytes.Repeat([]byte{0xA5}, 16))
+		params = append(params, modes.XTSParams{TweakCipher: tweak})
+	}
+	ctx, err := modes.NewSymmetricContext(cipher, mode, ciphers.PKCS7, make([]byte, cipher.GetBlockSize()), params...)
+	if err != nil {
+		t.Fatalf("%v: %v", mode, err)
+	}
+	return ctx
+}
+
+func intoModes(blockSize int) []ciphers.CipherMode {
+	list := []ciphers.CipherMode{
+		ciphers.ECB, ciphers.CBC, ciphers.PCBC, ciphers.CFB, ciphers.OFB, ciphers.CTR,
+		ciphers.RandomDelta, ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3,
+	}
+	if blockSize == 16 {
+		list = append(list, ciphers.GCM, ciphers.XTS)
+	}
+	return list
+}
+
+// на блок - почти ноль аллокаций: остаются только буферы результата,
+// дополнения и горутины на куски, их число не растет с каждым блоком
+// (поблочный путь через Encrypt давал 36 аллокаций на блок DES и 224 на блок DEAL)
+func TestModesAllocsPerBlock(t *testing.T) {
+	for name, cipher := range intoCiphers(t) {
+		bs := cipher.GetBlockSize()
+		message := make([]byte, allocBlocks*bs)
+		for _, mode := range intoModes(bs) {
+			ctx := intoContext(t, cipher, mode)
+			ciphertext, err := ctx.Encrypt(message)
+			if err != nil {
+				t.Fatalf("%s/%v: %v", name, mode, err)
+			}
+
+			enc := testing.AllocsPerRun(3, func() { ctx.Encrypt(message) })
+			dec := testing.AllocsPerRun(3, func() { ctx.Decrypt(ciphertext) })
+			t.Logf("%s/%v: encrypt %.0f, decrypt %.0f allocs per %d blocks", name, mode, enc, dec, allocBlocks)
+			if perBlock := max(enc, dec) / allocBlocks; perBlock > 0.1 {
+				t.Errorf("%s/%v: %.2f allocs per block", name, mode, perBlock)
+			}
+		}
+	}
+}
+
+func BenchmarkModesInto(b *testing.B) {
+	for name, cipher := range intoCiphers(b) {
+		bs := cipher.GetBlockSize()
+		message := make([]byte, allocBlocks*bs)
+		for _, mode := range intoModes(bs) {
+			ctx := intoContext(b, cipher, mode)
+			b.Run(fmt.Sprintf("%s/%v", name, mode), func(b *testing.B) {
+				b.SetBytes(int64(len(message)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := ctx.Encrypt(message); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
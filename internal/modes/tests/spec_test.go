@@ -0,0 +1,60 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/modes"
+)
+
+// контекст по строке совпадает с собранным вручную
+func TestContextFromSpec(t *testing.T) {
+	key := bytes.Repeat([]byte{0x17}, 32)
+	iv := bytes.Repeat([]byte{0x01}, 16)
+	message := []byte("config-driven DEAL-256 in CBC mode")
+
+	fromSpec, err := modes.NewSymmetricContextFromSpec("DEAL-256/CBC/PKCS7", key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := deal.NewDEALCipher(32)
+	d.SetSymmetricKey(key)
+	manual, _ := modes.NewSymmetricContext(d, ciphers.CBC, ciphers.PKCS7, iv)
+
+	got, err := fromSpec.Encrypt(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := manual.Encrypt(message)
+	if !bytes.Equal(got, expected) {
+		t.Error("spec context differs from manual context")
+	}
+	decrypted, err := fromSpec.Decrypt(got)
+	if err != nil || !bytes.Equal(decrypted, message) {
+		t.Errorf("round trip failed: %v", err)
+	}
+
+	// params передаются как есть: nonce для GCM
+	gcm, err := modes.NewSymmetricContextFromSpec("AES-128/GCM", key[:16], nil, make([]byte, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gcm.Encrypt(message); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestContextFromSpecErrors(t *testing.T) {
+	if _, err := modes.NewSymmetricContextFromSpec("Blowfish/CBC/PKCS7", make([]byte, 16), nil); !errors.Is(err, ciphers.ErrUnknownCipher) {
+		t.Errorf("expected ErrUnknownCipher, got %v", err)
+	}
+	if _, err := modes.NewSymmetricContextFromSpec("DEAL-256/CBC/PKCS7", make([]byte, 16), nil); err == nil {
+		t.Error("16-byte key for DEAL-256 accepted")
+	}
+	if _, err := modes.NewSymmetricContextFromSpec("DES/GCM", make([]byte, 8), nil); err == nil {
+		t.Error("GCM with 8-byte block accepted")
+	}
+}
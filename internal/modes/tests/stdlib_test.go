@@ -0,0 +1,367 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	stddes "crypto/des"
+	"encoding/binary"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/ciphers/rijndael"
+	"crypto-lab/internal/ciphers/stdcipher"
+	"crypto-lab/internal/modes"
+)
+
+// stdPair - один и тот же шифр как SymmetricCipher (для modes) и как cipher.Block
+// (для эталона на стандартной библиотеке)
+type stdPair struct {
+	name    string
+	keySize int
+	newPair func(t *testing.T, key []byte) (ciphers.SymmetricCipher, cipher.Block)
+}
+
+var stdPairs = []stdPair{
+	{"DES/crypto-des", 8, func(t *testing.T, key []byte) (ciphers.SymmetricCipher, cipher.Block) {
+		ours := des.NewDES()
+		if err := ours.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+		std, err := stddes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ours, std
+	}},
+	{"3DES/crypto-des", 24, func(t *testing.T, key []byte) (ciphers.SymmetricCipher, cipher.Block) {
+		ours := des.NewTripleDES()
+		if err := ours.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+		std, err := stddes.NewTripleDESCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ours, std
+	}},
+	{"Rijndael/crypto-aes", 16, func(t *testing.T, key []byte) (ciphers.SymmetricCipher, cipher.Block) {
+		ours, _ := rijndael.NewAES(16)
+		if err := ours.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+		std, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ours, std
+	}},
+	// cipher.Block -> SymmetricCipher: режимы modes поверх crypto/aes
+	{"stdcipher.Cipher(AES)", 32, func(t *testing.T, key []byte) (ciphers.SymmetricCipher, cipher.Block) {
+		wrapped := stdcipher.NewCipher(aes.BlockSize, aes.NewCipher)
+		if err := wrapped.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+		std, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wrapped, std
+	}},
+	// SymmetricCipher -> cipher.Block: режимы стандартной библиотеки поверх DEAL
+	{"DEAL/stdcipher.Block", 24, func(t *testing.T, key []byte) (ciphers.SymmetricCipher, cipher.Block) {
+		ours, err := deal.NewDEALCipher(len(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ours.SetSymmetricKey(key); err != nil {
+			t.Fatal(err)
+		}
+		return ours, stdcipher.NewBlock(ours)
+	}},
+}
+
+// stdReference - эталон режима на cipher.Block; для режимов, которых нет
+// в стандартной библиотеке (ECB, PCBC, CBC-CS, XTS), - по определению
+type stdReference func(block, tweak cipher.Block, iv, nonce []byte, seed int64, msg []byte) []byte
+
+var stdReferences = map[ciphers.CipherMode]stdReference{
+	ciphers.ECB: func(block, _ cipher.Block, _, _ []byte, _ int64, msg []byte) []byte {
+		bs := block.BlockSize()
+		out := make([]byte, len(msg))
+		for i := 0; i < len(msg); i += bs {
+			block.Encrypt(out[i:], msg[i:i+bs])
+		}
+		return out
+	},
+	ciphers.CBC: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		out := make([]byte, len(msg))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, msg)
+		return out
+	},
+	ciphers.PCBC: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		bs := block.BlockSize()
+		out := make([]byte, len(msg))
+		feedback := append([]byte(nil), iv...)
+		for i := 0; i < len(msg); i += bs {
+			for j := 0; j < bs; j++ {
+				out[i+j] = msg[i+j] ^ feedback[j]
+			}
+			block.Encrypt(out[i:], out[i:i+bs])
+			for j := 0; j < bs; j++ {
+				feedback[j] = msg[i+j] ^ out[i+j]
+			}
+		}
+		return out
+	},
+	ciphers.CFB: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		out := make([]byte, len(msg))
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(out, msg)
+		return out
+	},
+	ciphers.OFB: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		out := make([]byte, len(msg))
+		cipher.NewOFB(block, iv).XORKeyStream(out, msg)
+		return out
+	},
+	ciphers.CTR: func(block, _ cipher.Block, _, nonce []byte, _ int64, msg []byte) []byte {
+		counter := make([]byte, block.BlockSize())
+		copy(counter, nonce)
+		out := make([]byte, len(msg))
+		cipher.NewCTR(block, counter).XORKeyStream(out, msg)
+		return out
+	},
+	// RandomDelta - CTR, у которого счетчик начинается с seed
+	ciphers.RandomDelta: func(block, _ cipher.Block, _, nonce []byte, seed int64, msg []byte) []byte {
+		counter := make([]byte, block.BlockSize())
+		copy(counter, nonce)
+		var tmp [8]byte
+		binary.BigEndian.PutUint64(tmp[:], uint64(seed))
+		counterBytes := len(counter) - len(nonce)
+		copy(counter[len(nonce):], tmp[8-counterBytes:])
+		out := make([]byte, len(msg))
+		cipher.NewCTR(block, counter).XORKeyStream(out, msg)
+		return out
+	},
+	ciphers.GCM: func(block, _ cipher.Block, _, nonce []byte, _ int64, msg []byte) []byte {
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+		return aead.Seal(nil, nonce, msg, stdAAD)
+	},
+	ciphers.CBCCS1: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		return stdCBCCS(block, iv, msg, ciphers.CBCCS1)
+	},
+	ciphers.CBCCS2: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		return stdCBCCS(block, iv, msg, ciphers.CBCCS2)
+	},
+	ciphers.CBCCS3: func(block, _ cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+		return stdCBCCS(block, iv, msg, ciphers.CBCCS3)
+	},
+	ciphers.XTS: stdXTS,
+}
+
+var stdAAD = []byte("header")
+
+// CBC стандартной библиотеки над P || 0*, затем усечение и перестановка
+// двух последних блоков
+func stdCBCCS(block cipher.Block, iv, msg []byte, variant ciphers.CipherMode) []byte {
+	bs := block.BlockSize()
+	n := (len(msg) + bs - 1) / bs
+	d := len(msg) - (n-1)*bs
+	padded := make([]byte, n*bs)
+	copy(padded, msg)
+	full := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(full, padded)
+	if n == 1 {
+		return full
+	}
+
+	head := full[:(n-2)*bs]
+	prev := full[(n-2)*bs : (n-1)*bs]
+	last := full[(n-1)*bs:]
+	out := append([]byte(nil), head...)
+	if variant == ciphers.CBCCS3 || (variant == ciphers.CBCCS2 && d != bs) {
+		return append(append(out, last...), prev[:d]...)
+	}
+	return append(append(out, prev[:d]...), last...)
+}
+
+// IEEE 1619: T = E(K2, tweak), C = E(K1, P xor T) xor T, T *= α;
+// неполный хвост - кража шифртекста у последнего полного блока
+func stdXTS(block, tweak cipher.Block, iv, _ []byte, _ int64, msg []byte) []byte {
+	t := make([]byte, 16)
+	tweak.Encrypt(t, iv)
+	xtsBlock := func(dst, src, t []byte) {
+		for j := range 16 {
+			dst[j] = src[j] ^ t[j]
+		}
+		block.Encrypt(dst, dst)
+		for j := range 16 {
+			dst[j] ^= t[j]
+		}
+	}
+	mulAlpha := func(t []byte) []byte {
+		out := make([]byte, 16)
+		var carry byte
+		for j := range 16 {
+			out[j] = t[j]<<1 | carry
+			carry = t[j] >> 7
+		}
+		if carry != 0 {
+			out[0] ^= 0x87
+		}
+		return out
+	}
+
+	out := make([]byte, len(msg))
+	full := len(msg) / 16
+	tail := len(msg) % 16
+	if tail != 0 {
+		full--
+	}
+	for i := 0; i < full; i++ {
+		xtsBlock(out[i*16:], msg[i*16:i*16+16], t)
+		t = mulAlpha(t)
+	}
+	if tail == 0 {
+		return out
+	}
+
+	offset := full * 16
+	cc := make([]byte, 16)
+	xtsBlock(cc, msg[offset:offset+16], t)
+	pp := append(append([]byte(nil), msg[offset+16:]...), cc[tail:]...)
+	xtsBlock(out[offset:], pp, mulAlpha(t))
+	copy(out[offset+16:], cc[:tail])
+	return out
+}
+
+func pkcs7(msg []byte, bs int) []byte {
+	pad := bs - len(msg)%bs
+	return append(append([]byte(nil), msg...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+}
+
+func stdKey(size int, salt byte) []byte {
+	key := make([]byte, size)
+	for i := range key {
+		key[i] = byte(i*29) ^ salt
+	}
+	return key
+}
+
+// каждый режим modes совпадает с эталоном на стандартной библиотеке, а
+// расшифровка modes обращает эталонный шифртекст; 300 блоков - больше одного
+// куска параллельных режимов и прохода битовых срезов DES
+func TestModesMatchStdlib(t *testing.T) {
+	const seed = int64(0x0102030405)
+
+	for _, pair := range stdPairs {
+		sym, block := pair.newPair(t, stdKey(pair.keySize, 0))
+		bs := block.BlockSize()
+		iv := stdKey(bs, 0x5A)
+
+		for mode := ciphers.ECB; mode <= ciphers.XTS; mode++ {
+			reference := stdReferences[mode]
+			if (mode == ciphers.GCM || mode == ciphers.XTS) && bs != 16 {
+				continue
+			}
+
+			var params []any
+			var tweakBlock cipher.Block
+			nonce := iv[:bs/2]
+			switch mode {
+			case ciphers.CTR:
+				params = []any{nonce}
+			case ciphers.RandomDelta:
+				nonce = iv[:bs-5] // 5 байт счетчика, seed занимает 5 байт
+				params = []any{nonce, seed}
+			case ciphers.GCM:
+				nonce = iv[:12]
+				params = []any{nonce, modes.GCMParams{AAD: stdAAD}}
+			case ciphers.XTS:
+				var tweak ciphers.SymmetricCipher
+				tweak, tweakBlock = pair.newPair(t, stdKey(pair.keySize, 0xC3))
+				params = []any{nil, modes.XTSParams{TweakCipher: tweak}}
+			}
+
+			// GCM, CBC-CS и XTS работают без паддинга с любой длиной,
+			// остальным эталон получает сообщение с PKCS7
+			unpadded := false
+			lengths := []int{300 * bs, 300*bs + 3}
+			switch mode {
+			case ciphers.GCM, ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3, ciphers.XTS:
+				unpadded = true
+				lengths = append(lengths, bs, bs+1)
+			}
+
+			for _, length := range lengths {
+				msg := stdKey(length, 0x11)
+
+				ctx, err := modes.NewSymmetricContext(sym, mode, ciphers.PKCS7, iv, params...)
+				if err != nil {
+					t.Fatalf("%s/%v: %v", pair.name, mode, err)
+				}
+				input := msg
+				if !unpadded {
+					input = pkcs7(msg, bs)
+				}
+				expected := reference(block, tweakBlock, iv, nonce, seed, input)
+
+				got, err := ctx.Encrypt(msg)
+				if err != nil {
+					t.Fatalf("%s/%v/%d: %v", pair.name, mode, length, err)
+				}
+				if !bytes.Equal(got, expected) {
+					t.Errorf("%s/%v/%d: ciphertext differs from stdlib", pair.name, mode, length)
+					continue
+				}
+				decrypted, err := ctx.Decrypt(expected)
+				if err != nil {
+					t.Fatalf("%s/%v/%d: %v", pair.name, mode, length, err)
+				}
+				if !bytes.Equal(decrypted, msg) {
+					t.Errorf("%s/%v/%d: decrypting stdlib ciphertext failed", pair.name, mode, length)
+				}
+			}
+		}
+	}
+}
+
+// стандартные режимы поверх DEAL расшифровывают то, что зашифровали modes
+func TestStdlibModesOverDEAL(t *testing.T) {
+	d, _ := deal.NewDEALCipher(32)
+	d.SetSymmetricKey(stdKey(32, 0x77))
+	block := stdcipher.NewBlock(d)
+	iv := stdKey(16, 0x01)
+	msg := stdKey(40*16, 0x22)
+
+	ctx, _ := modes.NewSymmetricContext(d, ciphers.CBC, ciphers.PKCS7, iv)
+	ciphertext, err := ctx.Encrypt(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, ciphertext)
+	if !bytes.Equal(plain, pkcs7(msg, 16)) {
+		t.Error("cipher.NewCBCDecrypter over DEAL failed")
+	}
+
+	nonce := iv[:12]
+	gcmCtx, _ := modes.NewSymmetricContext(d, ciphers.GCM, ciphers.PKCS7, nil, nonce, modes.GCMParams{AAD: stdAAD})
+	sealed, err := gcmCtx.Encrypt(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, err := aead.Open(nil, nonce, sealed, stdAAD)
+	if err != nil || !bytes.Equal(opened, msg) {
+		t.Errorf("cipher.NewGCM over DEAL failed: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package modes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/modes"
+)
+
+var allModes = []ciphers.CipherMode{
+	ciphers.ECB, ciphers.CBC, ciphers.PCBC,
+	ciphers.CFB, ciphers.OFB, ciphers.CTR, ciphers.RandomDelta,
+}
+
+func newDESContext(t *testing.T, mode ciphers.CipherMode, padding ciphers.PaddingMode) *modes.SymmetricContext {
+	t.Helper()
+
+	cipher := des.NewDES()
+	if err := cipher.SetSymmetricKey([]byte{0x13, 0x34, 0x57, 0x79, 0x9B, 0xBC, 0xDF, 0xF1}); err != nil {
+		t.Fatal(err)
+	}
+
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	nonce := []byte{0xA, 0xB, 0xC, 0xD}
+	ctx, err := modes.NewSymmetricContext(cipher, mode, padding, iv, nonce, int64(12345))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+// пишем кусками по step байт
+func writeInPieces(w io.Writer, data []byte, step int) error {
+	for len(data) > 0 {
+		n := step
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// поток должен давать тот же шифртекст, что и Encrypt целиком
+func TestStreamMatchesEncrypt(t *testing.T) {
+	sizes := []int{0, 1, 7, 8, 9, 40, 300}
+	chunkSizes := []int{8, 24, 64}
+
+	for _, mode := range allModes {
+		for _, size := range sizes {
+			for _, chunkSize := range chunkSizes {
+				name := fmt.Sprintf("%s/size%d/chunk%d", mode, size, chunkSize)
+				t.Run(name, func(t *testing.T) {
+					ctx := newDESContext(t, mode, ciphers.PKCS7)
+
+					data := make([]byte, size)
+					for i := range data {
+						data[i] = byte(i*7 + 1)
+					}
+
+					expected, err := ctx.Encrypt(data)
+					if err != nil {
+						t.Fatalf("Encrypt failed: %v", err)
+					}
+
+					var encrypted bytes.Buffer
+					w, err := ctx.NewEncryptWriterSize(&encrypted, chunkSize)
+					if err != nil {
+						t.Fatalf("NewEncryptWriterSize failed: %v", err)
+					}
+					if err := writeInPieces(w, data, 5); err != nil {
+						t.Fatalf("Write failed: %v", err)
+					}
+					if err := w.Close(); err != nil {
+						t.Fatalf("Close failed: %v", err)
+					}
+
+					if !bytes.Equal(encrypted.Bytes(), expected) {
+						t.Fatalf("stream ciphertext differs from Encrypt\nexpected: %x\ngot:      %x", expected, encrypted.Bytes())
+					}
+
+					r, err := ctx.NewDecryptReaderSize(bytes.NewReader(encrypted.Bytes()), chunkSize+8)
+					if err != nil {
+						t.Fatalf("NewDecryptReaderSize failed: %v", err)
+					}
+					decrypted, err := io.ReadAll(r)
+					if err != nil {
+						t.Fatalf("ReadAll failed: %v", err)
+					}
+					if !bytes.Equal(decrypted, data) {
+						t.Errorf("stream round-trip failed\nexpected: %x\ngot:      %x", data, decrypted)
+					}
+				})
+			}
+		}
+	}
+}
+
+// режимы без паддинга: хвост придерживается до Close / EOF, результат тот же, что у Encrypt
+func TestStreamUnpaddedMatchesEncrypt(t *testing.T) {
+	sizes := []int{0, 1, 15, 16, 17, 47, 48, 49, 300}
+	chunkSizes := []int{16, 48}
+
+	for _, mode := range []ciphers.CipherMode{ciphers.GCM, ciphers.CBCCS1, ciphers.CBCCS2, ciphers.CBCCS3} {
+		for _, size := range sizes {
+			for _, chunkSize := range chunkSizes {
+				if mode != ciphers.GCM && size < 16 {
+					continue // CBC-CS требует хотя бы блок, см. TestStreamCBCCSTooShort
+				}
+				t.Run(fmt.Sprintf("%s/size%d/chunk%d", mode, size, chunkSize), func(t *testing.T) {
+					d, _ := deal.NewDEALCipher(16)
+					d.SetSymmetricKey(bytes.Repeat([]byte{0x21}, 16))
+					ctx, err := modes.NewSymmetricContext(d, mode, ciphers.PKCS7,
+						bytes.Repeat([]byte{0x07}, 16), make([]byte, 12))
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					data := make([]byte, size)
+					for i := range data {
+						data[i] = byte(i*7 + 1)
+					}
+					expected, err := ctx.Encrypt(data)
+					if err != nil {
+						t.Fatalf("Encrypt failed: %v", err)
+					}
+
+					var encrypted bytes.Buffer
+					w, _ := ctx.NewEncryptWriterSize(&encrypted, chunkSize)
+					if err := writeInPieces(w, data, 5); err != nil {
+						t.Fatalf("Write failed: %v", err)
+					}
+					if err := w.Close(); err != nil {
+						t.Fatalf("Close failed: %v", err)
+					}
+					if !bytes.Equal(encrypted.Bytes(), expected) {
+						t.Fatalf("stream ciphertext differs from Encrypt\nexpected: %x\ngot:      %x", expected, encrypted.Bytes())
+					}
+
+					r, _ := ctx.NewDecryptReaderSize(bytes.NewReader(expected), chunkSize)
+					decrypted, err := io.ReadAll(r)
+					if err != nil {
+						t.Fatalf("ReadAll failed: %v", err)
+					}
+					if !bytes.Equal(decrypted, data) {
+						t.Errorf("stream round-trip failed")
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestStreamCBCCSTooShort(t *testing.T) {
+	ctx := newDESContext(t, ciphers.CBCCS3, ciphers.PKCS7)
+	w, _ := ctx.NewEncryptWriter(io.Discard)
+	w.Write([]byte("short"))
+	if err := w.Close(); !errors.Is(err, modes.ErrMessageTooShort) {
+		t.Errorf("expected ErrMessageTooShort, got %v", err)
+	}
+}
+
+func TestStreamDecryptErrors(t *testing.T) {
+	ctx := newDESContext(t, ciphers.CBC, ciphers.PKCS7)
+
+	// не кратно блоку
+	r, _ := ctx.NewDecryptReader(bytes.NewReader(make([]byte, 13)))
+	if _, err := io.ReadAll(r); err == nil {
+		t.Errorf("Expected error for unaligned ciphertext")
+	}
+
+	// пустой шифртекст
+	r, _ = ctx.NewDecryptReader(bytes.NewReader(nil))
+	if _, err := io.ReadAll(r); err == nil {
+		t.Errorf("Expected error for empty ciphertext")
+	}
+
+	w, _ := ctx.NewEncryptWriter(io.Discard)
+	w.Close()
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Errorf("Expected error on write after Close")
+	}
+}
+
+func TestEncryptDecryptFile(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "plain.bin")
+	encryptedPath := filepath.Join(dir, "plain.bin.enc")
+	decryptedPath := filepath.Join(dir, "plain.bin.dec")
+
+	data := bytes.Repeat([]byte("streamed file contents "), 50)
+	if err := os.WriteFile(inputPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range allModes {
+		t.Run(mode.String(), func(t *testing.T) {
+			ctx := newDESContext(t, mode, ciphers.ANSIX923)
+
+			if err := ctx.EncryptFile(inputPath, encryptedPath); err != nil {
+				t.Fatalf("EncryptFile failed: %v", err)
+			}
+			if err := ctx.DecryptFile(encryptedPath, decryptedPath); err != nil {
+				t.Fatalf("DecryptFile failed: %v", err)
+			}
+
+			decrypted, err := os.ReadFile(decryptedPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Errorf("file round-trip failed")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/ciphers/des"
+	"crypto-lab/internal/ciphers/rijndael"
+	"crypto-lab/internal/modes"
+)
+
+func newXTSContext(t *testing.T, dataCipher, tweakCipher ciphers.SymmetricCipher) *modes.SymmetricContext {
+	t.Helper()
+	ctx, err := modes.NewSymmetricContext(dataCipher, ciphers.XTS, ciphers.PKCS7, nil,
+		nil, modes.XTSParams{TweakCipher: tweakCipher})
+	if err != nil {
+		t.Fatalf("Failed to create XTS context: %v", err)
+	}
+	return ctx
+}
+
+// IEEE 1619-2007, Annex B: XTS-AES-128
+func TestXTSKnownVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		key1, key2 string
+		sector     uint64
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "Vector 1",
+			key1:       "00000000000000000000000000000000",
+			key2:       "00000000000000000000000000000000",
+			sector:     0,
+			plaintext:  "0000000000000000000000000000000000000000000000000000000000000000",
+			ciphertext: "917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e",
+		},
+		{
+			name:       "Vector 2",
+			key1:       "11111111111111111111111111111111",
+			key2:       "22222222222222222222222222222222",
+			sector:     0x3333333333,
+			plaintext:  "4444444444444444444444444444444444444444444444444444444444444444",
+			ciphertext: "c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0",
+		},
+		{
+			// кража шифртекста: 17 байт
+			name:       "Vector 15",
+			key1:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			key2:       "bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+			sector:     0x123456789a, // твик 9a78563412 (little-endian)
+			plaintext:  "000102030405060708090a0b0c0d0e0f10",
+			ciphertext: "6c1625db4671522d3d7599601de7ca09ed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataCipher, _ := rijndael.NewAES(16)
+			dataCipher.SetSymmetricKey(unhex(tt.key1))
+			tweakCipher, _ := rijndael.NewAES(16)
+			tweakCipher.SetSymmetricKey(unhex(tt.key2))
+
+			ctx := newXTSContext(t, dataCipher, tweakCipher)
+
+			ciphertext, err := ctx.EncryptSector(tt.sector, unhex(tt.plaintext))
+			if err != nil {
+				t.Fatalf("EncryptSector failed: %v", err)
+			}
+			if !bytes.Equal(ciphertext, unhex(tt.ciphertext)) {
+				t.Errorf("expected %s\ngot      %x", tt.ciphertext, ciphertext)
+			}
+
+			plaintext, err := ctx.DecryptSector(tt.sector, ciphertext)
+			if err != nil {
+				t.Fatalf("DecryptSector failed: %v", err)
+			}
+			if !bytes.Equal(plaintext, unhex(tt.plaintext)) {
+				t.Errorf("round-trip failed: got %x", plaintext)
+			}
+		})
+	}
+}
+
+func TestXTSWithDEAL(t *testing.T) {
+	key1 := make([]byte, 32)
+	key2 := make([]byte, 32)
+	rand.Read(key1)
+	rand.Read(key2)
+	dataCipher, _ := deal.NewDEALCipher(32)
+	dataCipher.SetSymmetricKey(key1)
+	tweakCipher, _ := deal.NewDEALCipher(32)
+	tweakCipher.SetSymmetricKey(key2)
+
+	ctx := newXTSContext(t, dataCipher, tweakCipher)
+
+	sector := make([]byte, 512+7)
+	rand.Read(sector)
+
+	c1, err := ctx.EncryptSector(1, sector)
+	if err != nil {
+		t.Fatalf("EncryptSector failed: %v", err)
+	}
+	if len(c1) != len(sector) {
+		t.Errorf("expected length %d, got %d", len(sector), len(c1))
+	}
+
+	// тот же открытый текст в другом секторе шифруется иначе
+	c2, _ := ctx.EncryptSector(2, sector)
+	if bytes.Equal(c1, c2) {
+		t.Errorf("different sectors produced equal ciphertext")
+	}
+
+	p1, err := ctx.DecryptSector(1, c1)
+	if err != nil || !bytes.Equal(p1, sector) {
+		t.Errorf("sector 1 round-trip failed: %v", err)
+	}
+	if p2, _ := ctx.DecryptSector(1, c2); bytes.Equal(p2, sector) {
+		t.Errorf("decrypting with wrong sector number must not recover plaintext")
+	}
+}
+
+func TestXTSErrors(t *testing.T) {
+	aes, _ := rijndael.NewAES(16)
+	aes.SetSymmetricKey(make([]byte, 16))
+
+	if _, err := modes.NewSymmetricContext(aes, ciphers.XTS, ciphers.PKCS7, nil); err == nil {
+		t.Errorf("expected error without tweak cipher")
+	}
+	if _, err := modes.NewSymmetricContext(des.NewDES(), ciphers.XTS, ciphers.PKCS7, nil,
+		nil, modes.XTSParams{TweakCipher: aes}); err == nil {
+		t.Errorf("expected error for 8-byte block data cipher")
+	}
+
+	ctx := newXTSContext(t, aes, aes)
+	if _, err := ctx.EncryptSector(0, make([]byte, 15)); !errors.Is(err, modes.ErrMessageTooShort) {
+		t.Errorf("expected ErrMessageTooShort, got %v", err)
+	}
+
+	// файлы и потоки: явный отказ, а не файл, который нельзя расшифровать
+	if _, err := ctx.NewEncryptWriter(io.Discard); !errors.Is(err, modes.ErrXTSNotStreamable) {
+		t.Errorf("NewEncryptWriter: expected ErrXTSNotStreamable, got %v", err)
+	}
+	if err := ctx.EncryptStream(io.Discard, bytes.NewReader(make([]byte, 32))); !errors.Is(err, modes.ErrXTSNotStreamable) {
+		t.Errorf("EncryptStream: expected ErrXTSNotStreamable, got %v", err)
+	}
+	h := &modes.FileHeader{BlockSize: 16, Mode: ciphers.XTS, IV: make([]byte, 16)}
+	if _, err := modes.NewSymmetricContextFromHeader(aes, h); !errors.Is(err, modes.ErrXTSNotStreamable) {
+		t.Errorf("NewSymmetricContextFromHeader: expected ErrXTSNotStreamable, got %v", err)
+	}
+
+	cbcCtx, _ := modes.NewSymmetricContext(aes, ciphers.CBC, ciphers.PKCS7, make([]byte, 16))
+	if _, err := cbcCtx.EncryptSector(0, make([]byte, 16)); err == nil {
+		t.Errorf("expected error for EncryptSector on CBC context")
+	}
+}
@@ -0,0 +1,169 @@
+// XTS-режим (IEEE 1619) для посекторного шифрования дисков, блок 16 байт
+
+package modes
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"crypto-lab/internal/ciphers"
+)
+
+const xtsBlockSize = 16
+
+// ErrXTSNotStreamable: XTS шифрует секторы независимо по номеру, у потока нет
+// номеров секторов, поэтому файлы и потоки XTS не поддерживаются
+var ErrXTSNotStreamable = errors.New("modes: XTS works per sector (EncryptSector/DecryptSector), files and streams are not supported")
+
+// XTSParams передается в NewSymmetricContext среди params.
+// TweakCipher - второй шифр с ключом K2 (ключ K1 - у шифра контекста), ключи должны различаться.
+type XTSParams struct {
+	TweakCipher ciphers.SymmetricCipher
+}
+
+func (ctx *SymmetricContext) newXTSStrategy() (*xtsStrategy, error) {
+	if ctx.blockSize != xtsBlockSize {
+		return nil, fmt.Errorf("XTS requires 16-byte block cipher, got %d", ctx.blockSize)
+	}
+	for _, p := range ctx.params {
+		xp, ok := p.(XTSParams)
+		if !ok {
+			continue
+		}
+		if xp.TweakCipher == nil {
+			break
+		}
+		if xp.TweakCipher.GetBlockSize() != xtsBlockSize {
+			return nil, fmt.Errorf("XTS tweak cipher must have 16-byte block, got %d", xp.TweakCipher.GetBlockSize())
+		}
+		return &xtsStrategy{ctx: ctx, tweak: xp.TweakCipher}, nil
+	}
+	return nil, errors.New("XTS mode requires XTSParams with tweak cipher")
+}
+
+// 128-битный номер сектора в little-endian - это и есть IV для XTS
+func SectorTweak(sector uint64) []byte {
+	tweak := make([]byte, xtsBlockSize)
+	binary.LittleEndian.PutUint64(tweak, sector)
+	return tweak
+}
+
+// EncryptSector шифрует один сектор; длина сохраняется (кража шифртекста для неполного блока)
+func (ctx *SymmetricContext) EncryptSector(sector uint64, data []byte) ([]byte, error) {
+	if ctx.cipherMode != ciphers.XTS {
+		return nil, fmt.Errorf("EncryptSector requires XTS mode, got %v", ctx.cipherMode)
+	}
+	return ctx.processor.Process(data, SectorTweak(sector), true)
+}
+
+func (ctx *SymmetricContext) DecryptSector(sector uint64, data []byte) ([]byte, error) {
+	if ctx.cipherMode != ciphers.XTS {
+		return nil, fmt.Errorf("DecryptSector requires XTS mode, got %v", ctx.cipherMode)
+	}
+	return ctx.processor.Process(data, SectorTweak(sector), false)
+}
+
+// Ci = E(K1, Pi XOR Ti) XOR Ti, T0 = E(K2, tweak), Ti+1 = Ti * α
+func (ctx *SymmetricContext) processXTS(data, tweakValue []byte, tweakCipher ciphers.SymmetricCipher, isEncrypt bool) ([]byte, error) {
+	if len(data) < xtsBlockSize {
+		return nil, fmt.Errorf("%w: got %d bytes, block size %d", ErrMessageTooShort, len(data), xtsBlockSize)
+	}
+
+	t := make([]byte, xtsBlockSize)
+	if err := encryptBlock(tweakCipher, t, tweakValue); err != nil {
+		return nil, fmt.Errorf("XTS tweak encryption failed: %w", err)
+	}
+
+	fullBlocks := len(data) / xtsBlockSize
+	tail := len(data) % xtsBlockSize
+	if tail != 0 {
+		fullBlocks-- // последний полный блок участвует в краже шифртекста
+	}
+
+	result := make([]byte, len(data))
+	for i := 0; i < fullBlocks; i++ {
+		offset := i * xtsBlockSize
+		out := result[offset : offset+xtsBlockSize]
+		if err := ctx.xtsBlockInto(out, data[offset:offset+xtsBlockSize], t, isEncrypt); err != nil {
+			return nil, err
+		}
+		mulAlphaInto(t, t)
+	}
+
+	if tail == 0 {
+		return result, nil
+	}
+
+	// кража шифртекста: блок m-1 и неполный блок m
+	offset := fullBlocks * xtsBlockSize
+	tNext := mulAlpha(t)
+
+	// при расшифровке предпоследний блок обрабатывается с твиком следующего
+	first, second := t, tNext
+	if !isEncrypt {
+		first, second = tNext, t
+	}
+
+	cc, err := ctx.xtsBlock(data[offset:offset+xtsBlockSize], first, isEncrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	pp := make([]byte, xtsBlockSize)
+	copy(pp, data[offset+xtsBlockSize:])
+	copy(pp[tail:], cc[tail:])
+
+	last, err := ctx.xtsBlock(pp, second, isEncrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	copy(result[offset:], last)
+	copy(result[offset+xtsBlockSize:], cc[:tail])
+	return result, nil
+}
+
+func (ctx *SymmetricContext) xtsBlock(block, t []byte, isEncrypt bool) ([]byte, error) {
+	out := make([]byte, xtsBlockSize)
+	if err := ctx.xtsBlockInto(out, block, t, isEncrypt); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// dst = E(K1, block XOR t) XOR t (или D), dst и block могут совпадать
+func (ctx *SymmetricContext) xtsBlockInto(dst, block, t []byte, isEncrypt bool) error {
+	xorInto(dst, block, t)
+	var err error
+	if isEncrypt {
+		err = ctx.encryptBlock(dst, dst)
+	} else {
+		err = ctx.decryptBlock(dst, dst)
+	}
+	if err != nil {
+		return err
+	}
+	xorInto(dst, dst, t)
+	return nil
+}
+
+// умножение на α в GF(2^128), little-endian, многочлен x^128 + x^7 + x^2 + x + 1
+func mulAlpha(t []byte) []byte {
+	out := make([]byte, xtsBlockSize)
+	mulAlphaInto(out, t)
+	return out
+}
+
+// dst и t могут совпадать
+func mulAlphaInto(dst, t []byte) {
+	var carry byte
+	for i := 0; i < xtsBlockSize; i++ {
+		next := t[i] >> 7
+		dst[i] = (t[i] << 1) | carry
+		carry = next
+	}
+	if carry != 0 {
+		dst[0] ^= 0x87
+	}
+}
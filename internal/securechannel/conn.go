@@ -0,0 +1,245 @@
+package securechannel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"crypto-lab/internal/ciphers"
+	"crypto-lab/internal/ciphers/deal"
+	"crypto-lab/internal/hash"
+	"crypto-lab/internal/mac"
+	"crypto-lab/internal/modes"
+)
+
+// Запись: seq uint64 || len uint32 || шифртекст || тег HMAC-SHA-256(seq || len || шифртекст).
+// Шифр - DEAL-256 в CTR без паддинга (modes XORKeyStream, шифртекст той же длины,
+// что данные); nonce записи = базовый nonce направления XOR seq, так что ключевой
+// поток не повторяется. seq ожидается строго по порядку.
+
+const (
+	MaxRecordPayload = 16 * 1024
+	recordHeaderSize = 12
+	tagSize          = 32
+)
+
+var (
+	ErrBadRecordMAC     = errors.New("securechannel: record authentication failed")
+	ErrReplayedRecord   = errors.New("securechannel: replayed record")
+	ErrOutOfOrderRecord = errors.New("securechannel: record out of order")
+	ErrRecordTooLong    = errors.New("securechannel: record too long")
+)
+
+// Conn - зашифрованное соединение поверх net.Conn. После ошибки записи
+// соединение непригодно: все дальнейшие Read возвращают ту же ошибку.
+type Conn struct {
+	conn net.Conn
+
+	readMu  sync.Mutex
+	in      *halfConn
+	pending []byte // расшифрованный остаток последней записи
+	readErr error
+
+	writeMu  sync.Mutex
+	out      *halfConn
+	writeErr error
+}
+
+var _ net.Conn = (*Conn)(nil)
+
+// состояние одного направления
+type halfConn struct {
+	cipher ciphers.SymmetricCipher
+	macKey []byte
+	nonce  []byte
+	seq    uint64
+}
+
+func newHalfConn(keys *directionKeys) (*halfConn, error) {
+	cipher, err := deal.NewDEALCipher(encKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if err := cipher.SetSymmetricKey(keys.encKey); err != nil {
+		return nil, err
+	}
+	return &halfConn{cipher: cipher, macKey: keys.macKey, nonce: keys.nonce}, nil
+}
+
+// Client выполняет рукопожатие со стороны инициатора
+func Client(conn net.Conn, config *Config) (*Conn, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	out, in, err := clientHandshake(conn, config)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(conn, out, in)
+}
+
+// Server выполняет рукопожатие со стороны отвечающего
+func Server(conn net.Conn, config *Config) (*Conn, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	out, in, err := serverHandshake(conn, config)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(conn, out, in)
+}
+
+func newConn(conn net.Conn, outKeys, inKeys *directionKeys) (*Conn, error) {
+	out, err := newHalfConn(outKeys)
+	if err != nil {
+		return nil, err
+	}
+	in, err := newHalfConn(inKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn: conn, out: out, in: in}, nil
+}
+
+// CTR-контекст записи seq; паддинг не используется (XORKeyStream)
+func (hc *halfConn) context(seq uint64) (*modes.SymmetricContext, error) {
+	nonce := make([]byte, len(hc.nonce))
+	binary.BigEndian.PutUint64(nonce, seq)
+	for i := range nonce {
+		nonce[i] ^= hc.nonce[i]
+	}
+	return modes.NewSymmetricContext(hc.cipher, ciphers.CTR, ciphers.PKCS7, nil, nonce)
+}
+
+func (hc *halfConn) mac(header, ciphertext []byte) (mac.MAC, error) {
+	m, err := mac.NewHMAC(hash.NewSHA256, hc.macKey)
+	if err != nil {
+		return nil, err
+	}
+	m.Write(header)
+	m.Write(ciphertext)
+	return m, nil
+}
+
+func (hc *halfConn) seal(payload []byte) ([]byte, error) {
+	ctx, err := hc.context(hc.seq)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := ctx.XORKeyStream(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(ciphertext)+tagSize)
+	binary.BigEndian.PutUint64(record, hc.seq)
+	binary.BigEndian.PutUint32(record[8:], uint32(len(ciphertext)))
+	m, err := hc.mac(record, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	record = append(record, ciphertext...)
+	record = m.Sum(record)
+
+	hc.seq++
+	return record, nil
+}
+
+// open: сначала тег, затем номер, и только потом расшифровка
+func (hc *halfConn) open(r io.Reader) ([]byte, error) {
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	seq := binary.BigEndian.Uint64(header)
+	length := binary.BigEndian.Uint32(header[8:])
+	if length > MaxRecordPayload {
+		return nil, ErrRecordTooLong
+	}
+
+	body := make([]byte, int(length)+tagSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	ciphertext, tag := body[:length], body[length:]
+
+	m, err := hc.mac(header, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Verify(tag) {
+		return nil, ErrBadRecordMAC
+	}
+	switch {
+	case seq < hc.seq:
+		return nil, fmt.Errorf("%w: sequence %d, expected %d", ErrReplayedRecord, seq, hc.seq)
+	case seq > hc.seq:
+		return nil, fmt.Errorf("%w: sequence %d, expected %d", ErrOutOfOrderRecord, seq, hc.seq)
+	}
+
+	ctx, err := hc.context(seq)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := ctx.XORKeyStream(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	hc.seq++
+	return payload, nil
+}
+
+// Write режет данные на записи не длиннее MaxRecordPayload
+func (c *Conn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.writeErr != nil {
+		return 0, c.writeErr
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), MaxRecordPayload)
+		record, err := c.out.seal(p[:n])
+		if err == nil {
+			_, err = c.conn.Write(record)
+		}
+		if err != nil {
+			c.writeErr = err
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (c *Conn) Read(p []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	for len(c.pending) == 0 {
+		if c.readErr != nil {
+			return 0, c.readErr
+		}
+		c.pending, c.readErr = c.in.open(c.conn)
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *Conn) Close() error                       { return c.conn.Close() }
+func (c *Conn) LocalAddr() net.Addr                { return c.conn.LocalAddr() }
+func (c *Conn) RemoteAddr() net.Addr               { return c.conn.RemoteAddr() }
+func (c *Conn) SetDeadline(t time.Time) error      { return c.conn.SetDeadline(t) }
+func (c *Conn) SetReadDeadline(t time.Time) error  { return c.conn.SetReadDeadline(t) }
+func (c *Conn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
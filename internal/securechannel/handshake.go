@@ -0,0 +1,256 @@
+package securechannel
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"crypto-lab/internal/asymmetric/dh"
+	"crypto-lab/internal/asymmetric/rsa"
+	"crypto-lab/internal/hash"
+	"crypto-lab/internal/kdf"
+)
+
+// Рукопожатие (DH с подписями RSA-PSS, как в SIGMA):
+//
+//	C -> S: ClientHello    = version || nonceC || dhC
+//	S -> C: ServerHello    = nonceS || dhS || sign_S("server" || ClientHello || nonceS || dhS)
+//	C -> S: ClientFinished = sign_C("client" || ClientHello || ServerHello)
+//
+// Ключи направлений выводятся из общего секрета DH с солью SHA-256(ClientHello || ServerHello).
+// Каждое сообщение рукопожатия - uint32 длина || тело.
+
+const (
+	protocolVersion = 1
+	nonceSize       = 32
+	maxHandshakeMsg = 1 << 16
+
+	serverSignContext = "securechannel server"
+	clientSignContext = "securechannel client"
+)
+
+var (
+	ErrHandshakeFailed    = errors.New("securechannel: handshake failed")
+	ErrPeerAuthentication = errors.New("securechannel: peer authentication failed")
+)
+
+// Config: обе стороны должны использовать одну группу DH
+type Config struct {
+	Group      *dh.Group       // nil - MODP 2048 (RFC 3526)
+	PrivateKey *rsa.PrivateKey // свой ключ подписи
+	PeerKey    *rsa.PublicKey  // ожидаемый ключ подписи собеседника
+}
+
+func (c *Config) group() (*dh.Group, error) {
+	if c.Group != nil {
+		return c.Group, nil
+	}
+	return dh.MODPGroup(2048)
+}
+
+func (c *Config) validate() error {
+	if c == nil || c.PrivateKey == nil || c.PeerKey == nil {
+		return errors.New("securechannel: config needs own private key and peer public key")
+	}
+	return nil
+}
+
+// ключи одного направления
+type directionKeys struct {
+	encKey []byte // DEAL-256
+	macKey []byte // HMAC-SHA-256
+	nonce  []byte // базовый nonce CTR
+}
+
+const (
+	encKeySize   = 32
+	macKeySize   = 32
+	ctrNonceSize = 8
+	keyBlockSize = encKeySize + macKeySize + ctrNonceSize
+)
+
+func clientHandshake(rw io.ReadWriter, config *Config) (out, in *directionKeys, err error) {
+	group, err := config.group()
+	if err != nil {
+		return nil, nil, err
+	}
+	eph, err := dh.GenerateKey(group)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonceC := make([]byte, nonceSize)
+	if _, err := rand.Read(nonceC); err != nil {
+		return nil, nil, err
+	}
+	clientHello := append([]byte{protocolVersion}, nonceC...)
+	clientHello = append(clientHello, eph.PublicKeyBytes()...)
+	if err := writeMessage(rw, clientHello); err != nil {
+		return nil, nil, err
+	}
+
+	serverHello, err := readMessage(rw)
+	if err != nil {
+		return nil, nil, err
+	}
+	// nonceS || dhS || uint16 длина подписи || подпись
+	if len(serverHello) < nonceSize+group.Size()+2 {
+		return nil, nil, fmt.Errorf("%w: short server hello", ErrHandshakeFailed)
+	}
+	params := serverHello[:nonceSize+group.Size()]
+	peerPub, err := group.ParsePublicKey(params[nonceSize:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrHandshakeFailed, err)
+	}
+	sig, err := parseSignature(serverHello[len(params):])
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verify(config.PeerKey, sig, serverSignContext, clientHello, params); err != nil {
+		return nil, nil, err
+	}
+
+	finished, err := sign(config.PrivateKey, clientSignContext, clientHello, serverHello)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeMessage(rw, finished); err != nil {
+		return nil, nil, err
+	}
+
+	c2s, s2c, err := deriveKeys(eph, peerPub, clientHello, serverHello)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c2s, s2c, nil
+}
+
+func serverHandshake(rw io.ReadWriter, config *Config) (out, in *directionKeys, err error) {
+	group, err := config.group()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientHello, err := readMessage(rw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(clientHello) != 1+nonceSize+group.Size() {
+		return nil, nil, fmt.Errorf("%w: malformed client hello", ErrHandshakeFailed)
+	}
+	if clientHello[0] != protocolVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported protocol version %d", ErrHandshakeFailed, clientHello[0])
+	}
+	peerPub, err := group.ParsePublicKey(clientHello[1+nonceSize:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrHandshakeFailed, err)
+	}
+
+	eph, err := dh.GenerateKey(group)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonceS := make([]byte, nonceSize)
+	if _, err := rand.Read(nonceS); err != nil {
+		return nil, nil, err
+	}
+	params := append(nonceS, eph.PublicKeyBytes()...)
+	sig, err := sign(config.PrivateKey, serverSignContext, clientHello, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	serverHello := binary.BigEndian.AppendUint16(params, uint16(len(sig)))
+	serverHello = append(serverHello, sig...)
+	if err := writeMessage(rw, serverHello); err != nil {
+		return nil, nil, err
+	}
+
+	finished, err := readMessage(rw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verify(config.PeerKey, finished, clientSignContext, clientHello, serverHello); err != nil {
+		return nil, nil, err
+	}
+
+	c2s, s2c, err := deriveKeys(eph, peerPub, clientHello, serverHello)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s2c, c2s, nil
+}
+
+// ключевой материал: [клиент -> сервер][сервер -> клиент], по keyBlockSize байт
+func deriveKeys(eph *dh.PrivateKey, peer *big.Int, clientHello, serverHello []byte) (c2s, s2c *directionKeys, err error) {
+	secret, err := eph.SharedSecret(peer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrHandshakeFailed, err)
+	}
+	salt := transcriptHash(clientHello, serverHello)
+	material, err := kdf.PBKDF2(hash.NewSHA256, secret, salt, 1, 2*keyBlockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	split := func(b []byte) *directionKeys {
+		return &directionKeys{
+			encKey: b[:encKeySize],
+			macKey: b[encKeySize : encKeySize+macKeySize],
+			nonce:  b[encKeySize+macKeySize : keyBlockSize],
+		}
+	}
+	return split(material[:keyBlockSize]), split(material[keyBlockSize:]), nil
+}
+
+func transcriptHash(parts ...[]byte) []byte {
+	h := hash.NewSHA256()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func sign(priv *rsa.PrivateKey, context string, parts ...[]byte) ([]byte, error) {
+	digest := transcriptHash(append([][]byte{[]byte(context)}, parts...)...)
+	return rsa.SignPSS(hash.NewSHA256, rand.Reader, priv, digest, rsa.PSSSaltLengthEqualsHash)
+}
+
+func verify(pub *rsa.PublicKey, sig []byte, context string, parts ...[]byte) error {
+	digest := transcriptHash(append([][]byte{[]byte(context)}, parts...)...)
+	if err := rsa.VerifyPSS(hash.NewSHA256, pub, digest, sig, rsa.PSSSaltLengthEqualsHash); err != nil {
+		return ErrPeerAuthentication
+	}
+	return nil
+}
+
+func parseSignature(data []byte) ([]byte, error) {
+	if len(data) < 2 || int(binary.BigEndian.Uint16(data)) != len(data)-2 {
+		return nil, fmt.Errorf("%w: malformed signature", ErrHandshakeFailed)
+	}
+	return data[2:], nil
+}
+
+func writeMessage(w io.Writer, msg []byte) error {
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(msg)), uint32(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+func readMessage(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n > maxHandshakeMsg {
+		return nil, fmt.Errorf("%w: handshake message too long (%d bytes)", ErrHandshakeFailed, n)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,257 @@
+package securechannel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"crypto-lab/internal/asymmetric/dh"
+	"crypto-lab/internal/asymmetric/rsa"
+)
+
+var (
+	keysOnce             sync.Once
+	clientKey, serverKey *rsa.PrivateKey
+	testGroup            *dh.Group
+)
+
+func testConfigs(t *testing.T) (client, server *Config) {
+	t.Helper()
+	keysOnce.Do(func() {
+		clientKey, _ = rsa.GenerateKey(1024)
+		serverKey, _ = rsa.GenerateKey(1024)
+		testGroup, _ = dh.MODPGroup(1536)
+	})
+	if clientKey == nil || serverKey == nil {
+		t.Fatal("failed to generate RSA keys")
+	}
+	client = &Config{Group: testGroup, PrivateKey: clientKey, PeerKey: &serverKey.PublicKey}
+	server = &Config{Group: testGroup, PrivateKey: serverKey, PeerKey: &clientKey.PublicKey}
+	return client, server
+}
+
+// рукопожатие обеих сторон параллельно (net.Pipe синхронный)
+func handshake(t *testing.T, clientConn, serverConn net.Conn, clientCfg, serverCfg *Config) (*Conn, *Conn, error, error) {
+	t.Helper()
+	var server *Conn
+	var serverErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server, serverErr = Server(serverConn, serverCfg)
+		if serverErr != nil {
+			serverConn.Close()
+		}
+	}()
+	client, clientErr := Client(clientConn, clientCfg)
+	if clientErr != nil {
+		clientConn.Close()
+	}
+	<-done
+	return client, server, clientErr, serverErr
+}
+
+func TestRoundTripOverPipe(t *testing.T) {
+	clientCfg, serverCfg := testConfigs(t)
+	c1, c2 := net.Pipe()
+	client, server, err1, err2 := handshake(t, c1, c2, clientCfg, serverCfg)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("handshake: client %v, server %v", err1, err2)
+	}
+	defer client.Close()
+
+	// эхо-сервер
+	go func() {
+		io.Copy(server, server)
+		server.Close()
+	}()
+
+	msg := make([]byte, MaxRecordPayload+123) // две записи
+	for i := range msg {
+		msg[i] = byte(i * 31)
+	}
+	go client.Write(msg)
+
+	echo := make([]byte, len(msg))
+	if _, err := io.ReadFull(client, echo); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(echo, msg) {
+		t.Errorf("echoed data differs")
+	}
+}
+
+func TestHandshakeRejectsWrongPeerKey(t *testing.T) {
+	clientCfg, serverCfg := testConfigs(t)
+	impostor, err := rsa.GenerateKey(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCfg.PrivateKey = impostor // клиент ждет другой ключ сервера
+
+	c1, c2 := net.Pipe()
+	_, _, clientErr, serverErr := handshake(t, c1, c2, clientCfg, serverCfg)
+	if !errors.Is(clientErr, ErrPeerAuthentication) {
+		t.Errorf("client: expected ErrPeerAuthentication, got %v", clientErr)
+	}
+	if serverErr == nil {
+		t.Errorf("server must not complete handshake")
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	c1, _ := net.Pipe()
+	if _, err := Client(c1, &Config{}); err == nil {
+		t.Errorf("expected error for config without keys")
+	}
+}
+
+// посредник: рукопожатие пропускает как есть, записи клиента отдает в tamper
+type recordFilter func(i int, record []byte) [][]byte
+
+func withProxy(t *testing.T, tamper recordFilter) (client, server *Conn) {
+	t.Helper()
+	clientCfg, serverCfg := testConfigs(t)
+	clientConn, proxyClient := net.Pipe()
+	proxyServer, serverConn := net.Pipe()
+
+	go io.Copy(proxyClient, proxyServer)
+	go func() {
+		// ClientHello и ClientFinished
+		for i := 0; i < 2; i++ {
+			msg, err := readMessage(proxyClient)
+			if err != nil || writeMessage(proxyServer, msg) != nil {
+				return
+			}
+		}
+		for i := 0; ; i++ {
+			header := make([]byte, recordHeaderSize)
+			if _, err := io.ReadFull(proxyClient, header); err != nil {
+				return
+			}
+			n := int(header[8])<<24 | int(header[9])<<16 | int(header[10])<<8 | int(header[11])
+			body := make([]byte, n+tagSize)
+			if _, err := io.ReadFull(proxyClient, body); err != nil {
+				return
+			}
+			for _, r := range tamper(i, append(header, body...)) {
+				if _, err := proxyServer.Write(r); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	client, server, err1, err2 := handshake(t, clientConn, serverConn, clientCfg, serverCfg)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("handshake: client %v, server %v", err1, err2)
+	}
+	t.Cleanup(func() { client.Close(); server.Close() })
+	return client, server
+}
+
+// клиент шлет три записи "r0", "r1", "r2"; сервер читает, пока не получит ошибку
+func exchange(t *testing.T, client, server *Conn) ([]string, error) {
+	t.Helper()
+	go func() {
+		for _, m := range []string{"r0", "r1", "r2"} {
+			if _, err := client.Write([]byte(m)); err != nil {
+				return
+			}
+		}
+	}()
+
+	var got []string
+	buf := make([]byte, 64)
+	for len(got) < 3 {
+		n, err := server.Read(buf)
+		if err != nil {
+			return got, err
+		}
+		got = append(got, string(buf[:n]))
+	}
+	return got, nil
+}
+
+func TestRecordPassThrough(t *testing.T) {
+	var lengths []int
+	client, server := withProxy(t, func(i int, r []byte) [][]byte {
+		lengths = append(lengths, len(r))
+		return [][]byte{r}
+	})
+	got, err := exchange(t, client, server)
+	if err != nil || len(got) != 3 || got[2] != "r2" {
+		t.Errorf("expected r0 r1 r2, got %v (%v)", got, err)
+	}
+	// CTR без паддинга: шифртекст той же длины, что "rN"
+	for i, n := range lengths {
+		if n != recordHeaderSize+2+tagSize {
+			t.Errorf("record %d: %d bytes, expected %d", i, n, recordHeaderSize+2+tagSize)
+		}
+	}
+}
+
+func TestRecordTamperingDetected(t *testing.T) {
+	client, server := withProxy(t, func(i int, r []byte) [][]byte {
+		if i == 1 {
+			r[recordHeaderSize] ^= 0x01 // бит шифртекста
+		}
+		return [][]byte{r}
+	})
+	got, err := exchange(t, client, server)
+	if !errors.Is(err, ErrBadRecordMAC) || len(got) != 1 {
+		t.Errorf("expected ErrBadRecordMAC after r0, got %v, %v", got, err)
+	}
+
+	// соединение остается сломанным
+	if _, err2 := server.Read(make([]byte, 8)); !errors.Is(err2, ErrBadRecordMAC) {
+		t.Errorf("expected sticky ErrBadRecordMAC, got %v", err2)
+	}
+}
+
+func TestReplayRejected(t *testing.T) {
+	client, server := withProxy(t, func(i int, r []byte) [][]byte {
+		if i == 0 {
+			return [][]byte{r, r}
+		}
+		return [][]byte{r}
+	})
+	got, err := exchange(t, client, server)
+	if !errors.Is(err, ErrReplayedRecord) || len(got) != 1 || got[0] != "r0" {
+		t.Errorf("expected ErrReplayedRecord after r0, got %v, %v", got, err)
+	}
+}
+
+func TestReorderRejected(t *testing.T) {
+	var held []byte
+	client, server := withProxy(t, func(i int, r []byte) [][]byte {
+		switch i {
+		case 0:
+			held = r
+			return nil
+		case 1:
+			return [][]byte{r, held}
+		}
+		return [][]byte{r}
+	})
+	got, err := exchange(t, client, server)
+	if !errors.Is(err, ErrOutOfOrderRecord) || len(got) != 0 {
+		t.Errorf("expected ErrOutOfOrderRecord, got %v, %v", got, err)
+	}
+}
+
+func TestDroppedRecordDetected(t *testing.T) {
+	client, server := withProxy(t, func(i int, r []byte) [][]byte {
+		if i == 1 {
+			return nil
+		}
+		return [][]byte{r}
+	})
+	got, err := exchange(t, client, server)
+	if !errors.Is(err, ErrOutOfOrderRecord) || len(got) != 1 {
+		t.Errorf("expected ErrOutOfOrderRecord after r0, got %v, %v", got, err)
+	}
+}